@@ -0,0 +1,236 @@
+package aptos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/go-aptos/graphql"
+	txbuilder "github.com/coming-chat/go-aptos/transaction_builder"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+const (
+	TokenStandardCoin          = "coin"
+	TokenStandardFungibleAsset = "fungible_asset"
+
+	coinStorePrefix = "0x1::coin::CoinStore<"
+
+	queryFungibleAssetBalancesFormat = `
+	query FungibleAssetBalances {
+		current_fungible_asset_balances(
+		  where: {owner_address: {_eq: "%s"}, is_primary: {_eq: true}, token_standard: {_eq: "v2"}, amount: {_gt: "0"}}
+		) {
+		  asset_type
+		  storage_id
+		  amount
+		  is_frozen
+		  metadata {
+			name
+			symbol
+			decimals
+		  }
+		}
+	  }`
+)
+
+// The coin or fungible asset held by the account.
+type AccountToken struct {
+	// `TokenStandardCoin` or `TokenStandardFungibleAsset`
+	Standard string `json:"standard"`
+	// The coin tag (e.g. `0x1::aptos_coin::AptosCoin`) or the address of the fungible asset metadata object.
+	TokenType string `json:"tokenType"`
+	// The address of the primary fungible store, it's empty if the token is coin.
+	StoreAddress string `json:"storeAddress"`
+
+	// It's nil if the info of coin is unavailable.
+	TokenInfo *base.TokenInfo `json:"tokenInfo"`
+	// The usable balance is 0 if the store is frozen.
+	Balance *base.Balance `json:"balance"`
+}
+
+func (t *AccountToken) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(t)
+}
+
+func NewAccountTokenWithJsonString(str string) (*AccountToken, error) {
+	var o AccountToken
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (o *AccountToken) AsAny() *base.Any {
+	return &base.Any{Value: o}
+}
+func AsAccountToken(a *base.Any) *AccountToken {
+	if r, ok := a.Value.(*AccountToken); ok {
+		return r
+	}
+	if r, ok := a.Value.(AccountToken); ok {
+		return &r
+	}
+	return nil
+}
+
+// Fetch all coins (`0x1::coin::CoinStore<T>` resources) and fungible assets (primary fungible stores) held by the owner.
+// The fungible assets are discovered by the indexer `GraphUrl` of the chain, they are skipped if the `GraphUrl` is empty.
+// The main token is always the first one if the owner has registered it.
+// @return the array of `AccountToken`
+func (c *Chain) FetchAccountTokens(owner string) (arr *base.AnyArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	coins, err := c.fetchAccountCoins(owner)
+	if err != nil {
+		return
+	}
+	assets := []*AccountToken{}
+	if c.GraphUrl != "" {
+		assets, err = fetchAccountFungibleAssets(owner, c.GraphUrl)
+		if err != nil {
+			return
+		}
+	}
+
+	arr = base.NewAnyArray()
+	for _, coin := range coins {
+		arr.Values = append(arr.Values, coin)
+	}
+	for _, asset := range assets {
+		arr.Values = append(arr.Values, asset)
+	}
+	return arr, nil
+}
+
+func (c *Chain) fetchAccountCoins(owner string) ([]*AccountToken, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	resources, err := client.GetAccountResources(owner, 0)
+	if err != nil {
+		return nil, err
+	}
+	list := []interface{}{}
+	for _, resource := range resources {
+		token, err := accountTokenOfCoinStore(resource.Type, resource.Data)
+		if err != nil || token == nil {
+			continue
+		}
+		list = append(list, token)
+	}
+	// the token info of coin is stored in the creator's account, it should be fetched one by one.
+	_, err = base.MapListConcurrent(list, 10, func(i interface{}) (interface{}, error) {
+		token := i.(*AccountToken)
+		// the coin is still returned without info if its info is unavailable, e.g. the type of coin is invalid.
+		if coin, err := NewToken(c, token.TokenType); err == nil {
+			if info, err := coin.TokenInfo(); err == nil {
+				token.TokenInfo = info
+			}
+		}
+		return token, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	coins := make([]*AccountToken, len(list))
+	for i, item := range list {
+		coins[i] = item.(*AccountToken)
+	}
+	sort.SliceStable(coins, func(i, j int) bool {
+		return coins[i].TokenType == mainTokenTag && coins[j].TokenType != mainTokenTag
+	})
+	return coins, nil
+}
+
+// @return nil if the resource is not a coin store.
+func accountTokenOfCoinStore(resourceType string, data map[string]interface{}) (*AccountToken, error) {
+	if !strings.HasPrefix(resourceType, coinStorePrefix) || !strings.HasSuffix(resourceType, ">") {
+		return nil, nil
+	}
+	tag := strings.TrimSuffix(strings.TrimPrefix(resourceType, coinStorePrefix), ">")
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	store := struct {
+		Coin struct {
+			Value string `json:"value"`
+		} `json:"coin"`
+		Frozen bool `json:"frozen"`
+	}{}
+	err = json.Unmarshal(jsonData, &store)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountToken{
+		Standard:  TokenStandardCoin,
+		TokenType: tag,
+		Balance:   balanceOfStore(store.Coin.Value, store.Frozen),
+	}, nil
+}
+
+func fetchAccountFungibleAssets(owner, graphUrl string) ([]*AccountToken, error) {
+	res := struct {
+		Balances []struct {
+			AssetType string      `json:"asset_type"`
+			StorageId string      `json:"storage_id"`
+			Amount    json.Number `json:"amount"`
+			IsFrozen  bool        `json:"is_frozen"`
+			Metadata  *struct {
+				Name     string `json:"name"`
+				Symbol   string `json:"symbol"`
+				Decimals int16  `json:"decimals"`
+			} `json:"metadata"`
+		} `json:"current_fungible_asset_balances"`
+	}{}
+	address, err := indexerAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(queryFungibleAssetBalancesFormat, address)
+	err = graphql.FetchGraphQLSample(query, graphUrl, &res)
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]*AccountToken, 0, len(res.Balances))
+	for _, b := range res.Balances {
+		info := &base.TokenInfo{}
+		if b.Metadata != nil {
+			info.Name = b.Metadata.Name
+			info.Symbol = b.Metadata.Symbol
+			info.Decimal = b.Metadata.Decimals
+		}
+		assets = append(assets, &AccountToken{
+			Standard:     TokenStandardFungibleAsset,
+			TokenType:    b.AssetType,
+			StoreAddress: b.StorageId,
+			TokenInfo:    info,
+			Balance:      balanceOfStore(b.Amount.String(), b.IsFrozen),
+		})
+	}
+	return assets, nil
+}
+
+func balanceOfStore(amount string, frozen bool) *base.Balance {
+	if amount == "" {
+		amount = "0"
+	}
+	usable := amount
+	if frozen {
+		usable = "0"
+	}
+	return &base.Balance{Total: amount, Usable: usable}
+}
+
+// The indexer stores the addresses in the long format (64 hex characters), the address is also validated to be safely embedded in the query.
+func indexerAddress(address string) (string, error) {
+	accountAddress, err := txbuilder.NewAccountAddressFromHex(address)
+	if err != nil {
+		return "", errors.New("Invalid address: " + address)
+	}
+	return types.HexEncodeToString(accountAddress[:]), nil
+}
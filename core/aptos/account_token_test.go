@@ -0,0 +1,43 @@
+package aptos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountTokenOfCoinStore(t *testing.T) {
+	data := map[string]interface{}{
+		"coin":   map[string]interface{}{"value": "12345"},
+		"frozen": true,
+	}
+	token, err := accountTokenOfCoinStore("0x1::coin::CoinStore<"+usdtTag+">", data)
+	require.Nil(t, err)
+	require.Equal(t, TokenStandardCoin, token.Standard)
+	require.Equal(t, usdtTag, token.TokenType)
+	require.Equal(t, "12345", token.Balance.Total)
+	require.Equal(t, "0", token.Balance.Usable)
+
+	token, err = accountTokenOfCoinStore("0x1::account::Account", data)
+	require.Nil(t, err)
+	require.Nil(t, token)
+}
+
+func TestIndexerAddress(t *testing.T) {
+	address, err := indexerAddress("0x1")
+	require.Nil(t, err)
+	require.Equal(t, "0x0000000000000000000000000000000000000000000000000000000000000001", address)
+
+	_, err = indexerAddress(`0x1"}) { injected }`)
+	require.NotNil(t, err)
+}
+
+func TestFetchAccountTokens(t *testing.T) {
+	chain := NewChainWithRestUrl(mainnetRestUrl)
+	chain.GraphUrl = GraphUrlMainnet
+	tokens, err := chain.FetchAccountTokens("0x1")
+	require.Nil(t, err)
+	json, err := tokens.JsonString()
+	require.Nil(t, err)
+	t.Log(json.Value)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -34,6 +35,9 @@ type IChain interface {
 type Chain struct {
 	restClient *aptosclient.RestClient
 	RestUrl    string
+
+	// The indexer url, it's used to discover the fungible assets of account, e.g. `GraphUrlMainnet`.
+	GraphUrl string
 }
 
 func NewChainWithRestUrl(restUrl string) *Chain {
@@ -194,6 +198,11 @@ func (c *Chain) EstimateMaxGasAmountBCS(publicKey []byte, rawTxn *txbuilder.RawT
 	if err != nil {
 		return 0, err
 	}
+	return c.estimateMaxGasAmountOfSimulation(signedTx)
+}
+
+// @param signedTx the signed transaction with invalid signatures.
+func (c *Chain) estimateMaxGasAmountOfSimulation(signedTx []byte) (uint64, error) {
 	client, err := c.client()
 	if err != nil {
 		return 0, err
@@ -283,6 +292,26 @@ func (c *Chain) SignAndSendTransaction(account base.Account, hexData string) (*b
 
 // @return The raw transaction that `MaxGasAmount` has obtained from the chain in real time.
 func (c *Chain) createTransactionFromPayloadBCS(account base.Account, payload txbuilder.TransactionPayload) (*txbuilder.RawTransaction, error) {
+	txAbi, err := c.newRawTransaction(getAuthKey(account), payload)
+	if err != nil {
+		return nil, err
+	}
+	// estimate gas and resign
+	maxGas, err := c.EstimateMaxGasAmountBCS(account.PublicKey(), txAbi)
+	if err != nil {
+		return nil, err
+	}
+	txAbi.MaxGasAmount = maxGas
+	return txAbi, nil
+}
+
+// @return The raw transaction with the default `MaxGasAmount`, the caller should estimate it.
+func (c *Chain) newRawTransaction(sender txbuilder.AccountAddress, payload txbuilder.TransactionPayload) (*txbuilder.RawTransaction, error) {
+	return c.newRawTransactionOfSender(sender, payload, false)
+}
+
+// @param allowNewAccount the sender that doesn't exist on chain uses the sequence number 0, e.g. the new user sponsored by the fee payer.
+func (c *Chain) newRawTransactionOfSender(sender txbuilder.AccountAddress, payload txbuilder.TransactionPayload, allowNewAccount bool) (*txbuilder.RawTransaction, error) {
 	var (
 		err         error
 		client      *aptosclient.RestClient
@@ -294,8 +323,11 @@ func (c *Chain) createTransactionFromPayloadBCS(account base.Account, payload tx
 	if client, err = c.client(); err != nil {
 		return nil, err
 	}
-	if accountData, err = client.GetAccount(account.Address()); err != nil {
-		return nil, err
+	if accountData, err = client.GetAccount(types.HexEncodeToString(sender[:])); err != nil {
+		if !allowNewAccount || !isNotFoundError(err) {
+			return nil, err
+		}
+		accountData = &aptostypes.AccountCoreData{SequenceNumber: 0}
 	}
 	if ledgerInfo, err = client.LedgerInfo(); err != nil {
 		return nil, err
@@ -303,22 +335,21 @@ func (c *Chain) createTransactionFromPayloadBCS(account base.Account, payload tx
 	if gasPrice, err = client.EstimateGasPrice(); err != nil {
 		return nil, err
 	}
-	txAbi := &txbuilder.RawTransaction{
-		Sender:                  getAuthKey(account),
+	return &txbuilder.RawTransaction{
+		Sender:                  sender,
 		SequenceNumber:          accountData.SequenceNumber,
 		MaxGasAmount:            MaxGasAmount,
 		GasUnitPrice:            gasPrice,
 		Payload:                 payload,
 		ExpirationTimestampSecs: ledgerInfo.LedgerTimestamp + TxExpireSec,
 		ChainId:                 uint8(ledgerInfo.ChainId),
-	}
-	// estimate gas and resign
-	maxGas, err := c.EstimateMaxGasAmountBCS(account.PublicKey(), txAbi)
-	if err != nil {
-		return nil, err
-	}
-	txAbi.MaxGasAmount = maxGas
-	return txAbi, nil
+	}, nil
+}
+
+// Whether the error is the 404 response of rest api, e.g. the account or resource does not exist.
+func isNotFoundError(err error) bool {
+	var restErr *aptostypes.RestError
+	return errors.As(err, &restErr) && restErr.Code == http.StatusNotFound
 }
 
 func (c *Chain) EstimateGasPrice() (*base.OptionalString, error) {
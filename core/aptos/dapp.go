@@ -56,6 +56,23 @@ func (j *SignMessageResponse) JsonString() string {
 func (c *Chain) GenerateTransaction(senderPublicKey string, payload aptostypes.Payload) (txn *aptostypes.Transaction, err error) {
 	defer base.CatchPanicAndMapToBasicError(&err)
 
+	txn, err = c.simulateEntryFunctionPayload(senderPublicKey, payload)
+	if err != nil {
+		return
+	}
+	gasAmount, err := handleGasAmount([]*aptostypes.Transaction{txn})
+	if err != nil {
+		return nil, err
+	}
+	txn.MaxGasAmount = gasAmount
+
+	txn.Hash = ""
+	txn.Signature = nil // clean simulate signature.
+	return txn, nil
+}
+
+// Simulate the payload with the default max gas amount, the failed simulation is returned without error.
+func (c *Chain) simulateEntryFunctionPayload(senderPublicKey string, payload aptostypes.Payload) (txn *aptostypes.Transaction, err error) {
 	payload.Type = aptostypes.EntryFunctionPayload
 
 	sender, err := EncodePublicKeyToAddress(senderPublicKey)
@@ -106,16 +123,10 @@ func (c *Chain) GenerateTransaction(senderPublicKey string, payload aptostypes.P
 	if err != nil {
 		return
 	}
-	gasAmount, err := handleGasAmount(txns)
-	if err != nil {
-		return nil, err
+	if len(txns) <= 0 {
+		return nil, errors.New("Simulate transaction failed.")
 	}
-	txn = txns[0]
-	txn.MaxGasAmount = gasAmount
-
-	txn.Hash = ""
-	txn.Signature = nil // clean simulate signature.
-	return txn, nil
+	return txns[0], nil
 }
 
 func (c *Chain) SignTransaction(account base.Account, transaction aptostypes.Transaction) (txn *aptostypes.Transaction, err error) {
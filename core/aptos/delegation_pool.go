@@ -0,0 +1,444 @@
+package aptos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/coming-chat/go-aptos/graphql"
+	txbuilder "github.com/coming-chat/go-aptos/transaction_builder"
+	"github.com/coming-chat/lcs"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/pkg/httpUtil"
+)
+
+const (
+	delegationPoolModule   = "0x1::delegation_pool"
+	delegationPoolResource = "0x1::delegation_pool::DelegationPool"
+	stakePoolResource      = "0x1::stake::StakePool"
+
+	// The denominator of the commission percentage, 10000 means 100%.
+	CommissionDenominator = 10000
+
+	queryDelegationPools = `
+	query DelegationPools {
+		delegated_staking_pools {
+		  staking_pool_address
+		  current_staking_pool {
+			operator_address
+			voter_address
+		  }
+		}
+		current_delegated_staking_pool_balances {
+		  staking_pool_address
+		  total_coins
+		  operator_commission_percentage
+		}
+	  }`
+	queryDelegatorPoolsFormat = `
+	query DelegatorPools {
+		delegator_distinct_pool(where: {delegator_address: {_eq: "%s"}}) {
+		  pool_address
+		}
+	  }`
+)
+
+type DelegationPool struct {
+	PoolAddress     string `json:"poolAddress"`
+	OperatorAddress string `json:"operatorAddress"`
+	VoterAddress    string `json:"voterAddress"`
+	// The commission of operator, `CommissionDenominator` means 100%
+	Commission int64  `json:"commission"`
+	TotalStake string `json:"totalStake"`
+	// The estimated annual percentage rate of delegators after the commission, e.g. 0.07 means 7%
+	APR float64 `json:"apr"`
+	// The unlocked stake can be withdrawn after the time (unix seconds), it's 0 if the pool is from the pool list.
+	LockedUntilSecs int64 `json:"lockedUntilSecs"`
+}
+
+func (p *DelegationPool) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(p)
+}
+
+func NewDelegationPoolWithJsonString(str string) (*DelegationPool, error) {
+	var o DelegationPool
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (o *DelegationPool) AsAny() *base.Any {
+	return &base.Any{Value: o}
+}
+func AsDelegationPool(a *base.Any) *DelegationPool {
+	if r, ok := a.Value.(*DelegationPool); ok {
+		return r
+	}
+	if r, ok := a.Value.(DelegationPool); ok {
+		return &r
+	}
+	return nil
+}
+
+// The stake of the delegator in the pool.
+type DelegatedStake struct {
+	PoolAddress string `json:"poolAddress"`
+	// The stake that is earning rewards.
+	Active string `json:"active"`
+	// The unlocked stake that can be withdrawn.
+	Inactive string `json:"inactive"`
+	// The unlocked stake that is waiting for the lockup to end, it can be reactivated.
+	PendingInactive string `json:"pendingInactive"`
+}
+
+func (s *DelegatedStake) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(s)
+}
+
+func NewDelegatedStakeWithJsonString(str string) (*DelegatedStake, error) {
+	var o DelegatedStake
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func NewDelegatedStakeArrayWithJsonString(str string) (*base.AnyArray, error) {
+	var o []*DelegatedStake
+	err := base.FromJsonString(str, &o)
+	arr := make([]any, len(o))
+	for i, v := range o {
+		arr[i] = v
+	}
+	return &base.AnyArray{Values: arr}, err
+}
+
+func (o *DelegatedStake) AsAny() *base.Any {
+	return &base.Any{Value: o}
+}
+func AsDelegatedStake(a *base.Any) *DelegatedStake {
+	if r, ok := a.Value.(*DelegatedStake); ok {
+		return r
+	}
+	if r, ok := a.Value.(DelegatedStake); ok {
+		return &r
+	}
+	return nil
+}
+
+// MARK - Queries
+
+// Fetch all delegation pools from the indexer `GraphUrl`.
+// @return the array of `DelegationPool`
+func (c *Chain) FetchDelegationPools() (arr *base.AnyArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if c.GraphUrl == "" {
+		return nil, errors.New("The graph url of the chain is required")
+	}
+	res := struct {
+		Pools []struct {
+			StakingPoolAddress string `json:"staking_pool_address"`
+			CurrentStakingPool *struct {
+				OperatorAddress string `json:"operator_address"`
+				VoterAddress    string `json:"voter_address"`
+			} `json:"current_staking_pool"`
+		} `json:"delegated_staking_pools"`
+		Balances []struct {
+			StakingPoolAddress           string      `json:"staking_pool_address"`
+			TotalCoins                   json.Number `json:"total_coins"`
+			OperatorCommissionPercentage json.Number `json:"operator_commission_percentage"`
+		} `json:"current_delegated_staking_pool_balances"`
+	}{}
+	err = graphql.FetchGraphQLSample(queryDelegationPools, c.GraphUrl, &res)
+	if err != nil {
+		return
+	}
+	rewardsRate, err := c.fetchRewardsRatePerYear()
+	if err != nil {
+		return
+	}
+
+	pools := make(map[string]*DelegationPool)
+	arr = base.NewAnyArray()
+	for _, p := range res.Pools {
+		pool := &DelegationPool{PoolAddress: p.StakingPoolAddress, TotalStake: "0"}
+		if p.CurrentStakingPool != nil {
+			pool.OperatorAddress = p.CurrentStakingPool.OperatorAddress
+			pool.VoterAddress = p.CurrentStakingPool.VoterAddress
+		}
+		pools[pool.PoolAddress] = pool
+		arr.Values = append(arr.Values, pool)
+	}
+	for _, b := range res.Balances {
+		pool, ok := pools[b.StakingPoolAddress]
+		if !ok {
+			continue
+		}
+		// the indexer returns the commission in percentage, e.g. 10 means 10%
+		commission, _ := strconv.ParseFloat(b.OperatorCommissionPercentage.String(), 64)
+		pool.Commission = int64(commission * CommissionDenominator / 100)
+		pool.TotalStake = b.TotalCoins.String()
+		pool.APR = delegatorAPR(rewardsRate, pool.Commission)
+	}
+	return arr, nil
+}
+
+// Fetch the delegation pool from the chain directly.
+func (c *Chain) FetchDelegationPool(poolAddress string) (pool *DelegationPool, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := c.client()
+	if err != nil {
+		return
+	}
+	delegationRes, err := client.GetAccountResource(poolAddress, delegationPoolResource, 0)
+	if err != nil {
+		return
+	}
+	delegation := struct {
+		OperatorCommissionPercentage string `json:"operator_commission_percentage"`
+	}{}
+	if err = remarshal(delegationRes.Data, &delegation); err != nil {
+		return
+	}
+	stakeRes, err := client.GetAccountResource(poolAddress, stakePoolResource, 0)
+	if err != nil {
+		return
+	}
+	coin := struct {
+		Value string `json:"value"`
+	}{}
+	stake := struct {
+		OperatorAddress string `json:"operator_address"`
+		DelegatedVoter  string `json:"delegated_voter"`
+		LockedUntilSecs string `json:"locked_until_secs"`
+		Active          any    `json:"active"`
+		PendingActive   any    `json:"pending_active"`
+		PendingInactive any    `json:"pending_inactive"`
+	}{}
+	if err = remarshal(stakeRes.Data, &stake); err != nil {
+		return
+	}
+	totalStake := big.NewInt(0)
+	for _, stakeCoin := range []any{stake.Active, stake.PendingActive, stake.PendingInactive} {
+		if err = remarshal(stakeCoin, &coin); err != nil {
+			return
+		}
+		value, ok := big.NewInt(0).SetString(coin.Value, 10)
+		if ok {
+			totalStake.Add(totalStake, value)
+		}
+	}
+	rewardsRate, err := c.fetchRewardsRatePerYear()
+	if err != nil {
+		return
+	}
+	commission, _ := strconv.ParseInt(delegation.OperatorCommissionPercentage, 10, 64)
+	lockedUntil, _ := strconv.ParseInt(stake.LockedUntilSecs, 10, 64)
+	return &DelegationPool{
+		PoolAddress:     poolAddress,
+		OperatorAddress: stake.OperatorAddress,
+		VoterAddress:    stake.DelegatedVoter,
+		Commission:      commission,
+		TotalStake:      totalStake.String(),
+		APR:             delegatorAPR(rewardsRate, commission),
+		LockedUntilSecs: lockedUntil,
+	}, nil
+}
+
+// Fetch the stake of the delegator in the pool by the view function `0x1::delegation_pool::get_stake`
+func (c *Chain) FetchDelegatedStake(poolAddress, delegator string) (s *DelegatedStake, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	var res []string
+	err = c.view(&res, delegationPoolModule+"::get_stake", []string{}, []any{poolAddress, delegator})
+	if err != nil {
+		return
+	}
+	if len(res) != 3 {
+		return nil, errors.New("Invalid response of get_stake")
+	}
+	return &DelegatedStake{
+		PoolAddress:     poolAddress,
+		Active:          res[0],
+		Inactive:        res[1],
+		PendingInactive: res[2],
+	}, nil
+}
+
+// Fetch the stakes of the delegator in all pools, the pools are discovered by the indexer `GraphUrl`.
+// @return the array of `DelegatedStake`
+func (c *Chain) FetchDelegatedStakes(delegator string) (arr *base.AnyArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if c.GraphUrl == "" {
+		return nil, errors.New("The graph url of the chain is required")
+	}
+	res := struct {
+		Pools []struct {
+			PoolAddress string `json:"pool_address"`
+		} `json:"delegator_distinct_pool"`
+	}{}
+	address, err := indexerAddress(delegator)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf(queryDelegatorPoolsFormat, address)
+	err = graphql.FetchGraphQLSample(query, c.GraphUrl, &res)
+	if err != nil {
+		return
+	}
+	list := make([]interface{}, len(res.Pools))
+	for i, p := range res.Pools {
+		list[i] = p.PoolAddress
+	}
+	stakes, err := base.MapListConcurrent(list, 10, func(i interface{}) (interface{}, error) {
+		return c.FetchDelegatedStake(i.(string), delegator)
+	})
+	if err != nil {
+		return
+	}
+	return &base.AnyArray{Values: stakes}, nil
+}
+
+// The rewards rate per year of the validators, it's `rewards rate per epoch * epochs per year`
+func (c *Chain) fetchRewardsRatePerYear() (float64, error) {
+	client, err := c.client()
+	if err != nil {
+		return 0, err
+	}
+	configRes, err := client.GetAccountResource("0x1", "0x1::staking_config::StakingConfig", 0)
+	if err != nil {
+		return 0, err
+	}
+	config := struct {
+		RewardsRate            string `json:"rewards_rate"`
+		RewardsRateDenominator string `json:"rewards_rate_denominator"`
+	}{}
+	if err = remarshal(configRes.Data, &config); err != nil {
+		return 0, err
+	}
+	rate, _ := strconv.ParseFloat(config.RewardsRate, 64)
+	denominator, _ := strconv.ParseFloat(config.RewardsRateDenominator, 64)
+	if denominator == 0 {
+		return 0, errors.New("Invalid staking config")
+	}
+	ratePerEpoch := rate / denominator
+	// the rewards rate is a fixed point number in the new config since the rewards rate decreases periodically.
+	rewardsRes, err := client.GetAccountResource("0x1", "0x1::staking_config::StakingRewardsConfig", 0)
+	if err == nil {
+		rewards := struct {
+			RewardsRate struct {
+				Value string `json:"value"`
+			} `json:"rewards_rate"`
+		}{}
+		if remarshal(rewardsRes.Data, &rewards) == nil {
+			if value, ok := big.NewFloat(0).SetString(rewards.RewardsRate.Value); ok {
+				value.Quo(value, new(big.Float).SetMantExp(big.NewFloat(1), 64))
+				ratePerEpoch, _ = value.Float64()
+			}
+		}
+	}
+
+	blockRes, err := client.GetAccountResource("0x1", "0x1::block::BlockResource", 0)
+	if err != nil {
+		return 0, err
+	}
+	block := struct {
+		EpochInterval string `json:"epoch_interval"`
+	}{}
+	if err = remarshal(blockRes.Data, &block); err != nil {
+		return 0, err
+	}
+	// microseconds
+	interval, _ := strconv.ParseFloat(block.EpochInterval, 64)
+	if interval == 0 {
+		return 0, errors.New("Invalid epoch interval")
+	}
+	epochsPerYear := 365 * 24 * 3600 * 1e6 / interval
+	return ratePerEpoch * epochsPerYear, nil
+}
+
+func delegatorAPR(rewardsRatePerYear float64, commission int64) float64 {
+	return rewardsRatePerYear * float64(CommissionDenominator-commission) / CommissionDenominator
+}
+
+// Call the view function of the move module.
+// @param out the result array of the function will be called json.Unmarshal
+func (c *Chain) view(out interface{}, function string, typeArgs []string, args []any) error {
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]any{
+		"function":       function,
+		"type_arguments": typeArgs,
+		"arguments":      args,
+	})
+	if err != nil {
+		return err
+	}
+	res, err := httpUtil.Post(client.GetVersionedRpcUrl()+"/view", httpUtil.RequestParams{
+		Header: map[string]string{"Content-Type": "application/json"},
+		Body:   body,
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(res, out)
+}
+
+func remarshal(data any, out any) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonData, out)
+}
+
+// MARK - Payload builders, the payloads can be submitted by `SubmitTransactionPayloadBCS`
+
+// Stake the amount to the pool, the add stake fee is charged and refunded at the end of the epoch.
+func DelegationAddStakePayload(poolAddress, amount string) ([]byte, error) {
+	return delegationPoolPayload("add_stake", poolAddress, amount)
+}
+
+// Unlock the active stake, it becomes pending inactive until the lockup of the pool ends.
+func DelegationUnlockPayload(poolAddress, amount string) ([]byte, error) {
+	return delegationPoolPayload("unlock", poolAddress, amount)
+}
+
+// Move the pending inactive stake back to active.
+func DelegationReactivateStakePayload(poolAddress, amount string) ([]byte, error) {
+	return delegationPoolPayload("reactivate_stake", poolAddress, amount)
+}
+
+// Withdraw the inactive stake to the delegator.
+func DelegationWithdrawPayload(poolAddress, amount string) ([]byte, error) {
+	return delegationPoolPayload("withdraw", poolAddress, amount)
+}
+
+func delegationPoolPayload(function, poolAddress, amount string) ([]byte, error) {
+	pool, err := txbuilder.NewAccountAddressFromHex(poolAddress)
+	if err != nil {
+		return nil, errors.New("Invalid pool address")
+	}
+	amountInt, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return nil, errors.New("Invalid amount")
+	}
+	module, err := txbuilder.NewModuleIdFromString(delegationPoolModule)
+	if err != nil {
+		return nil, err
+	}
+	payload := txbuilder.TransactionPayloadEntryFunction{
+		ModuleName:   *module,
+		FunctionName: txbuilder.Identifier(function),
+		TyArgs:       []txbuilder.TypeTag{},
+		Args: [][]byte{
+			pool[:],
+			txbuilder.BCSSerializeBasicValue(amountInt),
+		},
+	}
+	return lcs.Marshal(txbuilder.TransactionPayload(payload))
+}
@@ -0,0 +1,61 @@
+package aptos
+
+import (
+	"testing"
+
+	txbuilder "github.com/coming-chat/go-aptos/transaction_builder"
+	"github.com/coming-chat/lcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelegationPoolPayload(t *testing.T) {
+	pool := "0x1"
+	_, err := DelegationAddStakePayload(pool, "-1")
+	require.NotNil(t, err)
+
+	builders := map[string]func(string, string) ([]byte, error){
+		"add_stake":        DelegationAddStakePayload,
+		"unlock":           DelegationUnlockPayload,
+		"reactivate_stake": DelegationReactivateStakePayload,
+		"withdraw":         DelegationWithdrawPayload,
+	}
+	for function, builder := range builders {
+		data, err := builder(pool, "1100000000")
+		require.Nil(t, err)
+		payload := txbuilder.TransactionPayloadEntryFunction{}
+		require.Nil(t, lcs.Unmarshal(data, &payload))
+		require.Equal(t, "delegation_pool", string(payload.ModuleName.Name))
+		require.Equal(t, function, string(payload.FunctionName))
+		require.Equal(t, txbuilder.BCSSerializeBasicValue(uint64(1100000000)), payload.Args[1])
+	}
+}
+
+func TestDelegatorAPR(t *testing.T) {
+	require.InDelta(t, 0.07, delegatorAPR(0.07, 0), 1e-9)
+	require.InDelta(t, 0.063, delegatorAPR(0.07, 1000), 1e-9)
+}
+
+func TestFetchDelegatedStakesInvalidAddress(t *testing.T) {
+	chain := NewChainWithRestUrl(mainnetRestUrl)
+	chain.GraphUrl = GraphUrlMainnet
+	// the address is validated before querying the indexer.
+	_, err := chain.FetchDelegatedStakes(`0x1"}}) { injected }`)
+	require.NotNil(t, err)
+}
+
+func TestFetchDelegationPools(t *testing.T) {
+	chain := NewChainWithRestUrl(mainnetRestUrl)
+	chain.GraphUrl = GraphUrlMainnet
+	pools, err := chain.FetchDelegationPools()
+	require.Nil(t, err)
+	require.True(t, pools.Count() > 0)
+
+	pool := AsDelegationPool(pools.ValueOf(0))
+	detail, err := chain.FetchDelegationPool(pool.PoolAddress)
+	require.Nil(t, err)
+	t.Log(detail.JsonString())
+
+	stake, err := chain.FetchDelegatedStake(pool.PoolAddress, pool.OperatorAddress)
+	require.Nil(t, err)
+	t.Log(stake.JsonString())
+}
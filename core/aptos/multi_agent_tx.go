@@ -0,0 +1,386 @@
+package aptos
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	txbuilder "github.com/coming-chat/go-aptos/transaction_builder"
+	"github.com/coming-chat/lcs"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"golang.org/x/crypto/sha3"
+)
+
+// The variants of `RawTransactionWithData` and `TransactionAuthenticator`,
+// the fee payer variants are not registered in the transaction builder, so they are serialized manually.
+const (
+	rawTransactionWithDataMultiAgent = 0
+	rawTransactionWithDataFeePayer   = 1
+
+	transactionAuthenticatorMultiAgent = 2
+	transactionAuthenticatorFeePayer   = 3
+)
+
+// The transaction signed by multiple accounts, it's a fee payer (sponsored) transaction if the `FeePayer` is not empty.
+// The sender, the secondary signers and the fee payer sign it separately (maybe on different devices),
+// it can be passed between them by the json string.
+type MultiAgentTransaction struct {
+	// The hex string of the BCS raw transaction.
+	RawTransaction   string   `json:"rawTransaction"`
+	SecondarySigners []string `json:"secondarySigners"`
+	FeePayer         string   `json:"feePayer"`
+
+	// The hex strings of the BCS account authenticators, they are empty until signed.
+	SenderAuthenticator     string   `json:"senderAuthenticator"`
+	SecondaryAuthenticators []string `json:"secondaryAuthenticators"`
+	FeePayerAuthenticator   string   `json:"feePayerAuthenticator"`
+}
+
+func NewMultiAgentTransactionWithJsonString(str string) (*MultiAgentTransaction, error) {
+	var o MultiAgentTransaction
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (t *MultiAgentTransaction) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(t)
+}
+
+// Build the multi-agent transaction, all secondary signers must sign it.
+// @param secondaryPublicKeys the public keys of secondary signers, separated by ",".
+// @param payload the BCS data of `TransactionPayloadEntryFunction`
+func (c *Chain) BuildMultiAgentTransaction(sender base.Account, secondaryPublicKeys string, payload []byte) (*MultiAgentTransaction, error) {
+	return c.buildMultiAgentTransaction(sender, secondaryPublicKeys, "", payload)
+}
+
+// Build the fee payer transaction, the gas is paid by the fee payer instead of the sender.
+// The sender can be the new account that has not been created on chain.
+// @param secondaryPublicKeys the public keys of secondary signers, separated by ",". It can be empty.
+// @param feePayerPublicKey the public key of the fee payer.
+// @param payload the BCS data of `TransactionPayloadEntryFunction`
+func (c *Chain) BuildFeePayerTransaction(sender base.Account, secondaryPublicKeys, feePayerPublicKey string, payload []byte) (*MultiAgentTransaction, error) {
+	if feePayerPublicKey == "" {
+		return nil, errors.New("The fee payer is required")
+	}
+	return c.buildMultiAgentTransaction(sender, secondaryPublicKeys, feePayerPublicKey, payload)
+}
+
+func (c *Chain) buildMultiAgentTransaction(sender base.Account, secondaryPublicKeys, feePayerPublicKey string, payloadData []byte) (*MultiAgentTransaction, error) {
+	payload := txbuilder.TransactionPayloadEntryFunction{}
+	if err := lcs.Unmarshal(payloadData, &payload); err != nil {
+		return nil, err
+	}
+	// the fee payer can sponsor the new user whose account has not been created.
+	rawTxn, err := c.newRawTransactionOfSender(getAuthKey(sender), payload, feePayerPublicKey != "")
+	if err != nil {
+		return nil, err
+	}
+
+	txn := &MultiAgentTransaction{SecondarySigners: []string{}, SecondaryAuthenticators: []string{}}
+	// the simulation needs the public keys of all signers, the signatures are invalid.
+	simulation := &MultiAgentTransaction{SecondarySigners: []string{}, SecondaryAuthenticators: []string{}}
+	simulation.SenderAuthenticator, err = simulationAuthenticator(sender.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	for _, publicKey := range strings.Split(secondaryPublicKeys, ",") {
+		if publicKey = strings.TrimSpace(publicKey); publicKey == "" {
+			continue
+		}
+		address, authenticator, err := simulationSigner(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		txn.SecondarySigners = append(txn.SecondarySigners, address)
+		txn.SecondaryAuthenticators = append(txn.SecondaryAuthenticators, "")
+		simulation.SecondarySigners = append(simulation.SecondarySigners, address)
+		simulation.SecondaryAuthenticators = append(simulation.SecondaryAuthenticators, authenticator)
+	}
+	if feePayerPublicKey != "" {
+		txn.FeePayer, simulation.FeePayerAuthenticator, err = simulationSigner(feePayerPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		simulation.FeePayer = txn.FeePayer
+	}
+
+	// estimate gas with the same rule as `EstimateMaxGasAmountBCS`
+	rawBytes, err := lcs.Marshal(rawTxn)
+	if err != nil {
+		return nil, err
+	}
+	simulation.RawTransaction = types.HexEncodeToString(rawBytes)
+	simulationTx, err := simulation.signedTransactionBytes()
+	if err != nil {
+		return nil, err
+	}
+	maxGas, err := c.estimateMaxGasAmountOfSimulation(simulationTx)
+	if err != nil {
+		return nil, err
+	}
+	rawTxn.MaxGasAmount = maxGas
+	rawBytes, err = lcs.Marshal(rawTxn)
+	if err != nil {
+		return nil, err
+	}
+	txn.RawTransaction = types.HexEncodeToString(rawBytes)
+	return txn, nil
+}
+
+func (t *MultiAgentTransaction) IsFeePayerTransaction() bool {
+	return t.FeePayer != ""
+}
+
+// The message signed by all signers, it's the `RawTransactionWithData` with the salt prefix.
+func (t *MultiAgentTransaction) SigningMessage() ([]byte, error) {
+	rawBytes, err := types.HexDecodeString(t.RawTransaction)
+	if err != nil {
+		return nil, err
+	}
+	secondaryBytes, err := t.secondarySignersBytes()
+	if err != nil {
+		return nil, err
+	}
+	prefix := sha3.Sum256([]byte(txbuilder.RAW_TRANSACTION_WITH_DATA_SALT))
+	message := bytes.NewBuffer(prefix[:])
+	if t.IsFeePayerTransaction() {
+		message.WriteByte(rawTransactionWithDataFeePayer)
+	} else {
+		message.WriteByte(rawTransactionWithDataMultiAgent)
+	}
+	message.Write(rawBytes)
+	message.Write(secondaryBytes)
+	if t.IsFeePayerTransaction() {
+		feePayer, err := txbuilder.NewAccountAddressFromHex(t.FeePayer)
+		if err != nil {
+			return nil, err
+		}
+		message.Write(feePayer[:])
+	}
+	return message.Bytes(), nil
+}
+
+func (t *MultiAgentTransaction) SignAsSender(account *Account) error {
+	rawTxn, err := t.rawTransaction()
+	if err != nil {
+		return err
+	}
+	if rawTxn.Sender != getAuthKey(account) {
+		return errors.New("The account is not the sender of the transaction")
+	}
+	t.SenderAuthenticator, err = t.signedAuthenticator(account)
+	return err
+}
+
+func (t *MultiAgentTransaction) SignAsSecondarySigner(account *Account) error {
+	address := getAuthKey(account)
+	for i, signer := range t.SecondarySigners {
+		signerAddress, err := txbuilder.NewAccountAddressFromHex(signer)
+		if err != nil {
+			return err
+		}
+		if *signerAddress != address {
+			continue
+		}
+		authenticator, err := t.signedAuthenticator(account)
+		if err != nil {
+			return err
+		}
+		if len(t.SecondaryAuthenticators) != len(t.SecondarySigners) {
+			t.SecondaryAuthenticators = make([]string, len(t.SecondarySigners))
+		}
+		t.SecondaryAuthenticators[i] = authenticator
+		return nil
+	}
+	return errors.New("The account is not the secondary signer of the transaction")
+}
+
+func (t *MultiAgentTransaction) SignAsFeePayer(account *Account) error {
+	if !t.IsFeePayerTransaction() {
+		return errors.New("The transaction is not a fee payer transaction")
+	}
+	feePayer, err := txbuilder.NewAccountAddressFromHex(t.FeePayer)
+	if err != nil {
+		return err
+	}
+	if *feePayer != getAuthKey(account) {
+		return errors.New("The account is not the fee payer of the transaction")
+	}
+	t.FeePayerAuthenticator, err = t.signedAuthenticator(account)
+	return err
+}
+
+func (t *MultiAgentTransaction) IsFullySigned() bool {
+	if t.SenderAuthenticator == "" || len(t.SecondaryAuthenticators) != len(t.SecondarySigners) {
+		return false
+	}
+	for _, authenticator := range t.SecondaryAuthenticators {
+		if authenticator == "" {
+			return false
+		}
+	}
+	return !t.IsFeePayerTransaction() || t.FeePayerAuthenticator != ""
+}
+
+// Assemble the signatures of all signers to the signed transaction.
+// @return the hex string of the BCS signed transaction, it can be sent by `SendRawTransaction`.
+func (t *MultiAgentTransaction) SignedTransaction() (*base.OptionalString, error) {
+	if !t.IsFullySigned() {
+		return nil, errors.New("The transaction is not fully signed")
+	}
+	signedTx, err := t.signedTransactionBytes()
+	if err != nil {
+		return nil, err
+	}
+	return &base.OptionalString{Value: types.HexEncodeToString(signedTx)}, nil
+}
+
+// Assemble and submit the fully signed transaction.
+// @return the hash of the transaction.
+func (c *Chain) SubmitMultiAgentTransaction(txn *MultiAgentTransaction) (*base.OptionalString, error) {
+	signedTx, err := txn.SignedTransaction()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := c.SendRawTransaction(signedTx.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &base.OptionalString{Value: hash}, nil
+}
+
+func (t *MultiAgentTransaction) rawTransaction() (*txbuilder.RawTransaction, error) {
+	rawBytes, err := types.HexDecodeString(t.RawTransaction)
+	if err != nil {
+		return nil, err
+	}
+	rawTxn := &txbuilder.RawTransaction{}
+	err = lcs.Unmarshal(rawBytes, rawTxn)
+	if err != nil {
+		return nil, err
+	}
+	return rawTxn, nil
+}
+
+func (t *MultiAgentTransaction) secondarySignersBytes() ([]byte, error) {
+	addresses := make([]txbuilder.AccountAddress, len(t.SecondarySigners))
+	for i, signer := range t.SecondarySigners {
+		address, err := txbuilder.NewAccountAddressFromHex(signer)
+		if err != nil {
+			return nil, err
+		}
+		addresses[i] = *address
+	}
+	return lcs.Marshal(addresses)
+}
+
+func (t *MultiAgentTransaction) signedAuthenticator(account *Account) (string, error) {
+	message, err := t.SigningMessage()
+	if err != nil {
+		return "", err
+	}
+	signature, err := account.Sign(message, "")
+	if err != nil {
+		return "", err
+	}
+	return ed25519Authenticator(account.PublicKey(), signature)
+}
+
+// The layout is `raw_txn | variant | sender | secondary_signer_addresses | secondary_signers | [fee_payer_address | fee_payer_signer]`
+func (t *MultiAgentTransaction) signedTransactionBytes() ([]byte, error) {
+	rawBytes, err := types.HexDecodeString(t.RawTransaction)
+	if err != nil {
+		return nil, err
+	}
+	secondaryBytes, err := t.secondarySignersBytes()
+	if err != nil {
+		return nil, err
+	}
+	signed := bytes.NewBuffer(rawBytes)
+	if t.IsFeePayerTransaction() {
+		signed.WriteByte(transactionAuthenticatorFeePayer)
+	} else {
+		signed.WriteByte(transactionAuthenticatorMultiAgent)
+	}
+	writeAuthenticator := func(authenticator string) error {
+		data, err := types.HexDecodeString(authenticator)
+		if err != nil {
+			return err
+		}
+		signed.Write(data)
+		return nil
+	}
+	if err = writeAuthenticator(t.SenderAuthenticator); err != nil {
+		return nil, err
+	}
+	signed.Write(secondaryBytes)
+	signed.Write(lcsUleb128(len(t.SecondaryAuthenticators)))
+	for _, authenticator := range t.SecondaryAuthenticators {
+		if err = writeAuthenticator(authenticator); err != nil {
+			return nil, err
+		}
+	}
+	if t.IsFeePayerTransaction() {
+		feePayer, err := txbuilder.NewAccountAddressFromHex(t.FeePayer)
+		if err != nil {
+			return nil, err
+		}
+		signed.Write(feePayer[:])
+		if err = writeAuthenticator(t.FeePayerAuthenticator); err != nil {
+			return nil, err
+		}
+	}
+	return signed.Bytes(), nil
+}
+
+// @return the hex string of the BCS `AccountAuthenticatorEd25519`
+func ed25519Authenticator(publicKey, signature []byte) (string, error) {
+	pubkey, err := txbuilder.NewEd25519PublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sig, err := txbuilder.NewEd25519Signature(signature)
+	if err != nil {
+		return "", err
+	}
+	// the wrapper makes the authenticator serialized as enum with the variant index.
+	data, err := lcs.Marshal(struct {
+		Authenticator txbuilder.AccountAuthenticator `lcs:"authenticator"`
+	}{txbuilder.AccountAuthenticatorEd25519{PublicKey: *pubkey, Signature: *sig}})
+	if err != nil {
+		return "", err
+	}
+	return types.HexEncodeToString(data), nil
+}
+
+func simulationAuthenticator(publicKey []byte) (string, error) {
+	return ed25519Authenticator(publicKey, make([]byte, txbuilder.ED25519_SIGNATURE_LENGTH))
+}
+
+// @return the address and the simulation authenticator of the public key.
+func simulationSigner(publicKey string) (string, string, error) {
+	address, err := EncodePublicKeyToAddress(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+	publicBytes, err := types.HexDecodeString(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+	authenticator, err := simulationAuthenticator(publicBytes)
+	if err != nil {
+		return "", "", err
+	}
+	return address, authenticator, nil
+}
+
+func lcsUleb128(value int) []byte {
+	res := []byte{}
+	v := uint64(value)
+	for v >= 0x80 {
+		res = append(res, byte(v&0x7f|0x80))
+		v >>= 7
+	}
+	return append(res, byte(v))
+}
@@ -0,0 +1,166 @@
+package aptos
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	txbuilder "github.com/coming-chat/go-aptos/transaction_builder"
+	"github.com/coming-chat/lcs"
+	"github.com/stretchr/testify/require"
+)
+
+func newRandomAccount(t *testing.T) *Account {
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := rand.Read(seed)
+	require.Nil(t, err)
+	account, err := AccountWithPrivateKey(types.HexEncodeToString(seed))
+	require.Nil(t, err)
+	return account
+}
+
+func newTestRawTransaction(t *testing.T, sender txbuilder.AccountAddress) *txbuilder.RawTransaction {
+	module, err := txbuilder.NewModuleIdFromString("0x1::aptos_account")
+	require.Nil(t, err)
+	return &txbuilder.RawTransaction{
+		Sender:         sender,
+		SequenceNumber: 1,
+		Payload: txbuilder.TransactionPayloadEntryFunction{
+			ModuleName:   *module,
+			FunctionName: "transfer",
+			TyArgs:       []txbuilder.TypeTag{},
+			Args:         [][]byte{},
+		},
+		MaxGasAmount:            2000,
+		GasUnitPrice:            100,
+		ExpirationTimestampSecs: 1700000000,
+		ChainId:                 2,
+	}
+}
+
+func newTestMultiAgentTransaction(t *testing.T, sender *Account, secondary *Account, feePayer *Account) *MultiAgentTransaction {
+	rawBytes, err := lcs.Marshal(newTestRawTransaction(t, getAuthKey(sender)))
+	require.Nil(t, err)
+	txn := &MultiAgentTransaction{
+		RawTransaction:          types.HexEncodeToString(rawBytes),
+		SecondarySigners:        []string{secondary.Address()},
+		SecondaryAuthenticators: []string{""},
+	}
+	if feePayer != nil {
+		txn.FeePayer = feePayer.Address()
+	}
+	return txn
+}
+
+func TestMultiAgentTransaction(t *testing.T) {
+	sender, secondary := newRandomAccount(t), newRandomAccount(t)
+	txn := newTestMultiAgentTransaction(t, sender, secondary, nil)
+
+	require.NotNil(t, txn.SignAsSender(secondary))
+	require.Nil(t, txn.SignAsSender(sender))
+	_, err := txn.SignedTransaction()
+	require.NotNil(t, err)
+	require.NotNil(t, txn.SignAsFeePayer(secondary))
+	require.Nil(t, txn.SignAsSecondarySigner(secondary))
+	require.True(t, txn.IsFullySigned())
+
+	message, err := txn.SigningMessage()
+	require.Nil(t, err)
+	senderAuth, err := types.HexDecodeString(txn.SenderAuthenticator)
+	require.Nil(t, err)
+	require.Equal(t, append([]byte{0, 32}, sender.PublicKey()...), senderAuth[:34])
+	require.True(t, ed25519.Verify(sender.PublicKey(), message, senderAuth[35:]))
+
+	// the multi-agent authenticator is supported by the transaction builder, the result should be the same.
+	signedTx, err := txn.SignedTransaction()
+	require.Nil(t, err)
+	rawTxn, err := txn.rawTransaction()
+	require.Nil(t, err)
+	secondaryAuth, err := types.HexDecodeString(txn.SecondaryAuthenticators[0])
+	require.Nil(t, err)
+	authenticatorOf := func(data []byte) txbuilder.AccountAuthenticatorEd25519 {
+		return txbuilder.AccountAuthenticatorEd25519{
+			PublicKey: txbuilder.Ed25519PublicKey{PublicKey: data[2:34]},
+			Signature: txbuilder.Ed25519Signature{Signature: data[35:]},
+		}
+	}
+	expected, err := lcs.Marshal(txbuilder.SignedTransaction{
+		Transaction: rawTxn,
+		Authenticator: txbuilder.TransactionAuthenticatorMultiAgent{
+			Sender:                   authenticatorOf(senderAuth),
+			SecondarySignerAddresses: []txbuilder.AccountAddress{getAuthKey(secondary)},
+			SecondarySigners:         []txbuilder.AccountAuthenticator{authenticatorOf(secondaryAuth)},
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, types.HexEncodeToString(expected), signedTx.Value)
+}
+
+func TestFeePayerTransaction(t *testing.T) {
+	sender, secondary, feePayer := newRandomAccount(t), newRandomAccount(t), newRandomAccount(t)
+	txn := newTestMultiAgentTransaction(t, sender, secondary, feePayer)
+	require.True(t, txn.IsFeePayerTransaction())
+
+	require.Nil(t, txn.SignAsSender(sender))
+	require.Nil(t, txn.SignAsSecondarySigner(secondary))
+	require.False(t, txn.IsFullySigned())
+	require.NotNil(t, txn.SignAsFeePayer(sender))
+	require.Nil(t, txn.SignAsFeePayer(feePayer))
+	require.True(t, txn.IsFullySigned())
+
+	message, err := txn.SigningMessage()
+	require.Nil(t, err)
+	feePayerAddress := getAuthKey(feePayer)
+	require.Equal(t, feePayerAddress[:], message[len(message)-32:])
+
+	// the json string can be passed to other signers.
+	jsonString, err := txn.JsonString()
+	require.Nil(t, err)
+	decoded, err := NewMultiAgentTransactionWithJsonString(jsonString.Value)
+	require.Nil(t, err)
+	require.Equal(t, txn, decoded)
+
+	signedTx, err := txn.SignedTransaction()
+	require.Nil(t, err)
+	rawBytes, err := types.HexDecodeString(txn.RawTransaction)
+	require.Nil(t, err)
+	signedBytes, err := types.HexDecodeString(signedTx.Value)
+	require.Nil(t, err)
+	require.Equal(t, byte(transactionAuthenticatorFeePayer), signedBytes[len(rawBytes)])
+	feePayerAuth, err := types.HexDecodeString(txn.FeePayerAuthenticator)
+	require.Nil(t, err)
+	require.Equal(t, feePayerAuth, signedBytes[len(signedBytes)-len(feePayerAuth):])
+}
+
+func TestNewRawTransactionOfNewAccount(t *testing.T) {
+	// the rest api of the node which doesn't have the sender's account.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1":
+			_, _ = w.Write([]byte(`{"chain_id":2,"ledger_version":"100","ledger_timestamp":"1700000000","block_height":"10"}`))
+		case r.URL.Path == "/v1/estimate_gas_price":
+			_, _ = w.Write([]byte(`{"gas_estimate":100}`))
+		case strings.HasPrefix(r.URL.Path, "/v1/accounts/"):
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Account not found","error_code":"account_not_found"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	chain := NewChainWithRestUrl(server.URL)
+	sender := getAuthKey(newRandomAccount(t))
+
+	_, err := chain.newRawTransaction(sender, nil)
+	require.True(t, isNotFoundError(err))
+
+	rawTxn, err := chain.newRawTransactionOfSender(sender, nil, true)
+	require.Nil(t, err)
+	require.Equal(t, uint64(0), rawTxn.SequenceNumber)
+	require.Equal(t, uint64(100), rawTxn.GasUnitPrice)
+	require.Equal(t, uint8(2), rawTxn.ChainId)
+}
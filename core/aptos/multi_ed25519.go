@@ -0,0 +1,285 @@
+package aptos
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	txbuilder "github.com/coming-chat/go-aptos/transaction_builder"
+	"github.com/coming-chat/lcs"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+// The K-of-N MultiEd25519 account, the address is derived from the public keys (the order matters) and the threshold.
+type MultiEd25519Account struct {
+	// The hex strings of the ed25519 public keys.
+	PublicKeys []string `json:"publicKeys"`
+	Threshold  int      `json:"threshold"`
+}
+
+// @param publicKeys the hex strings of the public keys, separated by ",".
+// @param threshold the number of signatures required, it should be in range [1, count of public keys].
+func NewMultiEd25519Account(publicKeys string, threshold int) (*MultiEd25519Account, error) {
+	keys := []string{}
+	for _, key := range strings.Split(publicKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	account := &MultiEd25519Account{PublicKeys: keys, Threshold: threshold}
+	if _, err := account.multiPublicKey(); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func NewMultiEd25519AccountWithJsonString(str string) (*MultiEd25519Account, error) {
+	var o MultiEd25519Account
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (a *MultiEd25519Account) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(a)
+}
+
+func (a *MultiEd25519Account) Address() (string, error) {
+	publicKey, err := a.multiPublicKey()
+	if err != nil {
+		return "", err
+	}
+	return publicKey.Address(), nil
+}
+
+func (a *MultiEd25519Account) multiPublicKey() (*txbuilder.MultiEd25519PublicKey, error) {
+	if a.Threshold < 1 || a.Threshold > len(a.PublicKeys) {
+		return nil, errors.New("Invalid threshold")
+	}
+	if len(a.PublicKeys) > txbuilder.MAX_SIGNATURES_SUPPORTED {
+		return nil, errors.New("Too many public keys")
+	}
+	keys := make([][]byte, len(a.PublicKeys))
+	for i, key := range a.PublicKeys {
+		bytes, err := types.HexDecodeString(key)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = bytes
+	}
+	return txbuilder.NewMultiEd25519PublicKey(keys, uint8(a.Threshold))
+}
+
+// The transaction sent by the MultiEd25519 account, the owners of public keys sign it separately,
+// it can be submitted after `Threshold` signatures are collected.
+type MultiEd25519Transaction struct {
+	// The hex string of the BCS raw transaction.
+	RawTransaction string               `json:"rawTransaction"`
+	Account        *MultiEd25519Account `json:"account"`
+	// The hex strings of the signatures, the index is same as the public key's. Empty means not signed.
+	Signatures []string `json:"signatures"`
+}
+
+func NewMultiEd25519TransactionWithJsonString(str string) (*MultiEd25519Transaction, error) {
+	var o MultiEd25519Transaction
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (t *MultiEd25519Transaction) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(t)
+}
+
+// @param payload the BCS data of `TransactionPayloadEntryFunction`
+func (c *Chain) BuildMultiEd25519Transaction(account *MultiEd25519Account, payload []byte) (*MultiEd25519Transaction, error) {
+	entryFunction := txbuilder.TransactionPayloadEntryFunction{}
+	if err := lcs.Unmarshal(payload, &entryFunction); err != nil {
+		return nil, err
+	}
+	address, err := account.Address()
+	if err != nil {
+		return nil, err
+	}
+	sender, err := txbuilder.NewAccountAddressFromHex(address)
+	if err != nil {
+		return nil, err
+	}
+	rawTxn, err := c.newRawTransaction(*sender, entryFunction)
+	if err != nil {
+		return nil, err
+	}
+
+	// estimate gas with the same rule as `EstimateMaxGasAmountBCS`, the simulation needs `Threshold` invalid signatures.
+	simulation := &MultiEd25519Transaction{Account: account, Signatures: make([]string, len(account.PublicKeys))}
+	for i := 0; i < account.Threshold; i++ {
+		simulation.Signatures[i] = types.HexEncodeToString(make([]byte, txbuilder.ED25519_SIGNATURE_LENGTH))
+	}
+	simulationTx, err := simulation.signedTransactionBytes(rawTxn)
+	if err != nil {
+		return nil, err
+	}
+	maxGas, err := c.estimateMaxGasAmountOfSimulation(simulationTx)
+	if err != nil {
+		return nil, err
+	}
+	rawTxn.MaxGasAmount = maxGas
+
+	rawBytes, err := lcs.Marshal(rawTxn)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiEd25519Transaction{
+		RawTransaction: types.HexEncodeToString(rawBytes),
+		Account:        account,
+		Signatures:     make([]string, len(account.PublicKeys)),
+	}, nil
+}
+
+// The message that every owner signs, it's the raw transaction with the salt prefix.
+func (t *MultiEd25519Transaction) SigningMessage() ([]byte, error) {
+	rawTxn, err := t.rawTransaction()
+	if err != nil {
+		return nil, err
+	}
+	return rawTxn.GetSigningMessage()
+}
+
+// Sign the transaction with the owner's account.
+func (t *MultiEd25519Transaction) SignWithAccount(account *Account) error {
+	message, err := t.SigningMessage()
+	if err != nil {
+		return err
+	}
+	signature, err := account.Sign(message, "")
+	if err != nil {
+		return err
+	}
+	return t.AddSignature(account.PublicKeyHex(), types.HexEncodeToString(signature))
+}
+
+// Add the signature collected from the owner, the signature will be verified.
+func (t *MultiEd25519Transaction) AddSignature(publicKey, signature string) error {
+	publicBytes, err := types.HexDecodeString(publicKey)
+	if err != nil {
+		return err
+	}
+	signatureBytes, err := types.HexDecodeString(signature)
+	if err != nil {
+		return err
+	}
+	message, err := t.SigningMessage()
+	if err != nil {
+		return err
+	}
+	if len(publicBytes) != ed25519.PublicKeySize || !ed25519.Verify(publicBytes, message, signatureBytes) {
+		return errors.New("Invalid signature")
+	}
+	for i, key := range t.Account.PublicKeys {
+		keyBytes, err := types.HexDecodeString(key)
+		if err != nil {
+			return err
+		}
+		if string(keyBytes) == string(publicBytes) {
+			if len(t.Signatures) != len(t.Account.PublicKeys) {
+				t.Signatures = make([]string, len(t.Account.PublicKeys))
+			}
+			t.Signatures[i] = types.HexEncodeToString(signatureBytes)
+			return nil
+		}
+	}
+	return errors.New("The public key is not the owner of the account")
+}
+
+func (t *MultiEd25519Transaction) SignedCount() int {
+	count := 0
+	for _, signature := range t.Signatures {
+		if signature != "" {
+			count++
+		}
+	}
+	return count
+}
+
+func (t *MultiEd25519Transaction) IsFullySigned() bool {
+	return t.SignedCount() >= t.Account.Threshold
+}
+
+// Assemble the collected signatures to the `MultiEd25519` authenticator, only the first `Threshold` signatures are used.
+// @return the hex string of the BCS signed transaction, it can be sent by `SendRawTransaction`.
+func (t *MultiEd25519Transaction) SignedTransaction() (*base.OptionalString, error) {
+	if !t.IsFullySigned() {
+		return nil, errors.New("The transaction is not fully signed")
+	}
+	rawTxn, err := t.rawTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := t.signedTransactionBytes(rawTxn)
+	if err != nil {
+		return nil, err
+	}
+	return &base.OptionalString{Value: types.HexEncodeToString(signedTx)}, nil
+}
+
+// Assemble and submit the fully signed transaction.
+// @return the hash of the transaction.
+func (c *Chain) SubmitMultiEd25519Transaction(txn *MultiEd25519Transaction) (*base.OptionalString, error) {
+	signedTx, err := txn.SignedTransaction()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := c.SendRawTransaction(signedTx.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &base.OptionalString{Value: hash}, nil
+}
+
+func (t *MultiEd25519Transaction) rawTransaction() (*txbuilder.RawTransaction, error) {
+	rawBytes, err := types.HexDecodeString(t.RawTransaction)
+	if err != nil {
+		return nil, err
+	}
+	rawTxn := &txbuilder.RawTransaction{}
+	err = lcs.Unmarshal(rawBytes, rawTxn)
+	if err != nil {
+		return nil, err
+	}
+	return rawTxn, nil
+}
+
+func (t *MultiEd25519Transaction) signedTransactionBytes(rawTxn *txbuilder.RawTransaction) ([]byte, error) {
+	publicKey, err := t.Account.multiPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	// the signatures must be ordered by the bits of bitmap.
+	signatures := []txbuilder.Ed25519Signature{}
+	bits := []uint8{}
+	for i, signature := range t.Signatures {
+		if signature == "" || len(bits) >= t.Account.Threshold {
+			continue
+		}
+		bytes, err := types.HexDecodeString(signature)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := txbuilder.NewEd25519Signature(bytes)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, *sig)
+		bits = append(bits, uint8(i))
+	}
+	bitmap, err := txbuilder.CreateBitmap(bits)
+	if err != nil {
+		return nil, err
+	}
+	return lcs.Marshal(txbuilder.SignedTransaction{
+		Transaction: rawTxn,
+		Authenticator: txbuilder.TransactionAuthenticatorMultiEd25519{
+			PublicKey: *publicKey,
+			Signature: txbuilder.MultiEd25519Signature{Signatures: signatures, Bitmap: bitmap},
+		},
+	})
+}
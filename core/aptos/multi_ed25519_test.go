@@ -0,0 +1,70 @@
+package aptos
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/go-aptos/aptosaccount"
+	txbuilder "github.com/coming-chat/go-aptos/transaction_builder"
+	"github.com/coming-chat/lcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiEd25519Transaction(t *testing.T) {
+	owners := []*Account{newRandomAccount(t), newRandomAccount(t), newRandomAccount(t)}
+	publicKeys := make([]string, len(owners))
+	publicBytes := make([][]byte, len(owners))
+	for i, owner := range owners {
+		publicKeys[i] = owner.PublicKeyHex()
+		publicBytes[i] = owner.PublicKey()
+	}
+	_, err := NewMultiEd25519Account(strings.Join(publicKeys, ","), 4)
+	require.NotNil(t, err)
+	account, err := NewMultiEd25519Account(strings.Join(publicKeys, ","), 2)
+	require.Nil(t, err)
+
+	address, err := account.Address()
+	require.Nil(t, err)
+	authKey, err := aptosaccount.GenerateMultisignerAuthKey(publicBytes, 2)
+	require.Nil(t, err)
+	require.Equal(t, types.HexEncodeToString(authKey[:]), address)
+
+	rawBytes, err := lcs.Marshal(newTestRawTransaction(t, authKey))
+	require.Nil(t, err)
+	txn := &MultiEd25519Transaction{
+		RawTransaction: types.HexEncodeToString(rawBytes),
+		Account:        account,
+		Signatures:     make([]string, len(owners)),
+	}
+
+	// sign out of order, and the signature of other account should be rejected.
+	require.Nil(t, txn.SignWithAccount(owners[2]))
+	require.NotNil(t, txn.SignWithAccount(newRandomAccount(t)))
+	require.False(t, txn.IsFullySigned())
+	_, err = txn.SignedTransaction()
+	require.NotNil(t, err)
+	message, err := txn.SigningMessage()
+	require.Nil(t, err)
+	signature, err := owners[0].Sign(message, "")
+	require.Nil(t, err)
+	require.NotNil(t, txn.AddSignature(owners[1].PublicKeyHex(), types.HexEncodeToString(signature)))
+	require.Nil(t, txn.AddSignature(owners[0].PublicKeyHex(), types.HexEncodeToString(signature)))
+	require.Equal(t, 2, txn.SignedCount())
+
+	signedTx, err := txn.SignedTransaction()
+	require.Nil(t, err)
+	signedBytes, err := types.HexDecodeString(signedTx.Value)
+	require.Nil(t, err)
+	require.Equal(t, rawBytes, signedBytes[:len(rawBytes)])
+	// variant | len | publicKeys | threshold | signatures | bitmap
+	auth := signedBytes[len(rawBytes):]
+	require.Equal(t, byte(1), auth[0])
+	multiSignature := txbuilder.MultiEd25519Signature{}
+	err = lcs.Unmarshal(auth[1+1+len(owners)*32+1:], &multiSignature)
+	require.Nil(t, err)
+	require.Equal(t, []byte{0b10100000, 0, 0, 0}, multiSignature.Bitmap)
+	require.True(t, ed25519.Verify(owners[0].PublicKey(), message, multiSignature.Signatures[0].Signature))
+	require.True(t, ed25519.Verify(owners[2].PublicKey(), message, multiSignature.Signatures[1].Signature))
+}
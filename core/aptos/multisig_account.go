@@ -0,0 +1,300 @@
+package aptos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	txbuilder "github.com/coming-chat/go-aptos/transaction_builder"
+	"github.com/coming-chat/lcs"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	multisigAccountModule   = "0x1::multisig_account"
+	multisigAccountResource = "0x1::multisig_account::MultisigAccount"
+	multisigAccountDomain   = "aptos_framework::multisig_account"
+
+	// The variant of `TransactionPayload::Multisig`, it's not registered in the transaction builder.
+	transactionPayloadMultisig = 3
+	// The variant of `MultisigTransactionPayload::EntryFunction`
+	multisigTransactionPayloadEntryFunction = 0
+	// The scheme of resource account address derivation.
+	deriveResourceAccountScheme = 0xFF
+)
+
+/*
+* The on-chain multisig account of the module `0x1::multisig_account`.
+
+	### Demo
+	```
+	// one of the owners proposes the transaction
+	var payload, err = MultisigProposePayload(multisigAddress, transferPayload)
+	var hash, err = chain.SubmitTransactionPayloadBCS(owner1, payload)
+	// the other owners approve the transaction with the sequence number
+	var payload, err = MultisigApprovePayload(multisigAddress, sequenceNumber)
+	var hash, err = chain.SubmitTransactionPayloadBCS(owner2, payload)
+	// any owner executes it after enough approvals
+	var signedTx, err = chain.BuildMultisigExecuteTx(owner1, multisigAddress, nil)
+	var hash, err = chain.SendRawTransaction(signedTx.Value)
+	```
+*/
+type MultisigAccountInfo struct {
+	Address   string   `json:"address"`
+	Owners    []string `json:"owners"`
+	Threshold int64    `json:"threshold"`
+
+	// The sequence number of the next proposed transaction.
+	NextSequenceNumber         int64 `json:"nextSequenceNumber"`
+	LastExecutedSequenceNumber int64 `json:"lastExecutedSequenceNumber"`
+}
+
+func (i *MultisigAccountInfo) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(i)
+}
+
+// The pending transactions are in range (`LastExecutedSequenceNumber`, `NextSequenceNumber`)
+func (i *MultisigAccountInfo) PendingCount() int64 {
+	return i.NextSequenceNumber - i.LastExecutedSequenceNumber - 1
+}
+
+func (c *Chain) FetchMultisigAccount(address string) (info *MultisigAccountInfo, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := c.client()
+	if err != nil {
+		return
+	}
+	res, err := client.GetAccountResource(address, multisigAccountResource, 0)
+	if err != nil {
+		return
+	}
+	jsonData, err := json.Marshal(res.Data)
+	if err != nil {
+		return
+	}
+	data := struct {
+		Owners                     []string `json:"owners"`
+		NumSignaturesRequired      string   `json:"num_signatures_required"`
+		NextSequenceNumber         string   `json:"next_sequence_number"`
+		LastExecutedSequenceNumber string   `json:"last_executed_sequence_number"`
+	}{}
+	err = json.Unmarshal(jsonData, &data)
+	if err != nil {
+		return
+	}
+	threshold, _ := strconv.ParseInt(data.NumSignaturesRequired, 10, 64)
+	next, _ := strconv.ParseInt(data.NextSequenceNumber, 10, 64)
+	last, _ := strconv.ParseInt(data.LastExecutedSequenceNumber, 10, 64)
+	return &MultisigAccountInfo{
+		Address:   address,
+		Owners:    data.Owners,
+		Threshold: threshold,
+
+		NextSequenceNumber:         next,
+		LastExecutedSequenceNumber: last,
+	}, nil
+}
+
+// The address of multisig account that will be created by the creator's next transaction.
+func (c *Chain) NextMultisigAccountAddress(creator string) (s *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := c.client()
+	if err != nil {
+		return
+	}
+	account, err := client.GetAccount(creator)
+	if err != nil {
+		return
+	}
+	address, err := MultisigAccountAddress(creator, int64(account.SequenceNumber))
+	if err != nil {
+		return
+	}
+	return &base.OptionalString{Value: address}, nil
+}
+
+// The multisig account address is the resource account address with the seed `domain | bcs(sequenceNumber)`
+// @param sequenceNumber the sequence number of the creator when the multisig account is created.
+func MultisigAccountAddress(creator string, sequenceNumber int64) (string, error) {
+	creatorAddress, err := txbuilder.NewAccountAddressFromHex(creator)
+	if err != nil {
+		return "", err
+	}
+	data := append([]byte{}, creatorAddress[:]...)
+	data = append(data, []byte(multisigAccountDomain)...)
+	data = binary.LittleEndian.AppendUint64(data, uint64(sequenceNumber))
+	data = append(data, deriveResourceAccountScheme)
+	address := sha3.Sum256(data)
+	return types.HexEncodeToString(address[:]), nil
+}
+
+// MARK - Payload builders, the payloads can be submitted by `SubmitTransactionPayloadBCS`
+
+// Create the multisig account, the creator is one of the owners.
+// @param additionalOwners the other owners' addresses, separated by ",".
+// @param threshold the number of approvals required to execute a transaction.
+func MultisigCreatePayload(additionalOwners string, threshold int64) ([]byte, error) {
+	owners := []txbuilder.AccountAddress{}
+	for _, owner := range strings.Split(additionalOwners, ",") {
+		if owner = strings.TrimSpace(owner); owner == "" {
+			continue
+		}
+		address, err := txbuilder.NewAccountAddressFromHex(owner)
+		if err != nil {
+			return nil, errors.New("Invalid owner address: " + owner)
+		}
+		owners = append(owners, *address)
+	}
+	if threshold < 1 || threshold > int64(len(owners)+1) {
+		return nil, errors.New("Invalid threshold")
+	}
+	ownersBytes, err := lcs.Marshal(owners)
+	if err != nil {
+		return nil, err
+	}
+	return multisigEntryFunctionPayload("create_with_owners", [][]byte{
+		ownersBytes,
+		txbuilder.BCSSerializeBasicValue(uint64(threshold)),
+		{0}, // metadata_keys: empty vector<String>
+		{0}, // metadata_values: empty vector<vector<u8>>
+	})
+}
+
+// Propose the transaction, the proposer approves it automatically.
+// @param payload the BCS data of `TransactionPayloadEntryFunction` that the multisig account will execute.
+func MultisigProposePayload(multisigAddress string, payload []byte) ([]byte, error) {
+	address, err := txbuilder.NewAccountAddressFromHex(multisigAddress)
+	if err != nil {
+		return nil, errors.New("Invalid multisig account address")
+	}
+	if err := lcs.Unmarshal(payload, &txbuilder.TransactionPayloadEntryFunction{}); err != nil {
+		return nil, err
+	}
+	multisigPayload, err := lcs.Marshal(append([]byte{multisigTransactionPayloadEntryFunction}, payload...))
+	if err != nil {
+		return nil, err
+	}
+	return multisigEntryFunctionPayload("create_transaction", [][]byte{
+		address[:],
+		multisigPayload,
+	})
+}
+
+func MultisigApprovePayload(multisigAddress string, sequenceNumber int64) ([]byte, error) {
+	return multisigVotePayload("approve_transaction", multisigAddress, sequenceNumber)
+}
+
+func MultisigRejectPayload(multisigAddress string, sequenceNumber int64) ([]byte, error) {
+	return multisigVotePayload("reject_transaction", multisigAddress, sequenceNumber)
+}
+
+func multisigVotePayload(function, multisigAddress string, sequenceNumber int64) ([]byte, error) {
+	address, err := txbuilder.NewAccountAddressFromHex(multisigAddress)
+	if err != nil {
+		return nil, errors.New("Invalid multisig account address")
+	}
+	return multisigEntryFunctionPayload(function, [][]byte{
+		address[:],
+		txbuilder.BCSSerializeBasicValue(uint64(sequenceNumber)),
+	})
+}
+
+func multisigEntryFunctionPayload(function string, args [][]byte) ([]byte, error) {
+	module, err := txbuilder.NewModuleIdFromString(multisigAccountModule)
+	if err != nil {
+		return nil, err
+	}
+	payload := txbuilder.TransactionPayloadEntryFunction{
+		ModuleName:   *module,
+		FunctionName: txbuilder.Identifier(function),
+		TyArgs:       []txbuilder.TypeTag{},
+		Args:         args,
+	}
+	return lcs.Marshal(txbuilder.TransactionPayload(payload))
+}
+
+// MARK - Execute
+
+// Build the transaction that executes the next approved transaction of the multisig account, the executor must be one of the owners.
+// @param payload the BCS data of `TransactionPayloadEntryFunction`, it's required only if the proposal stored the payload hash, otherwise nil.
+// @return the signed transaction, it can be sent by `SendRawTransaction`.
+func (c *Chain) BuildMultisigExecuteTx(account *Account, multisigAddress string, payload []byte) (*base.OptionalString, error) {
+	address, err := txbuilder.NewAccountAddressFromHex(multisigAddress)
+	if err != nil {
+		return nil, errors.New("Invalid multisig account address")
+	}
+	// TransactionPayload::Multisig { multisig_address, transaction_payload: Option<MultisigTransactionPayload> }
+	payloadBytes := append([]byte{transactionPayloadMultisig}, address[:]...)
+	if len(payload) == 0 {
+		payloadBytes = append(payloadBytes, 0)
+	} else {
+		if err := lcs.Unmarshal(payload, &txbuilder.TransactionPayloadEntryFunction{}); err != nil {
+			return nil, err
+		}
+		payloadBytes = append(payloadBytes, 1, multisigTransactionPayloadEntryFunction)
+		payloadBytes = append(payloadBytes, payload...)
+	}
+
+	rawTxn, err := c.newRawTransaction(getAuthKey(account), nil)
+	if err != nil {
+		return nil, err
+	}
+	simulationAuth, err := simulationAuthenticator(account.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	simulationTx, err := signedTransactionBytesWithPayload(rawTxn, payloadBytes, simulationAuth)
+	if err != nil {
+		return nil, err
+	}
+	maxGas, err := c.estimateMaxGasAmountOfSimulation(simulationTx)
+	if err != nil {
+		return nil, err
+	}
+	rawTxn.MaxGasAmount = maxGas
+
+	prefix := sha3.Sum256([]byte(txbuilder.RAW_TRANSACTION_SALT))
+	message := append(prefix[:], rawTransactionBytesWithPayload(rawTxn, payloadBytes)...)
+	signature, err := account.Sign(message, "")
+	if err != nil {
+		return nil, err
+	}
+	authenticator, err := ed25519Authenticator(account.PublicKey(), signature)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := signedTransactionBytesWithPayload(rawTxn, payloadBytes, authenticator)
+	if err != nil {
+		return nil, err
+	}
+	return &base.OptionalString{Value: types.HexEncodeToString(signedTx)}, nil
+}
+
+// Serialize the raw transaction with the BCS payload, the `Payload` of the raw transaction is ignored.
+func rawTransactionBytesWithPayload(rawTxn *txbuilder.RawTransaction, payload []byte) []byte {
+	raw := bytes.NewBuffer(nil)
+	raw.Write(rawTxn.Sender[:])
+	raw.Write(binary.LittleEndian.AppendUint64(nil, rawTxn.SequenceNumber))
+	raw.Write(payload)
+	raw.Write(binary.LittleEndian.AppendUint64(nil, rawTxn.MaxGasAmount))
+	raw.Write(binary.LittleEndian.AppendUint64(nil, rawTxn.GasUnitPrice))
+	raw.Write(binary.LittleEndian.AppendUint64(nil, rawTxn.ExpirationTimestampSecs))
+	raw.WriteByte(rawTxn.ChainId)
+	return raw.Bytes()
+}
+
+// @param authenticator the hex string of the ed25519 account authenticator, it has the same layout as the ed25519 transaction authenticator.
+func signedTransactionBytesWithPayload(rawTxn *txbuilder.RawTransaction, payload []byte, authenticator string) ([]byte, error) {
+	authBytes, err := types.HexDecodeString(authenticator)
+	if err != nil {
+		return nil, err
+	}
+	return append(rawTransactionBytesWithPayload(rawTxn, payload), authBytes...), nil
+}
@@ -0,0 +1,47 @@
+package aptos
+
+import (
+	"testing"
+
+	txbuilder "github.com/coming-chat/go-aptos/transaction_builder"
+	"github.com/coming-chat/lcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawTransactionBytesWithPayload(t *testing.T) {
+	rawTxn := newTestRawTransaction(t, getAuthKey(newRandomAccount(t)))
+	expected, err := lcs.Marshal(rawTxn)
+	require.Nil(t, err)
+
+	payload, err := lcs.Marshal(rawTxn.Payload)
+	require.Nil(t, err)
+	// the variant of `TransactionPayload::EntryFunction` is 2
+	payload = append([]byte{2}, payload...)
+	require.Equal(t, expected, rawTransactionBytesWithPayload(rawTxn, payload))
+}
+
+func TestMultisigPayload(t *testing.T) {
+	owner := newRandomAccount(t)
+	_, err := MultisigCreatePayload(owner.Address(), 3)
+	require.NotNil(t, err)
+	data, err := MultisigCreatePayload(owner.Address(), 2)
+	require.Nil(t, err)
+	payload := txbuilder.TransactionPayloadEntryFunction{}
+	require.Nil(t, lcs.Unmarshal(data, &payload))
+	require.Equal(t, "create_with_owners", string(payload.FunctionName))
+	require.Equal(t, 4, len(payload.Args))
+
+	multisigAddress, err := MultisigAccountAddress(owner.Address(), 0)
+	require.Nil(t, err)
+	require.True(t, IsValidAddress(multisigAddress))
+
+	transfer, err := lcs.Marshal(newTestRawTransaction(t, getAuthKey(owner)).Payload)
+	require.Nil(t, err)
+	data, err = MultisigProposePayload(multisigAddress, transfer)
+	require.Nil(t, err)
+	require.Nil(t, lcs.Unmarshal(data, &payload))
+	require.Equal(t, "create_transaction", string(payload.FunctionName))
+	var multisigPayload []byte
+	require.Nil(t, lcs.Unmarshal(payload.Args[1], &multisigPayload))
+	require.Equal(t, append([]byte{0}, transfer...), multisigPayload)
+}
@@ -0,0 +1,326 @@
+package aptos
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coming-chat/go-aptos/aptosclient"
+	"github.com/coming-chat/go-aptos/aptostypes"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+const (
+	fungibleStoreResource = "0x1::fungible_asset::FungibleStore"
+	objectCoreResource    = "0x1::object::ObjectCore"
+)
+
+var (
+	// e.g. `Move abort in 0x1::coin: EINSUFFICIENT_BALANCE(0x10006): Not enough coins to complete transaction`
+	moveAbortRegexp = regexp.MustCompile(`^Move abort in (0x[0-9a-fA-F]+::\w+): (\w+)\((0x[0-9a-fA-F]+)\):\s*(.*)$`)
+	// e.g. `Move abort in 0x1::coin: 0x10006`, the module has no error map.
+	moveAbortCodeRegexp = regexp.MustCompile(`^Move abort in (0x[0-9a-fA-F]+::\w+): (0x[0-9a-fA-F]+)$`)
+)
+
+// The preview of the transaction, it shows what the transaction does before signing.
+type SimulationResult struct {
+	Success  bool   `json:"success"`
+	VmStatus string `json:"vmStatus"`
+
+	// The abort info parsed from `VmStatus`, they are empty if the transaction is not aborted by the move code.
+	AbortLocation    string `json:"abortLocation"` // e.g. 0x1::coin
+	AbortCode        string `json:"abortCode"`     // e.g. 0x10006
+	AbortReason      string `json:"abortReason"`   // e.g. EINSUFFICIENT_BALANCE
+	AbortDescription string `json:"abortDescription"`
+
+	GasUsed      int64  `json:"gasUsed"`
+	GasUnitPrice int64  `json:"gasUnitPrice"`
+	GasFee       string `json:"gasFee"`
+
+	Events []*SimulationEvent `json:"events"`
+	// The balance changes of the sender, the gas fee is included in the change of the main token.
+	BalanceChanges []*BalanceChange `json:"balanceChanges"`
+}
+
+type SimulationEvent struct {
+	Type string `json:"type"`
+	// The json string of the event data.
+	Data string `json:"data"`
+}
+
+type BalanceChange struct {
+	// `TokenStandardCoin` or `TokenStandardFungibleAsset`
+	Standard string `json:"standard"`
+	// The coin tag or the address of the fungible asset metadata object.
+	TokenType string `json:"tokenType"`
+	// The address of the fungible store, it's empty if the token is coin.
+	StoreAddress string `json:"storeAddress"`
+
+	Before string `json:"before"`
+	After  string `json:"after"`
+	// The signed amount, e.g. "-100" means the balance is decreased by 100.
+	Change string `json:"change"`
+}
+
+func (r *SimulationResult) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(r)
+}
+
+func NewSimulationResultWithJsonString(str string) (*SimulationResult, error) {
+	var o SimulationResult
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+// Simulate the payload that is sent by the dApp, the failed simulation also returns the result with the vm status.
+func (c *Chain) SimulateTransaction(senderPublicKey string, payload aptostypes.Payload) (res *SimulationResult, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	txn, err := c.simulateEntryFunctionPayload(senderPublicKey, payload)
+	if err != nil {
+		return
+	}
+	sender, err := EncodePublicKeyToAddress(senderPublicKey)
+	if err != nil {
+		return
+	}
+	res, err = simulationResultOfTransaction(txn)
+	if err != nil {
+		return
+	}
+	res.BalanceChanges, err = c.fetchBalanceChanges(sender, txn.Changes)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// The payload is same as `GenerateTransactionJson`.
+func (c *Chain) SimulateTransactionJson(senderPublicKey string, payload string) (*SimulationResult, error) {
+	var payloadObj aptostypes.Payload
+	err := json.Unmarshal([]byte(payload), &payloadObj)
+	if err != nil {
+		return nil, err
+	}
+	return c.SimulateTransaction(senderPublicKey, payloadObj)
+}
+
+// The result without balance changes, they need the on-chain states before the transaction.
+func simulationResultOfTransaction(txn *aptostypes.Transaction) (*SimulationResult, error) {
+	res := &SimulationResult{
+		Success:      txn.Success,
+		VmStatus:     txn.VmStatus,
+		GasUsed:      int64(txn.GasUsed),
+		GasUnitPrice: int64(txn.GasUnitPrice),
+		GasFee:       strconv.FormatUint(txn.GasUsed*txn.GasUnitPrice, 10),
+		Events:       make([]*SimulationEvent, 0, len(txn.Events)),
+	}
+	if !txn.Success {
+		if match := moveAbortRegexp.FindStringSubmatch(txn.VmStatus); match != nil {
+			res.AbortLocation, res.AbortReason, res.AbortCode, res.AbortDescription = match[1], match[2], match[3], match[4]
+		} else if match := moveAbortCodeRegexp.FindStringSubmatch(txn.VmStatus); match != nil {
+			res.AbortLocation, res.AbortCode = match[1], match[2]
+		}
+	}
+	for _, event := range txn.Events {
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			return nil, err
+		}
+		res.Events = append(res.Events, &SimulationEvent{Type: event.Type, Data: string(data)})
+	}
+	return res, nil
+}
+
+// MARK - Balance changes
+
+// The store written by the transaction.
+type storeChange struct {
+	standard     string
+	tokenType    string
+	storeAddress string
+	// the owner of the fungible store, it's empty if it's not in the write set.
+	owner   string
+	balance string
+}
+
+// Collect the coin stores and fungible stores from the write set.
+func storeChangesOf(changes []aptostypes.Change) ([]*storeChange, error) {
+	stores := []*storeChange{}
+	owners := map[string]string{}
+	for _, change := range changes {
+		if change.Type != "write_resource" {
+			continue
+		}
+		resource := struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}{}
+		if err := remarshal(change.Data, &resource); err != nil {
+			return nil, err
+		}
+		address := normalizeAddress(change.Address)
+		switch {
+		case strings.HasPrefix(resource.Type, coinStorePrefix):
+			store := struct {
+				Coin struct {
+					Value string `json:"value"`
+				} `json:"coin"`
+			}{}
+			if err := json.Unmarshal(resource.Data, &store); err != nil {
+				return nil, err
+			}
+			stores = append(stores, &storeChange{
+				standard:     TokenStandardCoin,
+				tokenType:    strings.TrimSuffix(strings.TrimPrefix(resource.Type, coinStorePrefix), ">"),
+				storeAddress: address,
+				owner:        address,
+				balance:      store.Coin.Value,
+			})
+		case resource.Type == fungibleStoreResource:
+			store := struct {
+				Metadata struct {
+					Inner string `json:"inner"`
+				} `json:"metadata"`
+				Balance string `json:"balance"`
+			}{}
+			if err := json.Unmarshal(resource.Data, &store); err != nil {
+				return nil, err
+			}
+			stores = append(stores, &storeChange{
+				standard:     TokenStandardFungibleAsset,
+				tokenType:    store.Metadata.Inner,
+				storeAddress: address,
+				balance:      store.Balance,
+			})
+		case resource.Type == objectCoreResource:
+			object := struct {
+				Owner string `json:"owner"`
+			}{}
+			if err := json.Unmarshal(resource.Data, &object); err != nil {
+				return nil, err
+			}
+			owners[address] = normalizeAddress(object.Owner)
+		}
+	}
+	for _, store := range stores {
+		if store.standard == TokenStandardFungibleAsset {
+			store.owner = owners[store.storeAddress]
+		}
+	}
+	return stores, nil
+}
+
+func (c *Chain) fetchBalanceChanges(owner string, changes []aptostypes.Change) ([]*BalanceChange, error) {
+	stores, err := storeChangesOf(changes)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	owner = normalizeAddress(owner)
+	balanceChanges := []*BalanceChange{}
+	for _, store := range stores {
+		if store.owner == "" {
+			object := struct {
+				Owner string `json:"owner"`
+			}{}
+			found, err := fetchResourceData(client, store.storeAddress, objectCoreResource, &object)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				store.owner = normalizeAddress(object.Owner)
+			}
+		}
+		if store.owner != owner {
+			continue
+		}
+		before, err := fetchStoreBalance(client, store)
+		if err != nil {
+			return nil, err
+		}
+		change, err := balanceChangeOf(store, before)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			balanceChanges = append(balanceChanges, change)
+		}
+	}
+	return balanceChanges, nil
+}
+
+// @return the balance of the store before the transaction, it's "0" if the store is created by the transaction.
+func fetchStoreBalance(client *aptosclient.RestClient, store *storeChange) (string, error) {
+	if store.standard == TokenStandardCoin {
+		data := struct {
+			Coin struct {
+				Value string `json:"value"`
+			} `json:"coin"`
+		}{}
+		found, err := fetchResourceData(client, store.storeAddress, coinStorePrefix+store.tokenType+">", &data)
+		if err != nil || !found {
+			return "0", err
+		}
+		return data.Coin.Value, nil
+	}
+	data := struct {
+		Balance string `json:"balance"`
+	}{}
+	found, err := fetchResourceData(client, store.storeAddress, fungibleStoreResource, &data)
+	if err != nil || !found {
+		return "0", err
+	}
+	return data.Balance, nil
+}
+
+// @return nil if the balance is not changed.
+func balanceChangeOf(store *storeChange, before string) (*BalanceChange, error) {
+	beforeInt, ok := big.NewInt(0).SetString(before, 10)
+	if !ok {
+		return nil, errors.New("Invalid balance: " + before)
+	}
+	afterInt, ok := big.NewInt(0).SetString(store.balance, 10)
+	if !ok {
+		return nil, errors.New("Invalid balance: " + store.balance)
+	}
+	delta := big.NewInt(0).Sub(afterInt, beforeInt)
+	if delta.Sign() == 0 {
+		return nil, nil
+	}
+	change := &BalanceChange{
+		Standard:  store.standard,
+		TokenType: store.tokenType,
+		Before:    before,
+		After:     store.balance,
+		Change:    delta.String(),
+	}
+	if store.standard == TokenStandardFungibleAsset {
+		change.StoreAddress = store.storeAddress
+	}
+	return change, nil
+}
+
+// @return false if the resource does not exist.
+func fetchResourceData(client *aptosclient.RestClient, address, resourceType string, out any) (bool, error) {
+	res, err := client.GetAccountResource(address, resourceType, 0)
+	if err != nil {
+		if isNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, remarshal(res.Data, out)
+}
+
+// Remove the leading zeros, the addresses in the write set are not padded.
+func normalizeAddress(address string) string {
+	trimmed := strings.TrimLeft(strings.TrimPrefix(strings.ToLower(address), "0x"), "0")
+	return "0x" + trimmed
+}
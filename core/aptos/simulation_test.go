@@ -0,0 +1,93 @@
+package aptos
+
+import (
+	"testing"
+
+	"github.com/coming-chat/go-aptos/aptostypes"
+	"github.com/coming-chat/wallet-SDK/core/testcase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulationResultOfTransaction(t *testing.T) {
+	txn := &aptostypes.Transaction{
+		Success:      false,
+		VmStatus:     "Move abort in 0x1::coin: EINSUFFICIENT_BALANCE(0x10006): Not enough coins to complete transaction",
+		GasUsed:      12,
+		GasUnitPrice: 100,
+		Events: []aptostypes.Event{
+			{Type: "0x1::coin::WithdrawEvent", Data: map[string]interface{}{"amount": "100"}},
+		},
+	}
+	res, err := simulationResultOfTransaction(txn)
+	require.Nil(t, err)
+	require.Equal(t, "0x1::coin", res.AbortLocation)
+	require.Equal(t, "0x10006", res.AbortCode)
+	require.Equal(t, "EINSUFFICIENT_BALANCE", res.AbortReason)
+	require.Equal(t, "Not enough coins to complete transaction", res.AbortDescription)
+	require.Equal(t, "1200", res.GasFee)
+	require.Equal(t, `{"amount":"100"}`, res.Events[0].Data)
+
+	txn.VmStatus = "Move abort in 0x1::coin: 0x10006"
+	res, err = simulationResultOfTransaction(txn)
+	require.Nil(t, err)
+	require.Equal(t, "0x10006", res.AbortCode)
+	require.Equal(t, "", res.AbortReason)
+}
+
+func TestStoreChangesOf(t *testing.T) {
+	changes := []aptostypes.Change{
+		{
+			Type:    "write_resource",
+			Address: "0x0a1",
+			Data: map[string]interface{}{
+				"type": "0x1::coin::CoinStore<0x1::aptos_coin::AptosCoin>",
+				"data": map[string]interface{}{"coin": map[string]interface{}{"value": "900"}},
+			},
+		},
+		{
+			Type:    "write_resource",
+			Address: "0xb2",
+			Data: map[string]interface{}{
+				"type": "0x1::fungible_asset::FungibleStore",
+				"data": map[string]interface{}{"metadata": map[string]interface{}{"inner": "0xc3"}, "balance": "50", "frozen": false},
+			},
+		},
+		{
+			Type:    "write_resource",
+			Address: "0xb2",
+			Data: map[string]interface{}{
+				"type": "0x1::object::ObjectCore",
+				"data": map[string]interface{}{"owner": "0xa1"},
+			},
+		},
+		{Type: "write_table_item", Handle: "0xd4"},
+	}
+	stores, err := storeChangesOf(changes)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(stores))
+	require.Equal(t, "0x1::aptos_coin::AptosCoin", stores[0].tokenType)
+	require.Equal(t, "0xa1", stores[0].owner)
+	require.Equal(t, "0xc3", stores[1].tokenType)
+	require.Equal(t, "0xa1", stores[1].owner)
+
+	change, err := balanceChangeOf(stores[0], "1000")
+	require.Nil(t, err)
+	require.Equal(t, "-100", change.Change)
+	change, err = balanceChangeOf(stores[1], "0")
+	require.Nil(t, err)
+	require.Equal(t, "50", change.Change)
+	require.Equal(t, "0xb2", change.StoreAddress)
+	change, err = balanceChangeOf(stores[1], "50")
+	require.Nil(t, err)
+	require.Nil(t, change)
+}
+
+func TestSimulateTransaction(t *testing.T) {
+	account, err := NewAccountWithMnemonic(testcase.M1)
+	require.Nil(t, err)
+	chain := NewChainWithRestUrl(testnetRestUrl)
+
+	res, err := chain.SimulateTransaction(account.PublicKeyHex(), payloadDemo())
+	require.Nil(t, err)
+	t.Log(res.JsonString())
+}
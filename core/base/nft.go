@@ -70,7 +70,7 @@ type NFTFetcher interface {
 // Extract the nft's real image url.
 // If the content type of the given url is JSON, it's will return the `image` field specified url.
 func ExtractNFTImageUrl(url string) (u *OptionalString, err error) {
-	url = strings.Replace(url, "ipfs://", "https://ipfs.io/ipfs/", 1)
+	url = IpfsUrlToHttpUrl(url)
 	u = &OptionalString{Value: url}
 	resp, err := httpUtil.Request(http.MethodHead, url, nil, nil) // HEAD request
 	if err != nil {
@@ -104,3 +104,14 @@ func ExtractNFTImageUrl(url string) (u *OptionalString, err error) {
 	}
 	return &OptionalString{Value: jsonValue.Image}, nil
 }
+
+// IpfsUrlToHttpUrl
+// Convert the `ipfs://` url to the ipfs.io gateway url, other urls will be returned directly.
+func IpfsUrlToHttpUrl(url string) string {
+	if !strings.HasPrefix(url, "ipfs://") {
+		return url
+	}
+	path := strings.TrimPrefix(url, "ipfs://")
+	path = strings.TrimPrefix(path, "ipfs/")
+	return "https://ipfs.io/ipfs/" + path
+}
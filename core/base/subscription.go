@@ -0,0 +1,248 @@
+package base
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type SubscriptionDelegate interface {
+	// A new message of the subscription has been received
+	// @param message the json string of the notification result, the format is specified by the subscription.
+	SubscriptionDidReceiveMessage(subscription *Subscription, message string)
+	// The connection is broken or the subscription request failed, it will reconnect if the reconnect count is not exhausted.
+	SubscriptionDidFail(subscription *Subscription, errMessage string)
+	// The subscription has been stopped, by `Stop()`, by the end of the subscription, or the reconnect count is exhausted.
+	SubscriptionDidStop(subscription *Subscription)
+}
+
+// The json-rpc subscription over websocket, e.g. `eth_subscribe`, `author_submitAndWatchExtrinsic`.
+// It should be created by the subscription builders of chains.
+type Subscription struct {
+	// The maximum number of reconnection when the connection is broken. 0 means never reconnect, -1 means infinite. default is 5
+	ReconnectCount int
+	// Time interval between two reconnections (ms). default 3000ms
+	ReconnectDelay int64
+
+	WsUrl string
+
+	// The subscribe method and params
+	method string
+	params []any
+	// The unsubscribe method, the params of unsubscribe is the subscription id by default.
+	unsubscribeMethod string
+
+	// The params of unsubscribe, the subscription id will be used if it's nil.
+	unsubscribeParams []any
+	// Convert the notification result to the message delivered to the delegate, nil means the raw json of result.
+	transform func(result json.RawMessage) (string, error)
+	// Check whether the subscription is finished after the notification, e.g. the transaction has been finalized.
+	isFinished func(result json.RawMessage) bool
+	// The notifications are sent with the request id instead of the subscription id, e.g. tendermint.
+	notifyWithRequestId bool
+
+	mutex   sync.Mutex
+	conn    *websocket.Conn
+	subId   json.RawMessage
+	stopped bool
+}
+
+func NewSubscription(wsUrl, method string, params []any, unsubscribeMethod string) *Subscription {
+	return &Subscription{
+		ReconnectCount: 5,
+		ReconnectDelay: 3000,
+
+		WsUrl:             wsUrl,
+		method:            method,
+		params:            params,
+		unsubscribeMethod: unsubscribeMethod,
+	}
+}
+
+// MARK - subscription options, they are used by the subscription builders of chains.
+
+func (s *Subscription) SetUnsubscribeParams(params []any) *Subscription {
+	s.unsubscribeParams = params
+	return s
+}
+
+func (s *Subscription) SetTransform(transform func(result json.RawMessage) (string, error)) *Subscription {
+	s.transform = transform
+	return s
+}
+
+func (s *Subscription) SetFinished(isFinished func(result json.RawMessage) bool) *Subscription {
+	s.isFinished = isFinished
+	return s
+}
+
+func (s *Subscription) SetNotifyWithRequestId(notifyWithRequestId bool) *Subscription {
+	s.notifyWithRequestId = notifyWithRequestId
+	return s
+}
+
+// Start the subscription in background, the messages will be delivered to the delegate.
+func (s *Subscription) Start(delegate SubscriptionDelegate) {
+	if delegate == nil {
+		println("You execute the method Start() without listening for any callbacks!!! You should better set a delegate.")
+		return
+	}
+	s.mutex.Lock()
+	s.stopped = false
+	s.mutex.Unlock()
+	go s.run(delegate)
+}
+
+// Unsubscribe and close the connection.
+func (s *Subscription) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	if s.conn == nil {
+		return
+	}
+	if s.subId != nil && s.unsubscribeMethod != "" {
+		params := s.unsubscribeParams
+		if params == nil {
+			params = []any{s.subId}
+		}
+		_ = s.conn.WriteJSON(newJsonRpcRequest(s.unsubscribeMethod, params))
+	}
+	_ = s.conn.Close()
+}
+
+func (s *Subscription) IsStopped() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stopped
+}
+
+func (s *Subscription) run(delegate SubscriptionDelegate) {
+	defer delegate.SubscriptionDidStop(s)
+	for attempt := 0; ; attempt++ {
+		finished, subscribed, err := s.subscribe(delegate)
+		if finished || s.IsStopped() {
+			s.Stop()
+			return
+		}
+		if err != nil {
+			delegate.SubscriptionDidFail(s, err.Error())
+		}
+		if subscribed {
+			// The connection had worked, so the reconnection is counted from the beginning.
+			attempt = 0
+		}
+		if s.ReconnectCount >= 0 && attempt >= s.ReconnectCount {
+			s.Stop()
+			return
+		}
+		time.Sleep(time.Duration(s.ReconnectDelay) * time.Millisecond)
+		if s.IsStopped() {
+			return
+		}
+	}
+}
+
+const subscriptionRequestId = 1
+
+type jsonRpcRequest struct {
+	JsonRpc string `json:"jsonrpc"`
+	Id      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+func newJsonRpcRequest(method string, params []any) *jsonRpcRequest {
+	if params == nil {
+		params = []any{}
+	}
+	return &jsonRpcRequest{JsonRpc: "2.0", Id: subscriptionRequestId, Method: method, Params: params}
+}
+
+type jsonRpcMessage struct {
+	Id     *int            `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Params *struct {
+		Subscription json.RawMessage `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+// Connect and subscribe, then read the notifications until the connection is broken or the subscription is finished.
+// @return finished true if the subscription is finished; subscribed true if the subscribe request has succeeded.
+func (s *Subscription) subscribe(delegate SubscriptionDelegate) (finished, subscribed bool, err error) {
+	conn, _, err := websocket.DefaultDialer.Dial(s.WsUrl, nil)
+	if err != nil {
+		return false, false, err
+	}
+	s.mutex.Lock()
+	if s.stopped {
+		s.mutex.Unlock()
+		_ = conn.Close()
+		return true, false, nil
+	}
+	s.conn = conn
+	s.subId = nil
+	err = conn.WriteJSON(newJsonRpcRequest(s.method, s.params))
+	s.mutex.Unlock()
+	if err != nil {
+		_ = conn.Close()
+		return false, false, err
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			// the connection may be closed by `Stop` already, closing it again is harmless.
+			_ = conn.Close()
+			return false, subscribed, err
+		}
+		var message jsonRpcMessage
+		if err = json.Unmarshal(data, &message); err != nil {
+			continue
+		}
+		if message.Error != nil {
+			_ = conn.Close()
+			return false, subscribed, errors.New(message.Error.Message)
+		}
+
+		var result json.RawMessage
+		s.mutex.Lock()
+		switch {
+		case message.Id != nil && *message.Id == subscriptionRequestId && s.subId == nil:
+			// The response of subscribe request
+			s.subId = message.Result
+			subscribed = true
+		case message.Id != nil && *message.Id == subscriptionRequestId && s.notifyWithRequestId:
+			result = message.Result
+		case message.Params != nil && string(message.Params.Subscription) == string(s.subId):
+			result = message.Params.Result
+		}
+		s.mutex.Unlock()
+		if result == nil {
+			continue
+		}
+
+		msg := string(result)
+		if s.transform != nil {
+			if msg, err = s.transform(result); err != nil {
+				delegate.SubscriptionDidFail(s, err.Error())
+				continue
+			}
+		}
+		delegate.SubscriptionDidReceiveMessage(s, msg)
+		if s.isFinished != nil && s.isFinished(result) {
+			return true, subscribed, nil
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package base
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+type subscriptionRecorder struct {
+	messages chan string
+	stopped  chan bool
+}
+
+func (r *subscriptionRecorder) SubscriptionDidReceiveMessage(subscription *Subscription, message string) {
+	r.messages <- message
+}
+func (r *subscriptionRecorder) SubscriptionDidFail(subscription *Subscription, errMessage string) {}
+func (r *subscriptionRecorder) SubscriptionDidStop(subscription *Subscription) {
+	r.stopped <- true
+}
+
+func TestSubscription(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.Nil(t, err)
+		defer conn.Close()
+
+		var request jsonRpcRequest
+		require.Nil(t, conn.ReadJSON(&request))
+		require.Equal(t, "test_subscribe", request.Method)
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"result":"0xabc"}`))
+		for i := 0; i < 3; i++ {
+			notification := `{"jsonrpc":"2.0","method":"test_notify","params":{"subscription":"0xabc","result":` + string(rune('0'+i)) + `}}`
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(notification))
+		}
+		// wait for the unsubscribe request
+		_ = conn.ReadJSON(&request)
+	}))
+	defer server.Close()
+
+	wsUrl := "ws" + strings.TrimPrefix(server.URL, "http")
+	sub := NewSubscription(wsUrl, "test_subscribe", nil, "test_unsubscribe").
+		SetFinished(func(result json.RawMessage) bool { return string(result) == "2" })
+	recorder := &subscriptionRecorder{messages: make(chan string, 10), stopped: make(chan bool, 1)}
+	sub.Start(recorder)
+
+	select {
+	case <-recorder.stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the subscription should be finished")
+	}
+	require.Equal(t, 3, len(recorder.messages))
+	require.Equal(t, "0", <-recorder.messages)
+	require.True(t, sub.IsStopped())
+}
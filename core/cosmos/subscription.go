@@ -0,0 +1,51 @@
+package cosmos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+// Subscribe the events of tendermint `/websocket`, the message is the json of result, which contains `query`, `data` and `events`.
+// @param wsUrl the websocket url of the rpc node, e.g. wss://rpc.cosmos.network/websocket
+// @param query the event query, e.g. `tm.event='Tx' AND message.sender='cosmos1...'`
+func NewEventsSubscription(wsUrl, query string) *base.Subscription {
+	sub := base.NewSubscription(wsUrl, "subscribe", []any{query}, "unsubscribe")
+	return sub.SetUnsubscribeParams([]any{query}).SetNotifyWithRequestId(true)
+}
+
+// Subscribe the result of the transaction, the subscription is finished after the transaction is committed.
+func NewTxSubscription(wsUrl, txHash string) *base.Subscription {
+	hash := strings.ToUpper(strings.TrimPrefix(txHash, "0x"))
+	query := fmt.Sprintf("tm.event='Tx' AND tx.hash='%v'", hash)
+	return NewEventsSubscription(wsUrl, query).SetFinished(isTxEventResult)
+}
+
+func isTxEventResult(result json.RawMessage) bool {
+	var res struct {
+		Data *json.RawMessage `json:"data"`
+	}
+	return json.Unmarshal(result, &res) == nil && res.Data != nil
+}
+
+// Subscribe the result of the transaction with the rpc of the chain.
+func (c *Chain) SubscribeTx(txHash string) *base.Subscription {
+	return NewTxSubscription(websocketUrlOf(c.RpcUrl), txHash)
+}
+
+// The tendermint websocket endpoint of the rpc url, e.g. https://rpc.cosmos.network => wss://rpc.cosmos.network/websocket
+func websocketUrlOf(rpcUrl string) string {
+	url := strings.TrimSuffix(rpcUrl, "/")
+	switch {
+	case strings.HasPrefix(url, "https://"):
+		url = "wss://" + strings.TrimPrefix(url, "https://")
+	case strings.HasPrefix(url, "http://"):
+		url = "ws://" + strings.TrimPrefix(url, "http://")
+	}
+	if !strings.HasSuffix(url, "/websocket") {
+		url += "/websocket"
+	}
+	return url
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
 	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -61,10 +62,37 @@ func (c *Chain) BatchFetchErc20TokenBalance(contractListString, address string)
 // @return 余额数组，顺序与传入的 contractList 是保持一致的
 // @throw 如果任意一个代币请求余额出错时，会抛出错误
 func (c *Chain) BatchErc20TokenBalance(contractList []string, address string) ([]string, error) {
-	return base.MapListConcurrentStringToString(contractList, func(s string) (string, error) {
-		b, err := c.Erc20Token(s).BalanceOfAddress(address)
-		return b.Total, err
-	})
+	if len(address) == 0 {
+		return nil, errors.New("The address of the wallet is empty.")
+	}
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	owner := common.HexToAddress(address)
+	calls := make([]contractMethodCall, len(contractList))
+	for i, contract := range contractList {
+		if len(contract) == 0 {
+			return nil, errors.New("The address of the contract is empty.")
+		}
+		calls[i] = contractMethodCall{contract: contract, method: "balanceOf", params: []interface{}{owner}}
+	}
+	outs, errs, err := chain.multicallContractMethods(Erc20AbiStr, calls)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]string, len(contractList))
+	for i, out := range outs {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		balance, ok := out.(*big.Int)
+		if !ok {
+			return nil, errors.New("Invalid balance of " + contractList[i])
+		}
+		balances[i] = balance.String()
+	}
+	return balances, nil
 }
 
 // call eth_call method
@@ -0,0 +1,510 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	PreviewStandardNative    = "NATIVE"
+	PreviewStandardERC20     = "ERC20"
+	PreviewStandardRedPacket = "RedPacket"
+)
+
+var (
+	topicApproval       = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	topicApprovalForAll = crypto.Keccak256Hash([]byte("ApprovalForAll(address,address,bool)"))
+)
+
+type PreviewParam struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// The decoded calldata of a transaction.
+type PreviewCall struct {
+	// One of ERC20, ERC721, ERC1155, RedPacket
+	Standard string          `json:"standard"`
+	Method   string          `json:"method"`
+	Params   []*PreviewParam `json:"params"`
+}
+
+type TokenBalanceChange struct {
+	Address string `json:"address"`
+	// The contract address, empty if it's the chain's main token
+	Token string `json:"token"`
+	// One of NATIVE, ERC20, ERC721, ERC1155
+	Standard string `json:"standard"`
+	TokenId  string `json:"tokenId,omitempty"`
+	// Positive means income, negative means expense
+	Amount string `json:"amount"`
+}
+
+type TokenApproval struct {
+	Token string `json:"token"`
+	// One of ERC20, ERC721, ERC1155, empty if the standard cannot be determined
+	Standard string `json:"standard"`
+	Owner    string `json:"owner"`
+	Spender  string `json:"spender"`
+	// The approved amount of erc20
+	Amount string `json:"amount,omitempty"`
+	// The approved token id of erc721
+	TokenId string `json:"tokenId,omitempty"`
+	// setApprovalForAll of erc721 & erc1155
+	ApprovedForAll bool `json:"approvedForAll,omitempty"`
+}
+
+type TransactionPreview struct {
+	Success      bool   `json:"success"`
+	RevertReason string `json:"revertReason"`
+	// The hex string that returned by `eth_call`
+	ReturnData string `json:"returnData"`
+
+	// Nil if the transaction is not a known contract call.
+	Call *PreviewCall `json:"call"`
+
+	BalanceChanges []*TokenBalanceChange `json:"balanceChanges"`
+	Approvals      []*TokenApproval      `json:"approvals"`
+
+	// Whether the balance changes and approvals are from `debug_traceCall`,
+	// otherwise they are inferred from the calldata and may be incomplete.
+	Traced bool `json:"traced"`
+}
+
+func (p *TransactionPreview) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(p)
+}
+
+func NewTransactionPreviewWithJsonString(str string) (*TransactionPreview, error) {
+	var o TransactionPreview
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+// 在签名之前模拟执行交易，预览交易的执行结果
+// 交易会在 pending 区块通过 eth_call 执行，如果节点支持 debug_traceCall，会通过交易日志解析代币余额变化和授权
+// @param from 交易的发送者
+func (c *Chain) PreviewTransaction(from string, transaction *Transaction) (preview *TransactionPreview, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if transaction == nil {
+		return nil, errors.New("Invalid transaction")
+	}
+	if !IsValidAddress(from) {
+		return nil, errors.New("Invalid sender address")
+	}
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	callArg, err := previewCallArg(from, transaction)
+	if err != nil {
+		return
+	}
+
+	preview = &TransactionPreview{
+		BalanceChanges: []*TokenBalanceChange{},
+		Approvals:      []*TokenApproval{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), chain.timeout)
+	defer cancel()
+	var output hexutil.Bytes
+	err = chain.RpcClient.CallContext(ctx, &output, "eth_call", callArg, "pending")
+	if err != nil {
+		preview.RevertReason = revertReasonOfError(err)
+		err = nil
+	} else {
+		preview.Success = true
+		preview.ReturnData = output.String()
+	}
+
+	fromAddress := common.HexToAddress(from)
+	var data []byte
+	if transaction.Data != "" {
+		data, err = hexutil.Decode(ensureHexPrefix(transaction.Data))
+		if err != nil {
+			return nil, errors.New("Invalid data string")
+		}
+	}
+	if transaction.To != "" && len(data) > 4 {
+		to := transaction.To
+		preview.Call = decodePreviewCall(data, func() bool {
+			_, err := chain.TokenDecimal(to)
+			return err == nil
+		})
+	}
+
+	var frame callFrame
+	err = chain.RpcClient.CallContext(ctx, &frame, "debug_traceCall", callArg, "pending", map[string]interface{}{
+		"tracer":       "callTracer",
+		"tracerConfig": map[string]interface{}{"withLog": true},
+	})
+	if err == nil {
+		preview.Traced = true
+		if frame.Error != "" {
+			preview.Success = false
+			if preview.RevertReason == "" {
+				preview.RevertReason = frame.revertReason()
+			}
+		}
+		if preview.Success {
+			changes := newBalanceChanges()
+			frame.collect(changes, &preview.Approvals)
+			preview.BalanceChanges = changes.list()
+		}
+		return preview, nil
+	}
+	err = nil
+
+	// The node does not support debug_traceCall, infer from the calldata.
+	if preview.Success {
+		changes := newBalanceChanges()
+		value, _ := big.NewInt(0).SetString(transaction.Value, 10)
+		if value != nil && value.Sign() > 0 && transaction.To != "" {
+			changes.add(fromAddress, "", PreviewStandardNative, "", new(big.Int).Neg(value))
+			changes.add(common.HexToAddress(transaction.To), "", PreviewStandardNative, "", value)
+		}
+		if preview.Call != nil {
+			preview.Call.infer(fromAddress, common.HexToAddress(transaction.To), changes, &preview.Approvals)
+		}
+		preview.BalanceChanges = changes.list()
+	}
+	return preview, nil
+}
+
+func ensureHexPrefix(str string) string {
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		return str
+	}
+	return "0x" + str
+}
+
+func previewCallArg(from string, transaction *Transaction) (map[string]interface{}, error) {
+	rawTx, err := transaction.GetRawTx()
+	if err != nil {
+		return nil, err
+	}
+	arg := map[string]interface{}{
+		"from": common.HexToAddress(from),
+	}
+	if transaction.To != "" {
+		arg["to"] = rawTx.To()
+	}
+	if len(rawTx.Data()) > 0 {
+		arg["data"] = hexutil.Bytes(rawTx.Data())
+	}
+	if rawTx.Value() != nil {
+		arg["value"] = (*hexutil.Big)(rawTx.Value())
+	}
+	if transaction.GasLimit != "" {
+		arg["gas"] = hexutil.Uint64(rawTx.Gas())
+	}
+	return arg, nil
+}
+
+// Parse the revert reason from the error of `eth_call`
+func revertReasonOfError(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if dataStr, ok := dataErr.ErrorData().(string); ok {
+			data, decodeErr := hexutil.Decode(dataStr)
+			if decodeErr == nil {
+				if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+					return reason
+				}
+			}
+		}
+	}
+	return err.Error()
+}
+
+// Decode the calldata with the known abis: erc20, erc721, erc1155 and red packet
+// @param isErc20 erc20 and erc721 have the same `approve` and `transferFrom` method, it's used to distinguish them.
+func decodePreviewCall(data []byte, isErc20 func() bool) *PreviewCall {
+	type knownAbi struct {
+		standard string
+		abiStr   string
+	}
+	knownAbis := []knownAbi{
+		{PreviewStandardERC20, Erc20AbiStr},
+		{NFTStandardERC721, Erc721AbiStr},
+		{NFTStandardERC1155, Erc1155AbiStr},
+		{PreviewStandardRedPacket, RedPacketABI},
+	}
+	for _, known := range knownAbis {
+		method, params, err := DecodeContractParams(known.abiStr, data)
+		if err != nil || method == "" {
+			continue
+		}
+		if known.standard == PreviewStandardERC20 && (method == ERC20_METHOD_APPROVE || method == "transferFrom") {
+			if isErc20 != nil && !isErc20() {
+				continue
+			}
+		}
+		parsedAbi, err := abi.JSON(strings.NewReader(known.abiStr))
+		if err != nil {
+			continue
+		}
+		abiMethod, err := parsedAbi.MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+		call := &PreviewCall{
+			Standard: known.standard,
+			Method:   method,
+			Params:   make([]*PreviewParam, len(params)),
+		}
+		for i, param := range params {
+			call.Params[i] = &PreviewParam{
+				Name:  abiMethod.Inputs[i].Name,
+				Type:  abiMethod.Inputs[i].Type.String(),
+				Value: formatPreviewValue(param),
+			}
+		}
+		return call
+	}
+	return nil
+}
+
+func formatPreviewValue(value interface{}) string {
+	switch v := value.(type) {
+	case common.Address:
+		return v.String()
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return hexutil.Encode(v)
+	case string:
+		return v
+	case bool:
+		return fmt.Sprint(v)
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items := make([]string, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			items[i] = formatPreviewValue(rv.Index(i).Interface())
+		}
+		bytes, _ := json.Marshal(items)
+		return string(bytes)
+	}
+	return fmt.Sprint(value)
+}
+
+func (c *PreviewCall) param(name string) string {
+	for _, p := range c.Params {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// infer the balance changes and approvals of the known methods.
+func (c *PreviewCall) infer(from, contract common.Address, changes *balanceChanges, approvals *[]*TokenApproval) {
+	token := contract.String()
+	bigOf := func(name string) *big.Int {
+		i, ok := big.NewInt(0).SetString(c.param(name), 10)
+		if !ok {
+			return big.NewInt(0)
+		}
+		return i
+	}
+	transfer := func(standard string, sender, receiver common.Address, tokenId string, amount *big.Int) {
+		changes.add(sender, token, standard, tokenId, new(big.Int).Neg(amount))
+		changes.add(receiver, token, standard, tokenId, amount)
+	}
+
+	switch c.Standard {
+	case PreviewStandardERC20:
+		switch c.Method {
+		case ERC20_METHOD_TRANSFER:
+			transfer(c.Standard, from, common.HexToAddress(c.param("recipient")), "", bigOf("amount"))
+		case "transferFrom":
+			transfer(c.Standard, common.HexToAddress(c.param("sender")), common.HexToAddress(c.param("recipient")), "", bigOf("amount"))
+		case ERC20_METHOD_APPROVE:
+			*approvals = append(*approvals, &TokenApproval{Token: token, Standard: c.Standard, Owner: from.String(), Spender: c.param("spender"), Amount: c.param("amount")})
+		}
+	case NFTStandardERC721:
+		switch c.Method {
+		case "transferFrom", "safeTransferFrom":
+			transfer(c.Standard, common.HexToAddress(c.param("from")), common.HexToAddress(c.param("to")), c.param("tokenId"), big.NewInt(1))
+		case ERC20_METHOD_APPROVE:
+			*approvals = append(*approvals, &TokenApproval{Token: token, Standard: c.Standard, Owner: from.String(), Spender: c.param("to"), TokenId: c.param("tokenId")})
+		case "setApprovalForAll":
+			*approvals = append(*approvals, &TokenApproval{Token: token, Standard: c.Standard, Owner: from.String(), Spender: c.param("operator"), ApprovedForAll: c.param("approved") == "true"})
+		}
+	case NFTStandardERC1155:
+		switch c.Method {
+		case "safeTransferFrom":
+			transfer(c.Standard, common.HexToAddress(c.param("from")), common.HexToAddress(c.param("to")), c.param("id"), bigOf("amount"))
+		case "safeBatchTransferFrom":
+			var ids, amounts []string
+			_ = json.Unmarshal([]byte(c.param("ids")), &ids)
+			_ = json.Unmarshal([]byte(c.param("amounts")), &amounts)
+			for i := 0; i < len(ids) && i < len(amounts); i++ {
+				amount, ok := big.NewInt(0).SetString(amounts[i], 10)
+				if ok {
+					transfer(c.Standard, common.HexToAddress(c.param("from")), common.HexToAddress(c.param("to")), ids[i], amount)
+				}
+			}
+		case "setApprovalForAll":
+			*approvals = append(*approvals, &TokenApproval{Token: token, Standard: c.Standard, Owner: from.String(), Spender: c.param("operator"), ApprovedForAll: c.param("approved") == "true"})
+		}
+	}
+}
+
+// The result of `debug_traceCall` with `callTracer`
+type callFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           common.Address  `json:"to"`
+	Value        *hexutil.Big    `json:"value"`
+	Error        string          `json:"error"`
+	RevertReason string          `json:"revertReason"`
+	Output       hexutil.Bytes   `json:"output"`
+	Calls        []*callFrame    `json:"calls"`
+	Logs         []*callFrameLog `json:"logs"`
+}
+
+type callFrameLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+func (f *callFrame) revertReason() string {
+	if f.RevertReason != "" {
+		return f.RevertReason
+	}
+	if reason, err := abi.UnpackRevert(f.Output); err == nil {
+		return reason
+	}
+	return f.Error
+}
+
+func (f *callFrame) collect(changes *balanceChanges, approvals *[]*TokenApproval) {
+	if f.Error != "" {
+		// The state changes of the failed call frame will be reverted.
+		return
+	}
+	if f.Value != nil && f.Type != "DELEGATECALL" && f.Type != "STATICCALL" {
+		value := f.Value.ToInt()
+		if value.Sign() > 0 {
+			changes.add(f.From, "", PreviewStandardNative, "", new(big.Int).Neg(value))
+			changes.add(f.To, "", PreviewStandardNative, "", value)
+		}
+	}
+	for _, log := range f.Logs {
+		log.collect(changes, approvals)
+	}
+	for _, call := range f.Calls {
+		call.collect(changes, approvals)
+	}
+}
+
+func (l *callFrameLog) collect(changes *balanceChanges, approvals *[]*TokenApproval) {
+	if len(l.Topics) == 0 {
+		return
+	}
+	token := l.Address.String()
+	addressOf := func(topic common.Hash) common.Address {
+		return common.BytesToAddress(topic.Bytes())
+	}
+	switch {
+	case l.Topics[0] == topicTransfer && len(l.Topics) == 3:
+		amount := new(big.Int).SetBytes(l.Data)
+		changes.add(addressOf(l.Topics[1]), token, PreviewStandardERC20, "", new(big.Int).Neg(amount))
+		changes.add(addressOf(l.Topics[2]), token, PreviewStandardERC20, "", amount)
+	case l.Topics[0] == topicTransfer && len(l.Topics) == 4:
+		tokenId := l.Topics[3].Big().String()
+		changes.add(addressOf(l.Topics[1]), token, NFTStandardERC721, tokenId, big.NewInt(-1))
+		changes.add(addressOf(l.Topics[2]), token, NFTStandardERC721, tokenId, big.NewInt(1))
+	case (l.Topics[0] == topicErc1155TransferSingle || l.Topics[0] == topicErc1155TransferBatch) && len(l.Topics) == 4:
+		ids, amounts, err := unpackErc1155TransferData(l.Topics[0], l.Data)
+		if err != nil || len(ids) != len(amounts) {
+			return
+		}
+		for i, id := range ids {
+			changes.add(addressOf(l.Topics[2]), token, NFTStandardERC1155, id.String(), new(big.Int).Neg(amounts[i]))
+			changes.add(addressOf(l.Topics[3]), token, NFTStandardERC1155, id.String(), amounts[i])
+		}
+	case l.Topics[0] == topicApproval && len(l.Topics) == 3:
+		*approvals = append(*approvals, &TokenApproval{
+			Token: token, Standard: PreviewStandardERC20,
+			Owner: addressOf(l.Topics[1]).String(), Spender: addressOf(l.Topics[2]).String(),
+			Amount: new(big.Int).SetBytes(l.Data).String(),
+		})
+	case l.Topics[0] == topicApproval && len(l.Topics) == 4:
+		*approvals = append(*approvals, &TokenApproval{
+			Token: token, Standard: NFTStandardERC721,
+			Owner: addressOf(l.Topics[1]).String(), Spender: addressOf(l.Topics[2]).String(),
+			TokenId: l.Topics[3].Big().String(),
+		})
+	case l.Topics[0] == topicApprovalForAll && len(l.Topics) == 3:
+		// The event is shared by erc721 and erc1155, so the standard is unknown.
+		*approvals = append(*approvals, &TokenApproval{
+			Token: token,
+			Owner: addressOf(l.Topics[1]).String(), Spender: addressOf(l.Topics[2]).String(),
+			ApprovedForAll: new(big.Int).SetBytes(l.Data).Sign() != 0,
+		})
+	}
+}
+
+// Aggregate the balance changes by (address, token, tokenId)
+type balanceChanges struct {
+	keys    []string
+	changes map[string]*TokenBalanceChange
+	amounts map[string]*big.Int
+}
+
+func newBalanceChanges() *balanceChanges {
+	return &balanceChanges{
+		changes: make(map[string]*TokenBalanceChange),
+		amounts: make(map[string]*big.Int),
+	}
+}
+
+func (b *balanceChanges) add(address common.Address, token, standard, tokenId string, amount *big.Int) {
+	key := strings.ToLower(address.String() + token + "-" + tokenId)
+	if _, exists := b.changes[key]; !exists {
+		b.keys = append(b.keys, key)
+		b.changes[key] = &TokenBalanceChange{
+			Address:  address.String(),
+			Token:    token,
+			Standard: standard,
+			TokenId:  tokenId,
+		}
+		b.amounts[key] = big.NewInt(0)
+	}
+	b.amounts[key].Add(b.amounts[key], amount)
+}
+
+// @return The non-zero changes in the order they were added.
+func (b *balanceChanges) list() []*TokenBalanceChange {
+	list := []*TokenBalanceChange{}
+	for _, key := range b.keys {
+		amount := b.amounts[key]
+		if amount.Sign() == 0 {
+			continue
+		}
+		change := b.changes[key]
+		change.Amount = amount.String()
+		list = append(list, change)
+	}
+	return list
+}
@@ -0,0 +1,101 @@
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePreviewCall(t *testing.T) {
+	receiver := "0x6334d64D5167F726d8A44f3fbCA66613708E59E7"
+	data, err := EncodeErc20Transfer(receiver, "1000")
+	require.Nil(t, err)
+	call := decodePreviewCall(data, nil)
+	require.NotNil(t, call)
+	require.Equal(t, PreviewStandardERC20, call.Standard)
+	require.Equal(t, ERC20_METHOD_TRANSFER, call.Method)
+	require.Equal(t, receiver, call.param("recipient"))
+	require.Equal(t, "1000", call.param("amount"))
+
+	// erc721 approve has the same selector as erc20
+	data, err = EncodeContractData(Erc721AbiStr, "approve", common.HexToAddress(receiver), big.NewInt(12))
+	require.Nil(t, err)
+	call = decodePreviewCall(data, func() bool { return false })
+	require.NotNil(t, call)
+	require.Equal(t, NFTStandardERC721, call.Standard)
+	require.Equal(t, "12", call.param("tokenId"))
+
+	from := common.HexToAddress("0x8c951f58F63C0018BFBb47A29e55e84507eD63Bd")
+	contract := common.HexToAddress("0x0000000000000000000000000000000000000721")
+	changes := newBalanceChanges()
+	approvals := []*TokenApproval{}
+	call.infer(from, contract, changes, &approvals)
+	require.Equal(t, 0, len(changes.list()))
+	require.Equal(t, 1, len(approvals))
+	require.Equal(t, receiver, approvals[0].Spender)
+
+	call = decodePreviewCall([]byte{1, 2, 3, 4, 5}, nil)
+	require.Nil(t, call)
+}
+
+func TestCallFrame_collect(t *testing.T) {
+	// a swap: send 1 ETH to the router, receive 100 erc20 token
+	traceJson := `{
+		"type": "CALL",
+		"from": "0x8c951f58f63c0018bfbb47a29e55e84507ed63bd",
+		"to": "0x7a250d5630b4cf539739df2c5dacb4c659f2488d",
+		"value": "0xde0b6b3a7640000",
+		"calls": [{
+			"type": "CALL",
+			"from": "0x7a250d5630b4cf539739df2c5dacb4c659f2488d",
+			"to": "0xdac17f958d2ee523a2206206994597c13d831ec7",
+			"value": "0x0",
+			"logs": [{
+				"address": "0xdac17f958d2ee523a2206206994597c13d831ec7",
+				"topics": [
+					"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+					"0x0000000000000000000000007a250d5630b4cf539739df2c5dacb4c659f2488d",
+					"0x0000000000000000000000008c951f58f63c0018bfbb47a29e55e84507ed63bd"
+				],
+				"data": "0x0000000000000000000000000000000000000000000000000000000000000064"
+			}]
+		}, {
+			"type": "CALL",
+			"from": "0x7a250d5630b4cf539739df2c5dacb4c659f2488d",
+			"to": "0x0000000000000000000000000000000000000001",
+			"value": "0x1",
+			"error": "execution reverted"
+		}]
+	}`
+	var frame callFrame
+	err := json.Unmarshal([]byte(traceJson), &frame)
+	require.Nil(t, err)
+
+	changes := newBalanceChanges()
+	approvals := []*TokenApproval{}
+	frame.collect(changes, &approvals)
+	list := changes.list()
+	require.Equal(t, 4, len(list))
+	require.Equal(t, "-1000000000000000000", list[0].Amount)
+	require.Equal(t, PreviewStandardNative, list[0].Standard)
+	require.Equal(t, "0xdAC17F958D2ee523a2206206994597C13D831ec7", list[3].Token)
+	require.Equal(t, "0x8c951f58F63C0018BFBb47A29e55e84507eD63Bd", list[3].Address)
+	require.Equal(t, "100", list[3].Amount)
+}
+
+func TestChain_PreviewTransaction(t *testing.T) {
+	chain := rpcs.ethereumProd.Chain()
+	from := "0x5754284f345afc66a98fbb0a0afe71e0f007b949"
+	data, err := EncodeErc20Transfer("0x8c951f58F63C0018BFBb47A29e55e84507eD63Bd", "1000000")
+	require.Nil(t, err)
+	tx := NewTransaction("", "", "", rpcs.ethereumProd.contracts.USDT, "0", common.Bytes2Hex(data))
+
+	preview, err := chain.PreviewTransaction(from, tx)
+	require.Nil(t, err)
+	jsonString, err := preview.JsonString()
+	require.Nil(t, err)
+	t.Log(jsonString.Value)
+}
@@ -0,0 +1,139 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// The minimum price bump percentage that the node requires to replace a pending transaction.
+// It's geth's default `txpool.pricebump`.
+const DEFAULT_REPLACEMENT_PRICE_BUMP = 10
+
+// 加速一笔 pending 状态的交易
+// 使用相同的 nonce 重新签名原交易，并提高手续费，手续费至少会提高节点要求的替换比例 (10%)
+// @param hash 需要加速的交易 hash
+// @param gasPrice 新的 gasPrice，EIP1559 交易表示新的 maxFee; 如果为空或者低于替换要求，会使用满足替换要求的最低价格
+// @return 签名后的交易, 可以通过 SendRawTransaction 发送
+func (c *Chain) SpeedUpTransaction(account *Account, hash, gasPrice string) (*base.OptionalString, error) {
+	var price *big.Int
+	if gasPrice != "" {
+		var ok bool
+		if price, ok = big.NewInt(0).SetString(gasPrice, 10); !ok {
+			return nil, errors.New("Invalid gasPrice")
+		}
+	}
+	return c.replaceTransaction(account, hash, false, price)
+}
+
+// 取消一笔 pending 状态的交易
+// 使用相同的 nonce 发送一笔 0 金额的转账给自己，并提高手续费，手续费至少会提高节点要求的替换比例 (10%)
+// @param hash 需要取消的交易 hash
+// @return 签名后的交易, 可以通过 SendRawTransaction 发送
+func (c *Chain) CancelTransaction(account *Account, hash string) (*base.OptionalString, error) {
+	return c.replaceTransaction(account, hash, true, nil)
+}
+
+func (c *Chain) replaceTransaction(account *Account, hash string, cancel bool, gasPrice *big.Int) (s *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if account == nil {
+		return nil, errors.New("Invalid account")
+	}
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	original, err := chain.TransactionByHash(hash)
+	if err != nil {
+		return
+	}
+	if !original.IsPending {
+		return nil, errors.New("The transaction is no longer pending and cannot be replaced")
+	}
+	if !strings.EqualFold(original.From.String(), account.Address()) {
+		return nil, errors.New("The transaction is not sent by the account")
+	}
+
+	// The current network price is used as the lower limit, so that the new transaction can be packaged in time.
+	var suggestPrice, suggestTip *big.Int
+	if original.SignedTx.Type() == ethTypes.DynamicFeeTxType {
+		if gas, err := c.SuggestGasPriceEIP1559(); err == nil {
+			suggestPrice, _ = big.NewInt(0).SetString(gas.MaxFee, 10)
+			suggestTip, _ = big.NewInt(0).SetString(gas.MaxPriorityFee, 10)
+		}
+	} else {
+		if price, err := chain.SuggestGasPrice(); err == nil {
+			suggestPrice, _ = big.NewInt(0).SetString(price, 10)
+		}
+	}
+	if gasPrice != nil {
+		suggestPrice = gasPrice
+	}
+
+	transaction := newReplacementTransaction(original.SignedTx, account.Address(), cancel, suggestPrice, suggestTip)
+	rawTx, err := transaction.GetRawTx()
+	if err != nil {
+		return
+	}
+	txResult, err := chain.buildTxWithTransaction(rawTx, account.privateKeyECDSA)
+	if err != nil {
+		return
+	}
+	return &base.OptionalString{Value: txResult.TxHex}, nil
+}
+
+// Create a transaction with the same nonce to replace the original one.
+// @param minPrice the new gas price (max fee of eip1559) should not be lower than it, nil means no limit.
+// @param minTip the new max priority fee of eip1559 should not be lower than it, nil means no limit.
+func newReplacementTransaction(original *ethTypes.Transaction, sender string, cancel bool, minPrice, minTip *big.Int) *Transaction {
+	tx := &Transaction{
+		Nonce:    strconv.FormatUint(original.Nonce(), 10),
+		GasLimit: strconv.FormatUint(original.Gas(), 10),
+		Value:    original.Value().String(),
+		Data:     types.HexEncodeToString(original.Data()),
+	}
+	if original.To() != nil {
+		tx.To = original.To().String()
+	} else {
+		tx.contractCreation = true
+	}
+	if cancel {
+		tx.To = sender
+		tx.Value = "0"
+		tx.Data = ""
+		tx.GasLimit = DEFAULT_ETH_GAS_LIMIT
+		tx.contractCreation = false
+	}
+
+	gasPrice := bumpReplacementFee(original.GasFeeCap())
+	if minPrice != nil {
+		gasPrice = base.MaxBigInt(gasPrice, minPrice)
+	}
+	if original.Type() == ethTypes.DynamicFeeTxType {
+		tip := bumpReplacementFee(original.GasTipCap())
+		if minTip != nil {
+			tip = base.MaxBigInt(tip, minTip)
+		}
+		// max fee must not be lower than the priority fee
+		gasPrice = base.MaxBigInt(gasPrice, tip)
+		tx.MaxPriorityFeePerGas = tip.String()
+	}
+	tx.GasPrice = gasPrice.String()
+	return tx
+}
+
+// @return fee * (100 + DEFAULT_REPLACEMENT_PRICE_BUMP) / 100, rounded up
+func bumpReplacementFee(fee *big.Int) *big.Int {
+	if fee == nil || fee.Sign() == 0 {
+		return big.NewInt(1)
+	}
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+DEFAULT_REPLACEMENT_PRICE_BUMP))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
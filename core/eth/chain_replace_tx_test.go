@@ -0,0 +1,109 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReplacementTransaction(t *testing.T) {
+	sender := "0x8c951f58F63C0018BFBb47A29e55e84507eD63Bd"
+	to := common.HexToAddress("0x6334d64D5167F726d8A44f3fbCA66613708E59E7")
+
+	legacy := types.NewTx(&types.LegacyTx{
+		Nonce:    5,
+		To:       &to,
+		Value:    big.NewInt(1000),
+		Gas:      60000,
+		GasPrice: big.NewInt(1001),
+		Data:     []byte{1, 2, 3},
+	})
+	tx := newReplacementTransaction(legacy, sender, false, nil, nil)
+	require.Equal(t, "5", tx.Nonce)
+	require.Equal(t, "1102", tx.GasPrice) // 1001 * 1.1 = 1101.1, rounded up
+	require.Equal(t, "60000", tx.GasLimit)
+	require.Equal(t, to.String(), tx.To)
+	require.Equal(t, "1000", tx.Value)
+	require.Equal(t, "0x010203", tx.Data)
+	require.Equal(t, "", tx.MaxPriorityFeePerGas)
+
+	tx = newReplacementTransaction(legacy, sender, false, big.NewInt(2000), nil)
+	require.Equal(t, "2000", tx.GasPrice)
+
+	dynamic := types.NewTx(&types.DynamicFeeTx{
+		Nonce:     7,
+		To:        &to,
+		Value:     big.NewInt(1000),
+		Gas:       60000,
+		GasFeeCap: big.NewInt(3000),
+		GasTipCap: big.NewInt(100),
+	})
+	tx = newReplacementTransaction(dynamic, sender, true, big.NewInt(1000), big.NewInt(200))
+	require.Equal(t, "7", tx.Nonce)
+	require.Equal(t, "3300", tx.GasPrice)
+	require.Equal(t, "200", tx.MaxPriorityFeePerGas)
+	require.Equal(t, sender, tx.To)
+	require.Equal(t, "0", tx.Value)
+	require.Equal(t, "", tx.Data)
+	require.Equal(t, DEFAULT_ETH_GAS_LIMIT, tx.GasLimit)
+}
+
+func TestNewReplacementTransaction_ContractCreation(t *testing.T) {
+	sender := "0x8c951f58F63C0018BFBb47A29e55e84507eD63Bd"
+	creation := types.NewTx(&types.LegacyTx{
+		Nonce:    3,
+		Value:    big.NewInt(1000),
+		Gas:      500000,
+		GasPrice: big.NewInt(1001),
+		Data:     []byte{0x60, 0x80, 0x60, 0x40},
+	})
+	tx := newReplacementTransaction(creation, sender, false, nil, nil)
+	require.Equal(t, "", tx.To)
+	rawTx, err := tx.GetRawTx()
+	require.Nil(t, err)
+	require.Nil(t, rawTx.To()) // still a contract creation
+	require.Equal(t, creation.Data(), rawTx.Data())
+	require.Equal(t, creation.Value(), rawTx.Value())
+}
+
+func TestCancelTransaction(t *testing.T) {
+	sender := "0x8c951f58F63C0018BFBb47A29e55e84507eD63Bd"
+	to := common.HexToAddress("0x6334d64D5167F726d8A44f3fbCA66613708E59E7")
+	pendings := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{
+			Nonce:    9,
+			To:       &to,
+			Value:    big.NewInt(1000),
+			Gas:      60000,
+			GasPrice: big.NewInt(2000),
+			Data:     []byte{1, 2, 3},
+		}),
+		// contract creation
+		types.NewTx(&types.DynamicFeeTx{
+			Nonce:     9,
+			Value:     big.NewInt(1000),
+			Gas:       500000,
+			GasFeeCap: big.NewInt(2000),
+			GasTipCap: big.NewInt(100),
+			Data:      []byte{0x60, 0x80, 0x60, 0x40},
+		}),
+	}
+	for _, pending := range pendings {
+		tx := newReplacementTransaction(pending, sender, true, nil, nil)
+		rawTx, err := tx.GetRawTx()
+		require.Nil(t, err)
+		require.Equal(t, pending.Type(), rawTx.Type())
+		require.Equal(t, uint64(9), rawTx.Nonce())
+		require.Equal(t, "2200", rawTx.GasFeeCap().String())
+		require.NotNil(t, rawTx.To())
+		require.Equal(t, sender, rawTx.To().String())
+		require.Equal(t, "0", rawTx.Value().String())
+		require.Equal(t, 0, len(rawTx.Data()))
+		if pending.Type() == types.DynamicFeeTxType {
+			require.Equal(t, "110", rawTx.GasTipCap().String())
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package eth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type Erc20TokenDiscovery struct {
+	// The erc20 tokens discovered in this scan, contains the balance of the address.
+	// Contracts that do not implement erc20 correctly will be ignored.
+	Tokens []*Erc20TokenInfo
+	// The next block to be scanned, you can save it and continue scanning from it next time.
+	NextBlock int64
+}
+
+func (d *Erc20TokenDiscovery) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(d)
+}
+
+// @return The discovered contract addresses string, separated by ",". e.g. "add1,add2,add3"
+func (d *Erc20TokenDiscovery) ContractListString() string {
+	contracts := make([]string, len(d.Tokens))
+	for i, token := range d.Tokens {
+		contracts[i] = token.ContractAddress
+	}
+	return strings.Join(contracts, ",")
+}
+
+// 发现地址 转入或转出过 的所有 erc20 代币
+// 通过 eth_getLogs 分段扫描 Transfer 事件，并通过 Erc20TokenInfo 查询代币的名称、符号、精度和余额
+// @param address 用户的钱包地址
+// @param fromBlock 开始扫描的区块，可以传入上一次扫描结果的 NextBlock 以继续扫描
+// 查询代币信息时的网络错误会直接返回，此时不会产生新的 NextBlock, 避免代币在继续扫描时被遗漏
+// @param blockRange 每次 eth_getLogs 请求的区块数量，0 表示使用默认值
+func (c *Chain) DiscoverErc20Tokens(address string, fromBlock, blockRange int64) (discovery *Erc20TokenDiscovery, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if !IsValidAddress(address) {
+		return nil, errors.New("Invalid hex address")
+	}
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	latest, err := chain.LatestBlockNumber()
+	if err != nil {
+		return
+	}
+	discovery = &Erc20TokenDiscovery{
+		Tokens:    []*Erc20TokenInfo{},
+		NextBlock: fromBlock,
+	}
+	if fromBlock > latest {
+		return discovery, nil
+	}
+
+	addressTopic := common.BytesToHash(common.HexToAddress(address).Bytes())
+	queries := []ethereum.FilterQuery{
+		{Topics: [][]common.Hash{{topicTransfer}, {addressTopic}}},
+		{Topics: [][]common.Hash{{topicTransfer}, nil, {addressTopic}}},
+	}
+	contracts := []interface{}{}
+	found := make(map[common.Address]bool)
+	err = chain.FilterLogsInChunks(queries, fromBlock, latest, blockRange, func(logs []types.Log, chunkEnd int64) error {
+		for _, contract := range erc20ContractsOfLogs(logs) {
+			if !found[contract] {
+				found[contract] = true
+				contracts = append(contracts, contract.String())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	infos, err := base.MapListConcurrent(contracts, 10, func(contract interface{}) (interface{}, error) {
+		info, err := chain.Erc20TokenInfo(contract.(string), address)
+		var invalidErr *invalidErc20Error
+		if errors.As(err, &invalidErr) {
+			return nil, nil // not a standard erc20 token, ignore it
+		}
+		return info, err
+	})
+	if err != nil {
+		return
+	}
+	for _, info := range infos {
+		if info, ok := info.(*Erc20TokenInfo); ok {
+			discovery.Tokens = append(discovery.Tokens, info)
+		}
+	}
+	discovery.NextBlock = latest + 1
+	return discovery, nil
+}
+
+// The erc721 Transfer event has the same topic, but its tokenId is indexed, so we only need logs with 3 topics.
+func erc20ContractsOfLogs(logs []types.Log) []common.Address {
+	contracts := []common.Address{}
+	for _, log := range logs {
+		if len(log.Topics) == 3 && log.Topics[0] == topicTransfer {
+			contracts = append(contracts, log.Address)
+		}
+	}
+	return contracts
+}
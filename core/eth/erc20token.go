@@ -61,20 +61,30 @@ func (t *Erc20Token) TokenInfo() (*base.TokenInfo, error) {
 		return nil, err
 	}
 
-	info := &base.TokenInfo{}
-	info.Name, err = chain.TokenName(t.ContractAddress)
+	outs, errs, err := chain.multicallContractMethods(Erc20AbiStr, []contractMethodCall{
+		{contract: t.ContractAddress, method: "name"},
+		{contract: t.ContractAddress, method: "symbol"},
+		{contract: t.ContractAddress, method: "decimals"},
+	})
 	if err != nil {
 		return nil, err
 	}
-	info.Symbol, err = chain.TokenSymbol(t.ContractAddress)
-	if err != nil {
-		return nil, err
-	}
-	info.Decimal, err = chain.TokenDecimal(t.ContractAddress)
-	if err != nil {
-		return nil, err
-	}
-	return info, nil
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	name, ok1 := outs[0].(string)
+	symbol, ok2 := outs[1].(string)
+	decimal, ok3 := outs[2].(uint8)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, errors.New("Invalid erc20 token info")
+	}
+	return &base.TokenInfo{
+		Name:    name,
+		Symbol:  symbol,
+		Decimal: int16(decimal),
+	}, nil
 }
 
 func (t *Erc20Token) Decimal() (int16, error) {
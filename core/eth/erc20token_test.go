@@ -1,10 +1,19 @@
 package eth
 
 import (
+	"encoding/json"
+	"errors"
+	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBatch(t *testing.T) {
@@ -243,3 +252,92 @@ func TestErc20Token_EstimateGasLimit(t1 *testing.T) {
 		})
 	}
 }
+
+func TestErc20ContractsOfLogs(t *testing.T) {
+	contract := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	nftContract := common.HexToAddress("0x0000000000000000000000000000000000000721")
+	from := common.HexToHash("0x01")
+	to := common.HexToHash("0x02")
+	logs := []types.Log{
+		{Address: contract, Topics: []common.Hash{topicTransfer, from, to}},
+		{Address: nftContract, Topics: []common.Hash{topicTransfer, from, to, common.HexToHash("0x03")}},
+	}
+	contracts := erc20ContractsOfLogs(logs)
+	require.Equal(t, []common.Address{contract}, contracts)
+}
+
+func TestChain_DiscoverErc20Tokens(t *testing.T) {
+	address := "0xed24fc36d5ee211ea25a80239fb8c4cfd80f12ee"
+	token := common.HexToAddress("0x0000000000000000000000000000000000000020")
+	notToken := common.HexToAddress("0x0000000000000000000000000000000000000021")
+	erc20Abi, err := abi.JSON(strings.NewReader(Erc20AbiStr))
+	require.Nil(t, err)
+	multicallAbi, err := multicall3Abi()
+	require.Nil(t, err)
+
+	var callErr error
+	url := newMockRpcServer(t, func(method string, params []json.RawMessage) (interface{}, error) {
+		switch method {
+		case "eth_blockNumber":
+			return "0x64", nil
+		case "eth_getLogs":
+			topics := []common.Hash{topicTransfer, addressTopic(address), common.HexToHash("0x02")}
+			return []types.Log{
+				{Address: token, Topics: topics, BlockNumber: 10, Index: 0},
+				{Address: notToken, Topics: topics, BlockNumber: 10, Index: 1},
+			}, nil
+		case "eth_call":
+			if callErr != nil {
+				return nil, callErr
+			}
+			var msg struct {
+				Data  hexutil.Bytes `json:"data"`
+				Input hexutil.Bytes `json:"input"`
+			}
+			require.Nil(t, json.Unmarshal(params[0], &msg))
+			input := append(msg.Data, msg.Input...)
+			values, err := multicallAbi.Methods["aggregate3"].Inputs.Unpack(input[4:])
+			require.Nil(t, err)
+			calls := *abi.ConvertType(values[0], new([]multicall3Call)).(*[]multicall3Call)
+			type result struct {
+				Success    bool
+				ReturnData []byte
+			}
+			results := make([]result, len(calls))
+			for i, call := range calls {
+				if call.Target != token {
+					results[i] = result{Success: false, ReturnData: []byte{}}
+					continue
+				}
+				erc20Method, err := erc20Abi.MethodById(call.CallData[:4])
+				require.Nil(t, err)
+				outputs := map[string]interface{}{"decimals": uint8(6), "symbol": "USDT", "name": "Tether USD", "balanceOf": big.NewInt(100)}
+				data, err := erc20Method.Outputs.Pack(outputs[erc20Method.Name])
+				require.Nil(t, err)
+				results[i] = result{Success: true, ReturnData: data}
+			}
+			output, err := multicallAbi.Methods["aggregate3"].Outputs.Pack(results)
+			require.Nil(t, err)
+			return hexutil.Bytes(output), nil
+		}
+		return nil, errors.New("unexpected method " + method)
+	})
+	chain := NewChainWithRpc(url)
+
+	// the reverted contract is ignored.
+	discovery, err := chain.DiscoverErc20Tokens(address, 0, 0)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(discovery.Tokens))
+	require.Equal(t, token.String(), discovery.Tokens[0].ContractAddress)
+	require.Equal(t, "USDT", discovery.Tokens[0].Symbol)
+	require.Equal(t, "Tether USD", discovery.Tokens[0].Name)
+	require.Equal(t, int16(6), discovery.Tokens[0].Decimal)
+	require.Equal(t, "100", discovery.Tokens[0].Balance)
+	require.Equal(t, int64(101), discovery.NextBlock)
+
+	// the transport error is returned, so the scan can be retried from the same block.
+	callErr = errors.New("429 Too Many Requests")
+	_, err = chain.DiscoverErc20Tokens(address, 0, 0)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "429")
+}
@@ -3,6 +3,7 @@ package eth
 import (
 	"context"
 	"math/big"
+	"sync/atomic"
 	"time"
 
 	"github.com/coming-chat/wallet-SDK/core/base"
@@ -16,6 +17,10 @@ type EthChain struct {
 	timeout         time.Duration
 	chainId         *big.Int
 	rpcUrl          string
+
+	// The address of Multicall3 contract (common.Address), default is `Multicall3Address` if not set.
+	multicallAddressValue atomic.Value
+	multicallUnsupported  atomic.Bool
 }
 
 func NewEthChain() *EthChain {
@@ -2,10 +2,12 @@ package eth
 
 import (
 	"context"
+	"math/big"
 	"strconv"
 
 	"github.com/coming-chat/wallet-SDK/core/base"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // @title    主网代币余额查询
@@ -35,6 +37,21 @@ func (e *EthChain) LatestBlockNumber() (int64, error) {
 	return int64(number), nil
 }
 
+// 获取区块头, blockNumber 小于 0 表示最新区块
+func (e *EthChain) HeaderByNumber(blockNumber int64) (*types.Header, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	var number *big.Int = nil
+	if blockNumber >= 0 {
+		number = big.NewInt(blockNumber)
+	}
+	header, err := e.RemoteRpcClient.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	return header, nil
+}
+
 // 获取账户nonce
 func (e *EthChain) Nonce(spenderAddressHex string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
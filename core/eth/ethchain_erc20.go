@@ -25,6 +25,11 @@ func (e *EthChain) SdkBatchTokenBalance(contractListString, address string) (str
 	return c.BatchFetchErc20TokenBalance(contractListString, address)
 }
 
+// The contract does not implement erc20 correctly, e.g. the call is reverted or the result can't be decoded.
+type invalidErc20Error struct {
+	error
+}
+
 // Deprecated: Erc20TokenInfo is deprecated. Please Use Chain.Erc20Token().Erc20TokenInfo()
 // @title    Erc20代币基础信息
 // @description   返回代币基础信息
@@ -35,23 +40,34 @@ func (e *EthChain) Erc20TokenInfo(contractAddress string, walletAddress string)
 	var token = Erc20TokenInfo{TokenInfo: &base.TokenInfo{}}
 	token.ContractAddress = contractAddress
 	token.ChainId = e.chainId.String()
-	var err error
-	token.Decimal, err = e.TokenDecimal(contractAddress)
-	if err != nil {
-		return nil, err
-	}
-	token.Symbol, err = e.TokenSymbol(contractAddress)
-	if err != nil {
-		return nil, err
-	}
-	token.Name, err = e.TokenName(contractAddress)
+	if len(contractAddress) == 0 || len(walletAddress) == 0 {
+		return nil, errors.New("The address of the contract or wallet is empty.")
+	}
+	outs, errs, err := e.multicallContractMethods(Erc20AbiStr, []contractMethodCall{
+		{contract: contractAddress, method: "decimals"},
+		{contract: contractAddress, method: "symbol"},
+		{contract: contractAddress, method: "name"},
+		{contract: contractAddress, method: "balanceOf", params: []interface{}{common.HexToAddress(walletAddress)}},
+	})
 	if err != nil {
 		return nil, err
 	}
-	token.Balance, err = e.TokenBalance(contractAddress, walletAddress)
-	if err != nil {
-		return nil, err
+	for _, err := range errs {
+		if err != nil {
+			return nil, &invalidErc20Error{err}
+		}
 	}
+	decimal, ok1 := outs[0].(uint8)
+	symbol, ok2 := outs[1].(string)
+	name, ok3 := outs[2].(string)
+	balance, ok4 := outs[3].(*big.Int)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, &invalidErc20Error{errors.New("Invalid erc20 token info")}
+	}
+	token.Decimal = int16(decimal)
+	token.Symbol = symbol
+	token.Name = name
+	token.Balance = balance.String()
 	return &token, nil
 }
 
@@ -0,0 +1,120 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 默认每次 eth_getLogs 请求的区块数量
+const DEFAULT_LOG_BLOCK_RANGE int64 = 5000
+
+// 节点限制了 eth_getLogs 的区块范围或者返回数量时的错误信息 (不同节点服务商的描述不同)
+var logRangeLimitErrors = []string{
+	"query returned more than",
+	"too many results",
+	"too many logs",
+	"block range",
+	"range too large",
+	"range is too large",
+	"limit exceeded",
+	"response size exceeded",
+	"response is too big",
+}
+
+// 调用 eth_getLogs 查询日志
+func (e *EthChain) FilterLogs(query ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	logs, err := e.RemoteRpcClient.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	return logs, nil
+}
+
+// 分段扫描区块范围 [fromBlock, toBlock] 内的日志
+// 每一段会请求所有的 queries (FromBlock 和 ToBlock 会被覆盖), 去重并按照 区块高度、日志序号 排序后回调给 handler
+// 如果节点限制了区块范围或者返回的日志数量，会将分段范围减半后重试，该段成功后恢复为配置的分段范围
+// 其他错误 (例如网络错误) 会直接返回
+// @param blockRange 每一段的区块数量, 0 表示使用默认值 DEFAULT_LOG_BLOCK_RANGE
+// @param handler 每一段扫描完成后的回调, chunkEnd 是该段的最后一个区块; 返回错误会终止扫描
+func (e *EthChain) FilterLogsInChunks(queries []ethereum.FilterQuery, fromBlock, toBlock, blockRange int64,
+	handler func(logs []types.Log, chunkEnd int64) error) error {
+	if fromBlock < 0 || toBlock < fromBlock {
+		return errors.New("Invalid block range")
+	}
+	if blockRange <= 0 {
+		blockRange = DEFAULT_LOG_BLOCK_RANGE
+	}
+
+	chunkRange := blockRange
+	for start := fromBlock; start <= toBlock; {
+		end := base.Min(start+chunkRange-1, toBlock)
+		logs, err := e.filterLogsOfQueries(queries, start, end)
+		if err != nil {
+			if chunkRange > 1 && isLogRangeLimitError(err) {
+				chunkRange = chunkRange / 2
+				continue
+			}
+			return err
+		}
+		chunkRange = blockRange
+		if err = handler(logs, end); err != nil {
+			return err
+		}
+		start = end + 1
+	}
+	return nil
+}
+
+func (e *EthChain) filterLogsOfQueries(queries []ethereum.FilterQuery, fromBlock, toBlock int64) ([]types.Log, error) {
+	type logKey struct {
+		txHash string
+		index  uint
+	}
+	seen := make(map[logKey]bool)
+	result := []types.Log{}
+	for _, query := range queries {
+		query.FromBlock = big.NewInt(fromBlock)
+		query.ToBlock = big.NewInt(toBlock)
+		logs, err := e.FilterLogs(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			if log.Removed {
+				continue
+			}
+			key := logKey{log.TxHash.String(), log.Index}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, log)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].BlockNumber != result[j].BlockNumber {
+			return result[i].BlockNumber < result[j].BlockNumber
+		}
+		return result[i].Index < result[j].Index
+	})
+	return result, nil
+}
+
+func isLogRangeLimitError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, limit := range logRangeLimitErrors {
+		if strings.Contains(msg, limit) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package eth
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// A json-rpc server which answers every request (or batch element) with the handler.
+// @return the url of the server
+func newMockRpcServer(t *testing.T, handler func(method string, params []json.RawMessage) (interface{}, error)) string {
+	type request struct {
+		Id     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	respond := func(req request) map[string]interface{} {
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.Id}
+		if req.Method == "eth_chainId" {
+			res["result"] = "0x1"
+			return res
+		}
+		result, err := handler(req.Method, req.Params)
+		if err != nil {
+			res["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+		} else {
+			res["result"] = result
+		}
+		return res
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.Nil(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		if len(body) > 0 && body[0] == '[' {
+			var reqs []request
+			require.Nil(t, json.Unmarshal(body, &reqs))
+			results := make([]map[string]interface{}, len(reqs))
+			for i, req := range reqs {
+				results[i] = respond(req)
+			}
+			_ = json.NewEncoder(w).Encode(results)
+			return
+		}
+		var req request
+		require.Nil(t, json.Unmarshal(body, &req))
+		_ = json.NewEncoder(w).Encode(respond(req))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func newMockRpcChain(t *testing.T, handler func(method string, params []json.RawMessage) (interface{}, error)) *EthChain {
+	chain, err := NewEthChain().CreateRemote(newMockRpcServer(t, handler))
+	require.Nil(t, err)
+	t.Cleanup(chain.Close)
+	return chain
+}
+
+func TestFilterLogsInChunks(t *testing.T) {
+	var ranges [][2]uint64
+	var limitErr error = errors.New("query returned more than 10000 results")
+	chain := newMockRpcChain(t, func(method string, params []json.RawMessage) (interface{}, error) {
+		require.Equal(t, "eth_getLogs", method)
+		var filter struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		}
+		require.Nil(t, json.Unmarshal(params[0], &filter))
+		ranges = append(ranges, [2]uint64{uint64(filter.FromBlock), uint64(filter.ToBlock)})
+		if limitErr != nil && filter.ToBlock-filter.FromBlock >= 100 {
+			return nil, limitErr
+		}
+		return []types.Log{}, nil
+	})
+
+	chunkEnds := []int64{}
+	handler := func(logs []types.Log, chunkEnd int64) error {
+		chunkEnds = append(chunkEnds, chunkEnd)
+		return nil
+	}
+	queries := []ethereum.FilterQuery{{}}
+	err := chain.FilterLogsInChunks(queries, 0, 299, 200, handler)
+	require.Nil(t, err)
+	// the range is restored after the shrunk chunk succeeds.
+	require.Equal(t, [][2]uint64{{0, 199}, {0, 99}, {100, 299}, {100, 199}, {200, 299}}, ranges)
+	require.Equal(t, []int64{99, 199, 299}, chunkEnds)
+
+	// the other errors are returned without retrying.
+	ranges, chunkEnds = nil, []int64{}
+	limitErr = errors.New("internal error")
+	err = chain.FilterLogsInChunks(queries, 0, 299, 200, handler)
+	require.NotNil(t, err)
+	require.Equal(t, 1, len(ranges))
+	require.Equal(t, 0, len(chunkEnds))
+}
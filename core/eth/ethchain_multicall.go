@@ -0,0 +1,308 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// Multicall3 在绝大多数 evm 链上都部署在这个地址, https://github.com/mds1/multicall
+	Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+	Multicall3AbiStr = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+	// 每一次 aggregate3 调用最多打包的请求数量
+	multicallBatchSize = 100
+)
+
+var (
+	multicall3AbiParsed     abi.ABI
+	multicall3AbiParsedErr  error
+	multicall3AbiParsedOnce sync.Once
+)
+
+type MulticallResult struct {
+	Success    bool
+	ReturnData []byte
+	// The error message if the call is failed
+	Error string
+}
+
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+func multicall3Abi() (*abi.ABI, error) {
+	multicall3AbiParsedOnce.Do(func() {
+		multicall3AbiParsed, multicall3AbiParsedErr = abi.JSON(strings.NewReader(Multicall3AbiStr))
+	})
+	return &multicall3AbiParsed, multicall3AbiParsedErr
+}
+
+func (e *EthChain) multicallAddress() common.Address {
+	if address, ok := e.multicallAddressValue.Load().(common.Address); ok {
+		return address
+	}
+	return common.HexToAddress(Multicall3Address)
+}
+
+// Set the address of Multicall3 contract, it's only necessary if the contract is not deployed at `Multicall3Address`
+// @param address empty means use `Multicall3Address`
+func (e *EthChain) SetMulticallAddress(address string) error {
+	if address == "" {
+		address = Multicall3Address
+	}
+	if !IsValidAddress(address) {
+		return errors.New("Invalid hex address")
+	}
+	e.multicallAddressValue.Store(common.HexToAddress(address))
+	e.multicallUnsupported.Store(false)
+	return nil
+}
+
+// 批量调用合约的只读方法，每一个请求的失败不会影响其他请求
+// 优先使用 Multicall3 的 aggregate3 将多个请求打包成一个 eth_call,
+// 如果链上没有部署 Multicall 合约, 或者请求中有转账金额、指定了 From (aggregate3 的调用者是 Multicall 合约)，会降级为 JSON-RPC 的批量请求
+// @return 结果数组，顺序与传入的 msgs 保持一致
+func (e *EthChain) Multicall(msgs []ethereum.CallMsg) (results []*MulticallResult, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if len(msgs) == 0 {
+		return []*MulticallResult{}, nil
+	}
+	canAggregate := !e.multicallUnsupported.Load()
+	for _, msg := range msgs {
+		if msg.To == nil || (msg.Value != nil && msg.Value.Sign() != 0) || msg.From != (common.Address{}) {
+			canAggregate = false
+			break
+		}
+	}
+	if canAggregate {
+		results, err = e.aggregate3(msgs)
+		if err == nil {
+			return results, nil
+		}
+	}
+	return e.batchCallContract(msgs)
+}
+
+func (e *EthChain) aggregate3(msgs []ethereum.CallMsg) ([]*MulticallResult, error) {
+	parsedAbi, err := multicall3Abi()
+	if err != nil {
+		return nil, err
+	}
+	multicallAddress := e.multicallAddress()
+	results := make([]*MulticallResult, 0, len(msgs))
+	for start := 0; start < len(msgs); start += multicallBatchSize {
+		end := base.Min(start+multicallBatchSize, len(msgs))
+		calls := make([]multicall3Call, 0, end-start)
+		for _, msg := range msgs[start:end] {
+			calls = append(calls, multicall3Call{
+				Target:       *msg.To,
+				AllowFailure: true,
+				CallData:     msg.Data,
+			})
+		}
+		data, err := parsedAbi.Pack("aggregate3", calls)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+		output, err := e.RemoteRpcClient.CallContract(ctx, ethereum.CallMsg{To: &multicallAddress, Data: data}, nil)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		if len(output) == 0 {
+			// There is no multicall contract at the address.
+			e.multicallUnsupported.Store(true)
+			return nil, errors.New("Multicall contract is not deployed")
+		}
+
+		chunkResults, err := unpackAggregate3Result(output)
+		if err != nil {
+			return nil, err
+		}
+		if len(chunkResults) != len(calls) {
+			return nil, errors.New("The number of multicall results does not match")
+		}
+		results = append(results, chunkResults...)
+	}
+	return results, nil
+}
+
+func unpackAggregate3Result(output []byte) ([]*MulticallResult, error) {
+	parsedAbi, err := multicall3Abi()
+	if err != nil {
+		return nil, err
+	}
+	values, err := parsedAbi.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, err
+	}
+	returns := *abi.ConvertType(values[0], new([]struct {
+		Success    bool
+		ReturnData []byte
+	})).(*[]struct {
+		Success    bool
+		ReturnData []byte
+	})
+	results := make([]*MulticallResult, len(returns))
+	for i, r := range returns {
+		results[i] = &MulticallResult{Success: r.Success, ReturnData: r.ReturnData}
+		if !r.Success {
+			results[i].Error = "execution reverted"
+			if reason, err := abi.UnpackRevert(r.ReturnData); err == nil {
+				results[i].Error = "execution reverted: " + reason
+			}
+		}
+	}
+	return results, nil
+}
+
+// 使用 JSON-RPC 批量请求 eth_call
+func (e *EthChain) batchCallContract(msgs []ethereum.CallMsg) ([]*MulticallResult, error) {
+	outputs := make([]hexutil.Bytes, len(msgs))
+	elems := make([]rpc.BatchElem, len(msgs))
+	for i, msg := range msgs {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{toCallArg(msg), "latest"},
+			Result: &outputs[i],
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	err := e.RpcClient.BatchCallContext(ctx, elems)
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	results := make([]*MulticallResult, len(msgs))
+	for i, elem := range elems {
+		if elem.Error != nil {
+			results[i] = &MulticallResult{Error: elem.Error.Error()}
+		} else {
+			results[i] = &MulticallResult{Success: true, ReturnData: outputs[i]}
+		}
+	}
+	return results, nil
+}
+
+// Copy from go-ethereum/ethclient
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	return arg
+}
+
+// 使用 Multicall 批量调用合约的只读方法, 并解码每个方法的第一个返回值
+// @return 解码后的结果数组，顺序与 calls 保持一致; 调用被 revert 或者返回值无法解码的结果为 nil, 错误信息在 errs 中
+// 其他的失败 (例如节点限流、超时) 无法判断合约本身是否有问题，会作为 err 返回
+func (e *EthChain) multicallContractMethods(abiStr string, calls []contractMethodCall) (outs []interface{}, errs []error, err error) {
+	parsedAbi, err := abi.JSON(strings.NewReader(abiStr))
+	if err != nil {
+		return
+	}
+	msgs := make([]ethereum.CallMsg, len(calls))
+	for i, call := range calls {
+		data, err := parsedAbi.Pack(call.method, call.params...)
+		if err != nil {
+			return nil, nil, err
+		}
+		to := common.HexToAddress(call.contract)
+		msgs[i] = ethereum.CallMsg{To: &to, Data: data}
+	}
+	results, err := e.Multicall(msgs)
+	if err != nil {
+		return
+	}
+	outs = make([]interface{}, len(calls))
+	errs = make([]error, len(calls))
+	for i, result := range results {
+		if !result.Success {
+			if !isRevertError(result.Error) {
+				return nil, nil, errors.New(result.Error)
+			}
+			errs[i] = errors.New(result.Error)
+			continue
+		}
+		if len(result.ReturnData) == 0 {
+			errs[i] = bind.ErrNoCode
+			continue
+		}
+		values, err := parsedAbi.Unpack(calls[i].method, result.ReturnData)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if len(values) > 0 {
+			outs[i] = values[0]
+		}
+	}
+	return outs, errs, nil
+}
+
+func isRevertError(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "revert") || strings.Contains(message, "invalid opcode")
+}
+
+type contractMethodCall struct {
+	contract string
+	method   string
+	params   []interface{}
+}
+
+// MARK - Chain
+
+// 设置 Multicall3 合约的地址, 见 EthChain.SetMulticallAddress
+func (c *Chain) SetMulticallAddress(address string) error {
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return err
+	}
+	return chain.SetMulticallAddress(address)
+}
+
+// 批量调用合约的只读方法, 见 EthChain.Multicall
+func (c *Chain) Multicall(msgs []*CallMsg) ([]*MulticallResult, error) {
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	callMsgs := make([]ethereum.CallMsg, len(msgs))
+	for i, msg := range msgs {
+		if msg == nil {
+			return nil, errors.New("Invalid call message")
+		}
+		callMsgs[i] = msg.msg
+	}
+	return chain.Multicall(callMsgs)
+}
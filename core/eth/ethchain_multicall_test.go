@@ -0,0 +1,140 @@
+package eth
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpackAggregate3Result(t *testing.T) {
+	parsedAbi, err := multicall3Abi()
+	require.Nil(t, err)
+
+	// Error(string) "boom"
+	revertData := append(crypto.Keccak256([]byte("Error(string)"))[:4], common.FromHex("0x00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000004626f6f6d00000000000000000000000000000000000000000000000000000000")...)
+	output, err := parsedAbi.Methods["aggregate3"].Outputs.Pack([]struct {
+		Success    bool
+		ReturnData []byte
+	}{
+		{Success: true, ReturnData: common.BigToHash(common.Big1).Bytes()},
+		{Success: false, ReturnData: revertData},
+	})
+	require.Nil(t, err)
+
+	results, err := unpackAggregate3Result(output)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(results))
+	require.True(t, results[0].Success)
+	require.Equal(t, common.BigToHash(common.Big1).Bytes(), results[0].ReturnData)
+	require.False(t, results[1].Success)
+	require.Equal(t, "execution reverted: boom", results[1].Error)
+
+	_, err = parsedAbi.Pack("aggregate3", []multicall3Call{{Target: common.HexToAddress(Multicall3Address), AllowFailure: true, CallData: []byte{1, 2, 3, 4}}})
+	require.Nil(t, err)
+}
+
+func TestMulticall(t *testing.T) {
+	parsedAbi, err := multicall3Abi()
+	require.Nil(t, err)
+	multicallAddress := common.HexToAddress("0x00000000000000000000000000000000000000ca")
+	target := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	reverted := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	sender := common.HexToAddress("0x8c951f58F63C0018BFBb47A29e55e84507eD63Bd")
+	// Error(string) "boom"
+	revertData := common.FromHex("0x08c379a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000004626f6f6d00000000000000000000000000000000000000000000000000000000")
+
+	type callArg struct {
+		From  common.Address `json:"from"`
+		To    common.Address `json:"to"`
+		Data  hexutil.Bytes  `json:"data"`
+		Input hexutil.Bytes  `json:"input"`
+	}
+	var requests []callArg
+	deployed := true
+	chain := newMockRpcChain(t, func(method string, params []json.RawMessage) (interface{}, error) {
+		require.Equal(t, "eth_call", method)
+		var arg callArg
+		require.Nil(t, json.Unmarshal(params[0], &arg))
+		requests = append(requests, arg)
+		input := append(arg.Data, arg.Input...)
+		if arg.To != multicallAddress {
+			return hexutil.Bytes{0x02}, nil
+		}
+		if !deployed {
+			return hexutil.Bytes{}, nil
+		}
+		values, err := parsedAbi.Methods["aggregate3"].Inputs.Unpack(input[4:])
+		require.Nil(t, err)
+		calls := *abi.ConvertType(values[0], new([]multicall3Call)).(*[]multicall3Call)
+		type result struct {
+			Success    bool
+			ReturnData []byte
+		}
+		results := make([]result, len(calls))
+		for i, call := range calls {
+			require.True(t, call.AllowFailure)
+			if call.Target == reverted {
+				results[i] = result{false, revertData}
+			} else {
+				results[i] = result{true, call.CallData}
+			}
+		}
+		output, err := parsedAbi.Methods["aggregate3"].Outputs.Pack(results)
+		require.Nil(t, err)
+		return hexutil.Bytes(output), nil
+	})
+	require.NotNil(t, chain.SetMulticallAddress("0x12"))
+	require.Nil(t, chain.SetMulticallAddress(multicallAddress.String()))
+
+	// aggregate3
+	results, err := chain.Multicall([]ethereum.CallMsg{
+		{To: &target, Data: []byte{0x01}},
+		{To: &reverted, Data: []byte{0x03}},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(requests))
+	require.Equal(t, multicallAddress, requests[0].To)
+	require.True(t, results[0].Success)
+	require.Equal(t, []byte{0x01}, results[0].ReturnData)
+	require.False(t, results[1].Success)
+	require.Equal(t, "execution reverted: boom", results[1].Error)
+
+	// the call with sender is not aggregated, or the sender will be the multicall contract.
+	requests = nil
+	results, err = chain.Multicall([]ethereum.CallMsg{{From: sender, To: &target, Data: []byte{0x01}}})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(requests))
+	require.Equal(t, sender, requests[0].From)
+	require.Equal(t, target, requests[0].To)
+	require.Equal(t, []byte{0x02}, results[0].ReturnData)
+
+	// fallback to the batch request if the multicall contract is not deployed.
+	requests, deployed = nil, false
+	results, err = chain.Multicall([]ethereum.CallMsg{{To: &target, Data: []byte{0x01}}})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(requests))
+	require.Equal(t, []byte{0x02}, results[0].ReturnData)
+	requests = nil
+	_, err = chain.Multicall([]ethereum.CallMsg{{To: &target, Data: []byte{0x01}}})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(requests))
+	require.Equal(t, target, requests[0].To)
+}
+
+func TestChain_Multicall(t *testing.T) {
+	chain := rpcs.ethereumProd.Chain()
+	usdt := rpcs.ethereumProd.contracts.USDT
+	balances, err := chain.BatchErc20TokenBalance([]string{usdt, usdt}, "0x5754284f345afc66a98fbb0a0afe71e0f007b949")
+	require.Nil(t, err)
+	t.Log(balances)
+
+	info, err := chain.Erc20Token(usdt).TokenInfo()
+	require.Nil(t, err)
+	t.Log(info)
+}
@@ -161,7 +161,7 @@ func (e *EthChain) TransactionByHash(txHash string) (*TransactionByHashResult, e
 	if err != nil {
 		return nil, err
 	}
-	msg, err := tx.AsMessage(types.NewEIP155Signer(e.chainId), nil)
+	msg, err := tx.AsMessage(types.LatestSignerForChainID(e.chainId), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,451 @@
+package eth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/pkg/httpUtil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	topicTransfer              = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	topicErc1155TransferSingle = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	topicErc1155TransferBatch  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+
+	erc1155AbiParsed     abi.ABI
+	erc1155AbiParsedErr  error
+	erc1155AbiParsedOnce sync.Once
+)
+
+// An NFT owned by the owner, rebuilt from the transfer logs.
+type NFTHolding struct {
+	ContractAddress string `json:"contract_address"`
+	TokenId         string `json:"token_id"`
+	Standard        string `json:"standard"`
+	// The amount of the token owned, erc721 is always 1
+	Amount string `json:"amount"`
+	// The block and transaction hash that the owner last received this token
+	BlockNumber int64  `json:"block_number"`
+	HashString  string `json:"hash"`
+}
+
+// The resumable scan progress of LogNFTFetcher.
+type NFTLogCursor struct {
+	Owner string `json:"owner"`
+	// The next block to be scanned
+	NextBlock int64 `json:"next_block"`
+	// key is `contract-tokenId`
+	Holdings map[string]*NFTHolding `json:"holdings"`
+}
+
+func newNFTLogCursor(owner string, startBlock int64) *NFTLogCursor {
+	return &NFTLogCursor{
+		Owner:     strings.ToLower(owner),
+		NextBlock: startBlock,
+		Holdings:  make(map[string]*NFTHolding),
+	}
+}
+
+// LogNFTFetcher rebuilds the owner's ERC721/ERC1155 holdings by scanning the `Transfer`, `TransferSingle`
+// and `TransferBatch` logs through `eth_getLogs`, it does not depend on any third-party indexer.
+type LogNFTFetcher struct {
+	Chain *Chain
+	// The first block to be scanned if there is no cursor, default is 0.
+	// It's recommended to set it to the account's first transaction block to reduce requests.
+	StartBlock int64
+	// The number of blocks per `eth_getLogs` request, default is `DEFAULT_LOG_BLOCK_RANGE`
+	BlockRange int64
+
+	cursor *NFTLogCursor
+}
+
+func NewLogNFTFetcher(chain *Chain) *LogNFTFetcher {
+	return &LogNFTFetcher{
+		Chain:      chain,
+		BlockRange: DEFAULT_LOG_BLOCK_RANGE,
+	}
+}
+
+// @return The json string of the scan progress, you can save it and restore with `SetCursorJsonString()` next time.
+func (f *LogNFTFetcher) CursorJsonString() (*base.OptionalString, error) {
+	if f.cursor == nil {
+		return nil, errors.New("There is no scan progress yet")
+	}
+	return base.JsonString(f.cursor)
+}
+
+// Restore the scan progress saved by `CursorJsonString()`
+func (f *LogNFTFetcher) SetCursorJsonString(str string) error {
+	var cursor NFTLogCursor
+	err := base.FromJsonString(str, &cursor)
+	if err != nil {
+		return err
+	}
+	if cursor.Holdings == nil {
+		cursor.Holdings = make(map[string]*NFTHolding)
+	}
+	cursor.Owner = strings.ToLower(cursor.Owner)
+	f.cursor = &cursor
+	return nil
+}
+
+// Scan the transfer logs from the cursor to the latest block.
+// @return The owner's holdings
+func (f *LogNFTFetcher) ScanHoldings(owner string) (holdings []*NFTHolding, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if !IsValidAddress(owner) {
+		return nil, fmt.Errorf("Invalid owner address %v", owner)
+	}
+	if f.cursor == nil || f.cursor.Owner != strings.ToLower(owner) {
+		f.cursor = newNFTLogCursor(owner, f.StartBlock)
+	}
+	chain, err := GetConnection(f.Chain.RpcUrl)
+	if err != nil {
+		return
+	}
+	latest, err := chain.LatestBlockNumber()
+	if err != nil {
+		return
+	}
+
+	if f.cursor.NextBlock <= latest {
+		ownerTopic := common.BytesToHash(common.HexToAddress(owner).Bytes())
+		erc1155Topics := []common.Hash{topicErc1155TransferSingle, topicErc1155TransferBatch}
+		queries := []ethereum.FilterQuery{
+			{Topics: [][]common.Hash{{topicTransfer}, {ownerTopic}}},
+			{Topics: [][]common.Hash{{topicTransfer}, nil, {ownerTopic}}},
+			{Topics: [][]common.Hash{erc1155Topics, nil, {ownerTopic}}},
+			{Topics: [][]common.Hash{erc1155Topics, nil, nil, {ownerTopic}}},
+		}
+		err = chain.FilterLogsInChunks(queries, f.cursor.NextBlock, latest, f.BlockRange, func(logs []types.Log, chunkEnd int64) error {
+			for _, log := range logs {
+				f.cursor.applyLog(log)
+			}
+			f.cursor.NextBlock = chunkEnd + 1
+			return nil
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	holdings = make([]*NFTHolding, 0, len(f.cursor.Holdings))
+	for _, holding := range f.cursor.Holdings {
+		holdings = append(holdings, holding)
+	}
+	sort.Slice(holdings, func(i, j int) bool {
+		return holdings[i].BlockNumber > holdings[j].BlockNumber
+	})
+	return holdings, nil
+}
+
+func (f *LogNFTFetcher) FetchNFTs(owner string) (res map[string][]*base.NFT, err error) {
+	holdings, err := f.ScanHoldings(owner)
+	if err != nil {
+		return
+	}
+	chain, err := GetConnection(f.Chain.RpcUrl)
+	if err != nil {
+		return
+	}
+
+	resolver := &nftMetadataResolver{
+		chain:       chain,
+		collections: make(map[string]string),
+		timestamps:  make(map[int64]int64),
+	}
+	list := make([]interface{}, len(holdings))
+	for i, h := range holdings {
+		list[i] = h
+	}
+	nfts, err := base.MapListConcurrent(list, 10, func(i interface{}) (interface{}, error) {
+		return resolver.resolve(i.(*NFTHolding)), nil
+	})
+	if err != nil {
+		return
+	}
+
+	nftGroupd := make(map[string][]*base.NFT)
+	for _, item := range nfts {
+		nft := item.(*base.NFT)
+		key := nft.GroupName()
+		group, exists := nftGroupd[key]
+		if exists {
+			nftGroupd[key] = append(group, nft)
+		} else {
+			nftGroupd[key] = []*base.NFT{nft}
+		}
+	}
+	for _, group := range nftGroupd {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].Timestamp > group[j].Timestamp
+		})
+	}
+	return nftGroupd, nil
+}
+
+// @return json string that grouped by nft's collection
+func (f *LogNFTFetcher) FetchNFTsJsonString(owner string) (*base.OptionalString, error) {
+	nfts, err := f.FetchNFTs(owner)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := json.Marshal(nfts)
+	if err != nil {
+		return nil, err
+	}
+	return &base.OptionalString{Value: string(bytes)}, nil
+}
+
+func (c *NFTLogCursor) applyLog(log types.Log) {
+	if len(log.Topics) == 0 {
+		return
+	}
+	var (
+		standard string
+		from, to common.Address
+		ids      []*big.Int
+		amounts  []*big.Int
+	)
+	switch log.Topics[0] {
+	case topicTransfer:
+		// erc20 Transfer have the same topic, but the value is not indexed.
+		if len(log.Topics) != 4 {
+			return
+		}
+		standard = NFTStandardERC721
+		from = common.BytesToAddress(log.Topics[1].Bytes())
+		to = common.BytesToAddress(log.Topics[2].Bytes())
+		ids = []*big.Int{log.Topics[3].Big()}
+		amounts = []*big.Int{big.NewInt(1)}
+	case topicErc1155TransferSingle, topicErc1155TransferBatch:
+		if len(log.Topics) != 4 {
+			return
+		}
+		standard = NFTStandardERC1155
+		from = common.BytesToAddress(log.Topics[2].Bytes())
+		to = common.BytesToAddress(log.Topics[3].Bytes())
+		var err error
+		ids, amounts, err = unpackErc1155TransferData(log.Topics[0], log.Data)
+		if err != nil || len(ids) != len(amounts) {
+			return
+		}
+	default:
+		return
+	}
+
+	owner := common.HexToAddress(c.Owner)
+	contract := strings.ToLower(log.Address.String())
+	for i, id := range ids {
+		key := contract + "-" + id.String()
+		holding, exists := c.Holdings[key]
+		amount := big.NewInt(0)
+		if exists {
+			amount.SetString(holding.Amount, 10)
+		} else {
+			holding = &NFTHolding{
+				ContractAddress: log.Address.String(),
+				TokenId:         id.String(),
+				Standard:        standard,
+			}
+		}
+		if from == owner {
+			amount.Sub(amount, amounts[i])
+		}
+		if to == owner {
+			amount.Add(amount, amounts[i])
+			holding.BlockNumber = int64(log.BlockNumber)
+			holding.HashString = log.TxHash.String()
+		}
+		if amount.Sign() <= 0 {
+			delete(c.Holdings, key)
+			continue
+		}
+		holding.Amount = amount.String()
+		c.Holdings[key] = holding
+	}
+}
+
+func unpackErc1155TransferData(topic common.Hash, data []byte) (ids []*big.Int, amounts []*big.Int, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	erc1155AbiParsedOnce.Do(func() {
+		erc1155AbiParsed, erc1155AbiParsedErr = abi.JSON(strings.NewReader(Erc1155AbiStr))
+	})
+	if erc1155AbiParsedErr != nil {
+		return nil, nil, erc1155AbiParsedErr
+	}
+	if topic == topicErc1155TransferSingle {
+		values, err := erc1155AbiParsed.Unpack("TransferSingle", data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []*big.Int{values[0].(*big.Int)}, []*big.Int{values[1].(*big.Int)}, nil
+	} else {
+		values, err := erc1155AbiParsed.Unpack("TransferBatch", data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return values[0].([]*big.Int), values[1].([]*big.Int), nil
+	}
+}
+
+type nftMetadataResolver struct {
+	chain *EthChain
+
+	lock        sync.Mutex
+	collections map[string]string
+	timestamps  map[int64]int64
+}
+
+// The metadata that an NFT's `tokenURI` points to.
+type nftMetadata struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	ImageUrl    string `json:"image_url"`
+}
+
+// resolve will not return error, the fields which cannot be resolved will be empty.
+func (r *nftMetadataResolver) resolve(holding *NFTHolding) *base.NFT {
+	nft := &base.NFT{
+		Id:              holding.TokenId,
+		Standard:        holding.Standard,
+		ContractAddress: holding.ContractAddress,
+		HashString:      holding.HashString,
+		Collection:      r.collectionName(holding.ContractAddress),
+		Timestamp:       r.blockTimestamp(holding.BlockNumber),
+	}
+
+	tokenUri, err := r.tokenUri(holding)
+	if err != nil || tokenUri == "" {
+		return nft
+	}
+	metadata, err := fetchNFTMetadata(tokenUri)
+	if err != nil {
+		// Maybe the uri is the image itself, the client can use `ExtractedImageUrl()` to try again.
+		nft.Image = base.IpfsUrlToHttpUrl(tokenUri)
+		return nft
+	}
+	nft.Name = metadata.Name
+	nft.Description = metadata.Description
+	if metadata.Image != "" {
+		nft.Image = base.IpfsUrlToHttpUrl(metadata.Image)
+	} else {
+		nft.Image = base.IpfsUrlToHttpUrl(metadata.ImageUrl)
+	}
+	return nft
+}
+
+func (r *nftMetadataResolver) tokenUri(holding *NFTHolding) (string, error) {
+	id, ok := big.NewInt(0).SetString(holding.TokenId, 10)
+	if !ok {
+		return "", errors.New("Invalid token id")
+	}
+	uri := ""
+	if holding.Standard == NFTStandardERC1155 {
+		err := r.chain.CallContractConstant(&uri, holding.ContractAddress, Erc1155AbiStr, "uri", nil, id)
+		if err != nil {
+			return "", err
+		}
+		// https://eips.ethereum.org/EIPS/eip-1155#metadata
+		uri = strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", id))
+	} else {
+		err := r.chain.CallContractConstant(&uri, holding.ContractAddress, Erc721AbiStr, "tokenURI", nil, id)
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(uri), nil
+}
+
+func (r *nftMetadataResolver) collectionName(contract string) string {
+	r.lock.Lock()
+	name, exists := r.collections[contract]
+	r.lock.Unlock()
+	if exists {
+		return name
+	}
+	// erc1155 may not have a name, it's will be grouped to `Others`
+	err := r.chain.CallContractConstant(&name, contract, Erc721AbiStr, "name", nil)
+	if err != nil {
+		name = ""
+	}
+	r.lock.Lock()
+	r.collections[contract] = name
+	r.lock.Unlock()
+	return name
+}
+
+func (r *nftMetadataResolver) blockTimestamp(blockNumber int64) int64 {
+	r.lock.Lock()
+	timestamp, exists := r.timestamps[blockNumber]
+	r.lock.Unlock()
+	if exists {
+		return timestamp
+	}
+	header, err := r.chain.HeaderByNumber(blockNumber)
+	if err != nil {
+		return 0
+	}
+	timestamp = int64(header.Time)
+	r.lock.Lock()
+	r.timestamps[blockNumber] = timestamp
+	r.lock.Unlock()
+	return timestamp
+}
+
+// Supports http(s) url, ipfs url and `data:application/json` url
+func fetchNFTMetadata(uri string) (*nftMetadata, error) {
+	var body []byte
+	if strings.HasPrefix(uri, "data:") {
+		idx := strings.Index(uri, ",")
+		if idx < 0 {
+			return nil, errors.New("Invalid data uri")
+		}
+		header, data := uri[:idx], uri[idx+1:]
+		if !strings.Contains(header, "json") {
+			return nil, errors.New("The data uri is not json")
+		}
+		if strings.HasSuffix(header, ";base64") {
+			decoded, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return nil, err
+			}
+			body = decoded
+		} else {
+			unescaped, err := url.PathUnescape(data)
+			if err != nil {
+				return nil, err
+			}
+			body = []byte(unescaped)
+		}
+	} else {
+		resp, err := httpUtil.Get(base.IpfsUrlToHttpUrl(uri), nil)
+		if err != nil {
+			return nil, err
+		}
+		body = resp
+	}
+
+	var metadata nftMetadata
+	err := json.Unmarshal(body, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
@@ -0,0 +1,89 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func addressTopic(address string) common.Hash {
+	return common.BytesToHash(common.HexToAddress(address).Bytes())
+}
+
+func TestNFTLogCursor_applyLog(t *testing.T) {
+	owner := "0x8c951f58F63C0018BFBb47A29e55e84507eD63Bd"
+	other := "0x6334d64D5167F726d8A44f3fbCA66613708E59E7"
+	erc721 := common.HexToAddress("0x0000000000000000000000000000000000000721")
+	erc1155 := common.HexToAddress("0x0000000000000000000000000000000000001155")
+
+	uint256Array, _ := abi.NewType("uint256[]", "", nil)
+	batchData, err := abi.Arguments{{Type: uint256Array}, {Type: uint256Array}}.Pack(
+		[]*big.Int{big.NewInt(7), big.NewInt(8)},
+		[]*big.Int{big.NewInt(5), big.NewInt(1)},
+	)
+	require.Nil(t, err)
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	singleData, err := abi.Arguments{{Type: uint256Type}, {Type: uint256Type}}.Pack(big.NewInt(7), big.NewInt(2))
+	require.Nil(t, err)
+
+	logs := []types.Log{
+		// receive erc721 #1 and #2
+		{Address: erc721, BlockNumber: 1, Topics: []common.Hash{topicTransfer, addressTopic(other), addressTopic(owner), common.BigToHash(big.NewInt(1))}},
+		{Address: erc721, BlockNumber: 2, Topics: []common.Hash{topicTransfer, addressTopic(other), addressTopic(owner), common.BigToHash(big.NewInt(2))}},
+		// erc20 transfer should be ignored
+		{Address: erc721, BlockNumber: 2, Topics: []common.Hash{topicTransfer, addressTopic(other), addressTopic(owner)}, Data: common.BigToHash(big.NewInt(100)).Bytes()},
+		// send erc721 #1
+		{Address: erc721, BlockNumber: 3, Topics: []common.Hash{topicTransfer, addressTopic(owner), addressTopic(other), common.BigToHash(big.NewInt(1))}},
+		// receive erc1155 #7 x5, #8 x1
+		{Address: erc1155, BlockNumber: 4, Topics: []common.Hash{topicErc1155TransferBatch, addressTopic(other), addressTopic(other), addressTopic(owner)}, Data: batchData},
+		// send erc1155 #7 x2
+		{Address: erc1155, BlockNumber: 5, Topics: []common.Hash{topicErc1155TransferSingle, addressTopic(owner), addressTopic(owner), addressTopic(other)}, Data: singleData},
+	}
+
+	cursor := newNFTLogCursor(owner, 0)
+	for _, log := range logs {
+		cursor.applyLog(log)
+	}
+
+	require.Equal(t, 3, len(cursor.Holdings))
+	require.Nil(t, cursor.Holdings["0x0000000000000000000000000000000000000721-1"])
+	require.Equal(t, "1", cursor.Holdings["0x0000000000000000000000000000000000000721-2"].Amount)
+	require.Equal(t, NFTStandardERC721, cursor.Holdings["0x0000000000000000000000000000000000000721-2"].Standard)
+	require.Equal(t, "3", cursor.Holdings["0x0000000000000000000000000000000000001155-7"].Amount)
+	require.Equal(t, int64(4), cursor.Holdings["0x0000000000000000000000000000000000001155-7"].BlockNumber)
+	require.Equal(t, "1", cursor.Holdings["0x0000000000000000000000000000000000001155-8"].Amount)
+	require.Equal(t, NFTStandardERC1155, cursor.Holdings["0x0000000000000000000000000000000000001155-8"].Standard)
+}
+
+func TestFetchNFTMetadata_DataUri(t *testing.T) {
+	uri := "data:application/json;base64,eyJuYW1lIjoiVGVzdCAjMSIsImltYWdlIjoiaXBmczovL1FtVGVzdCJ9"
+	metadata, err := fetchNFTMetadata(uri)
+	require.Nil(t, err)
+	require.Equal(t, "Test #1", metadata.Name)
+	require.Equal(t, "https://ipfs.io/ipfs/QmTest", base.IpfsUrlToHttpUrl(metadata.Image))
+}
+
+func TestLogNFTFetcher(t *testing.T) {
+	owner := "0x8c951f58F63C0018BFBb47A29e55e84507eD63Bd"
+	fetcher := NewLogNFTFetcher(rpcs.ethereumProd.Chain())
+	fetcher.StartBlock = 16000000
+
+	var nftFetcher base.NFTFetcher = fetcher
+	nfts, err := nftFetcher.FetchNFTs(owner)
+	require.Nil(t, err)
+	for name, group := range nfts {
+		t.Logf("group: %v, count: %v", name, len(group))
+		for idx, nft := range group {
+			t.Logf("%4v: %v", idx, nft)
+		}
+	}
+
+	cursor, err := fetcher.CursorJsonString()
+	require.Nil(t, err)
+	t.Log(cursor.Value)
+}
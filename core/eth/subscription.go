@@ -0,0 +1,27 @@
+package eth
+
+import (
+	"encoding/json"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+// Subscribe the new block headers, the message is the json of block header.
+// @param wsUrl the websocket url of the rpc node, e.g. wss://...
+func NewNewHeadsSubscription(wsUrl string) *base.Subscription {
+	return base.NewSubscription(wsUrl, "eth_subscribe", []any{"newHeads"}, "eth_unsubscribe")
+}
+
+// Subscribe the logs which match the filter, the message is the json of log.
+// @param filterJson the filter of logs, e.g. `{"address":"0x...","topics":["0x..."]}`, empty means all logs.
+func NewLogsSubscription(wsUrl, filterJson string) (*base.Subscription, error) {
+	params := []any{"logs"}
+	if filterJson != "" {
+		filter := make(map[string]any)
+		if err := json.Unmarshal([]byte(filterJson), &filter); err != nil {
+			return nil, err
+		}
+		params = append(params, filter)
+	}
+	return base.NewSubscription(wsUrl, "eth_subscribe", params, "eth_unsubscribe"), nil
+}
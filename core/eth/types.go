@@ -133,10 +133,13 @@ type Transaction struct {
 
 	// EIP1559, Default is ""
 	MaxPriorityFeePerGas string
+
+	// The transaction deploys a contract and `To` is ignored, an empty `To` means the zero address otherwise.
+	contractCreation bool
 }
 
 func NewTransaction(nonce, gasPrice, gasLimit, to, value, data string) *Transaction {
-	return &Transaction{nonce, gasPrice, gasLimit, to, value, data, ""}
+	return &Transaction{nonce, gasPrice, gasLimit, to, value, data, "", false}
 }
 
 func NewTransactionFromHex(hexData string) (*Transaction, error) {
@@ -149,13 +152,18 @@ func NewTransactionFromHex(hexData string) (*Transaction, error) {
 	if err != nil {
 		return nil, err
 	}
+	to := ""
+	if decodeTx.To() != nil {
+		to = decodeTx.To().String()
+	}
 	tx := NewTransaction(
 		strconv.Itoa(int(decodeTx.Nonce())),
 		decodeTx.GasFeeCap().String(),
 		strconv.Itoa(int(decodeTx.Gas())),
-		decodeTx.To().String(),
+		to,
 		decodeTx.Value().String(),
 		hex.EncodeToString(decodeTx.Data()))
+	tx.contractCreation = decodeTx.To() == nil
 	// not equal, is eip1559; legacy feecap equal tipcap
 	if decodeTx.GasTipCap().Cmp(decodeTx.GasFeeCap()) != 0 {
 		tx.MaxPriorityFeePerGas = decodeTx.GasTipCap().String()
@@ -212,7 +220,11 @@ func (tx *Transaction) GetRawTx() (*types.Transaction, error) {
 	if tx.To != "" && !common.IsHexAddress(tx.To) {
 		return nil, errors.New("Invalid toAddress")
 	}
-	toAddress = common.HexToAddress(tx.To)
+	var to *common.Address // nil means contract creation
+	if !tx.contractCreation {
+		toAddress = common.HexToAddress(tx.To)
+		to = &toAddress
+	}
 	if tx.Data != "" {
 		if data, err = HexType.HexDecodeString(tx.Data); err != nil {
 			return nil, errors.New("Invalid data string")
@@ -223,7 +235,7 @@ func (tx *Transaction) GetRawTx() (*types.Transaction, error) {
 		// is legacy tx
 		return types.NewTx(&types.LegacyTx{
 			Nonce:    nonce,
-			To:       &toAddress,
+			To:       to,
 			Value:    value,
 			Gas:      gasLimit,
 			GasPrice: gasPrice,
@@ -233,7 +245,7 @@ func (tx *Transaction) GetRawTx() (*types.Transaction, error) {
 		// is dynamic fee tx
 		return types.NewTx(&types.DynamicFeeTx{
 			Nonce:     nonce,
-			To:        &toAddress,
+			To:        to,
 			Value:     value,
 			Gas:       gasLimit,
 			GasFeeCap: gasPrice,
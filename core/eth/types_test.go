@@ -1,8 +1,14 @@
 package eth
 
 import (
+	"encoding/hex"
+	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewTransactionFromHex(t *testing.T) {
@@ -63,3 +69,27 @@ func TestNewTransactionFromHex(t *testing.T) {
 		})
 	}
 }
+
+func TestTransaction_ContractCreation(t *testing.T) {
+	// an empty receiver is the zero address, not a contract creation.
+	rawTx, err := NewTransaction("1", "1000", "21000", "", "1", "").GetRawTx()
+	require.Nil(t, err)
+	require.NotNil(t, rawTx.To())
+	require.Equal(t, common.Address{}, *rawTx.To())
+
+	creation := types.NewTx(&types.LegacyTx{
+		Nonce:    3,
+		Gas:      500000,
+		GasPrice: big.NewInt(1001),
+		Data:     []byte{0x60, 0x80, 0x60, 0x40},
+	})
+	rawBytes, err := creation.MarshalBinary()
+	require.Nil(t, err)
+	tx, err := NewTransactionFromHex(hex.EncodeToString(rawBytes))
+	require.Nil(t, err)
+	require.Equal(t, "", tx.To)
+	rawTx, err = tx.GetRawTx()
+	require.Nil(t, err)
+	require.Nil(t, rawTx.To())
+	require.Equal(t, creation.Data(), rawTx.Data())
+}
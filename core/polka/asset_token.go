@@ -0,0 +1,331 @@
+package polka
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+const (
+	// The pallet-assets, e.g. the assets of Statemint/AssetHub
+	AssetPalletAssets = "Assets"
+	// The pallet-assets instance of foreign assets, the asset id is the SCALE encoded MultiLocation.
+	AssetPalletForeignAssets = "ForeignAssets"
+	// The orml-tokens, e.g. the tokens of Acala/Karura/Bifrost
+	AssetPalletTokens = "Tokens"
+)
+
+// The token of pallet-assets or orml-tokens.
+type AssetToken struct {
+	chain *Chain
+
+	// The pallet name, e.g. AssetPalletAssets, AssetPalletTokens
+	Pallet string
+	// The asset id number, or the SCALE encoded asset id in hex (e.g. the `CurrencyId` of orml-tokens).
+	AssetId string
+}
+
+// @param pallet the pallet name, e.g. AssetPalletAssets, AssetPalletTokens
+// @param assetId the asset id number, or the SCALE encoded asset id in hex (e.g. the `CurrencyId` of orml-tokens).
+func NewAssetToken(chain *Chain, pallet, assetId string) *AssetToken {
+	return &AssetToken{chain: chain, Pallet: pallet, AssetId: assetId}
+}
+
+type AssetBalance struct {
+	Free     string `json:"free"`
+	Reserved string `json:"reserved"`
+	Frozen   string `json:"frozen"`
+
+	// free + reserved
+	Total string `json:"total"`
+	// free - frozen
+	Usable string `json:"usable"`
+}
+
+func (b *AssetBalance) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(b)
+}
+
+func NewAssetBalanceWithJsonString(str string) (*AssetBalance, error) {
+	var o AssetBalance
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func newAssetBalance(free, reserved, frozen *big.Int) *AssetBalance {
+	usable := new(big.Int).Sub(free, frozen)
+	if usable.Sign() < 0 {
+		usable.SetInt64(0)
+	}
+	return &AssetBalance{
+		Free:     free.String(),
+		Reserved: reserved.String(),
+		Frozen:   frozen.String(),
+		Total:    new(big.Int).Add(free, reserved).String(),
+		Usable:   usable.String(),
+	}
+}
+
+// MARK - Implement the protocol Token
+
+func (t *AssetToken) Chain() base.Chain {
+	return t.chain
+}
+
+// Read the name, symbol and decimals from `Assets.Metadata`, or `AssetRegistry.Metadata` for orml-tokens.
+func (t *AssetToken) TokenInfo() (info *base.TokenInfo, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, network, err := t.chain.storageClient()
+	if err != nil {
+		return
+	}
+	pallet, item := t.Pallet, "Metadata"
+	if t.isOrmlTokens(cl.metadata) {
+		pallet = "AssetRegistry"
+	}
+	keyTypes, err := storageKeyTypeIds(cl.metadata, pallet, item)
+	if err != nil {
+		return
+	}
+	assetIdArg, err := encodeAssetIdOfType(cl.metadata, keyTypes[0], t.AssetId)
+	if err != nil {
+		return
+	}
+	value, err := cl.queryStorageValue(pallet, item, network, assetIdArg)
+	if err != nil {
+		return
+	}
+	if value == nil {
+		return nil, fmt.Errorf("the metadata of asset %v not found", t.AssetId)
+	}
+	return &base.TokenInfo{
+		Name:    textOfHexValue(fieldOfValue(value, "name")),
+		Symbol:  textOfHexValue(fieldOfValue(value, "symbol")),
+		Decimal: int16(int64OfValue(fieldOfValue(value, "decimals"))),
+	}, nil
+}
+
+func (t *AssetToken) BalanceOfAddress(address string) (*base.Balance, error) {
+	publicKey, err := DecodeAddressToPublicKey(address)
+	if err != nil {
+		return base.EmptyBalance(), err
+	}
+	return t.BalanceOfPublicKey(publicKey)
+}
+
+func (t *AssetToken) BalanceOfPublicKey(publicKey string) (*base.Balance, error) {
+	balance, err := t.AssetBalanceOfPublicKey(publicKey)
+	if err != nil {
+		return base.EmptyBalance(), err
+	}
+	return &base.Balance{Total: balance.Total, Usable: balance.Usable}, nil
+}
+
+func (t *AssetToken) BalanceOfAccount(account base.Account) (*base.Balance, error) {
+	return t.BalanceOfPublicKey(account.PublicKeyHex())
+}
+
+// MARK - Asset token
+
+func (t *AssetToken) AssetBalanceOfAddress(address string) (*AssetBalance, error) {
+	publicKey, err := DecodeAddressToPublicKey(address)
+	if err != nil {
+		return nil, err
+	}
+	return t.AssetBalanceOfPublicKey(publicKey)
+}
+
+// Query the balance from `Assets.Account` or `Tokens.Accounts`
+func (t *AssetToken) AssetBalanceOfPublicKey(publicKey string) (b *AssetBalance, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	accountId, err := types.HexDecodeString(publicKey)
+	if err != nil || len(accountId) != 32 {
+		return nil, ErrPublicKey
+	}
+	cl, network, err := t.chain.storageClient()
+	if err != nil {
+		return
+	}
+	zero := big.NewInt(0)
+
+	if t.isOrmlTokens(cl.metadata) {
+		// Tokens.Accounts: (AccountId, CurrencyId) => { free, reserved, frozen }
+		keyTypes, err := storageKeyTypeIds(cl.metadata, t.Pallet, "Accounts")
+		if err != nil || len(keyTypes) != 2 {
+			return nil, fmt.Errorf("the storage %v.Accounts is not supported", t.Pallet)
+		}
+		currencyId, err := encodeAssetIdOfType(cl.metadata, keyTypes[1], t.AssetId)
+		if err != nil {
+			return nil, err
+		}
+		value, err := cl.queryStorageValue(t.Pallet, "Accounts", network, accountId, currencyId)
+		if err != nil {
+			return nil, err
+		}
+		return newAssetBalance(
+			bigIntOfValue(fieldOfValue(value, "free")),
+			bigIntOfValue(fieldOfValue(value, "reserved")),
+			bigIntOfValue(fieldOfValue(value, "frozen"))), nil
+	}
+
+	// Assets.Account: (AssetId, AccountId) => { balance, status (is_frozen in the old runtimes), ... }
+	keyTypes, err := storageKeyTypeIds(cl.metadata, t.Pallet, "Account")
+	if err != nil || len(keyTypes) != 2 {
+		return nil, fmt.Errorf("the storage %v.Account is not supported", t.Pallet)
+	}
+	assetId, err := encodeAssetIdOfType(cl.metadata, keyTypes[0], t.AssetId)
+	if err != nil {
+		return
+	}
+	value, err := cl.queryStorageValue(t.Pallet, "Account", network, assetId, accountId)
+	if err != nil {
+		return
+	}
+	balance := bigIntOfValue(fieldOfValue(value, "balance"))
+	frozen := zero
+	if status := fieldOfValue(value, "status"); (status != nil && status != "Liquid") || fieldOfValue(value, "is_frozen") == true {
+		frozen = balance
+	}
+	return newAssetBalance(balance, zero, frozen), nil
+}
+
+// Build the transaction of `Assets.transfer` or `Tokens.transfer`
+func (t *AssetToken) BuildTransferTx(receiver, amount string) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, err := getConnectedPolkaClient(t.chain.RpcUrl)
+	if err != nil {
+		return
+	}
+	if err = cl.LoadMetadataIfNotExists(); err != nil {
+		return
+	}
+	tx := &Tx{metadata: cl.metadata}
+	if t.isOrmlTokens(cl.metadata) {
+		return tx.newTokensTransferTx(t.Pallet, t.AssetId, receiver, amount)
+	}
+	return tx.newAssetsTransferTx(t.Pallet, t.AssetId, receiver, amount)
+}
+
+// The orml-tokens stores the balances in `Accounts`, the pallet-assets stores in `Account`.
+func (t *AssetToken) isOrmlTokens(metadata *types.Metadata) bool {
+	return hasStorageEntry(metadata, t.Pallet, "Accounts")
+}
+
+// Create the call `Assets.transfer`
+// @param assetId the asset id number, or the SCALE encoded asset id in hex.
+func (t *Tx) NewAssetsTransferTx(assetId, dest, amount string) (*Transaction, error) {
+	return t.newAssetsTransferTx(AssetPalletAssets, assetId, dest, amount)
+}
+
+// Create the call `Tokens.transfer` of orml-tokens
+// @param currencyId the SCALE encoded `CurrencyId` in hex.
+func (t *Tx) NewTokensTransferTx(currencyId, dest, amount string) (*Transaction, error) {
+	return t.newTokensTransferTx(AssetPalletTokens, currencyId, dest, amount)
+}
+
+func (t *Tx) newAssetsTransferTx(pallet, assetId, dest, amount string) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	callName := pallet + ".transfer"
+	return t.newAssetTransferTx(callName, map[string]string{
+		"id": assetId, "target": dest,
+	}, amount)
+}
+
+func (t *Tx) newTokensTransferTx(pallet, currencyId, dest, amount string) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	callName := pallet + ".transfer"
+	return t.newAssetTransferTx(callName, map[string]string{
+		"currency_id": currencyId, "dest": dest,
+	}, amount)
+}
+
+// @param args the asset id and the receiver, the field names are different in different pallets.
+func (t *Tx) newAssetTransferTx(callName string, args map[string]string, amount string) (*Transaction, error) {
+	amountArg, err := compactBalanceArg(amount)
+	if err != nil {
+		return nil, err
+	}
+	namedArgs := map[string]interface{}{"amount": amountArg}
+	for name, value := range args {
+		fieldType, err := typeOfCallField(t.metadata, callName, name)
+		if err != nil {
+			return nil, err
+		}
+		if name == "target" || name == "dest" {
+			namedArgs[name], err = t.accountArg(callName, name, value)
+		} else {
+			var encoded []byte
+			encoded, err = encodeAssetIdOfTypeDef(t.metadata, fieldType, value)
+			namedArgs[name] = encodedArg(encoded)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.newExtrinsicsWithNamedArgs(callName, namedArgs)
+}
+
+// The type ids of the keys of storage map.
+func storageKeyTypeIds(metadata *types.Metadata, pallet, item string) ([]int64, error) {
+	entry, err := storageEntryOfMetadata(metadata, pallet, item)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.Type.IsMap {
+		return nil, fmt.Errorf("storage %v.%v is not a map", pallet, item)
+	}
+	keyType := entry.Type.AsMap.Key.Int64()
+	if len(entry.Type.AsMap.Hashers) == 1 {
+		return []int64{keyType}, nil
+	}
+	si1Type, ok := metadata.AsMetadataV14.EfficientLookup[keyType]
+	if !ok || !si1Type.Def.IsTuple {
+		return nil, fmt.Errorf("the keys of storage %v.%v not found", pallet, item)
+	}
+	ids := make([]int64, 0, len(si1Type.Def.Tuple))
+	for _, id := range si1Type.Def.Tuple {
+		ids = append(ids, id.Int64())
+	}
+	return ids, nil
+}
+
+func encodeAssetIdOfType(metadata *types.Metadata, typeId int64, assetId string) ([]byte, error) {
+	si1Type, ok := metadata.AsMetadataV14.EfficientLookup[typeId]
+	if !ok {
+		return nil, fmt.Errorf("type %v not found", typeId)
+	}
+	return encodeAssetIdOfTypeDef(metadata, si1Type, assetId)
+}
+
+// Encode the asset id as the type declared in metadata.
+// @param assetId the asset id number, or the SCALE encoded asset id in hex which will be used directly.
+func encodeAssetIdOfTypeDef(metadata *types.Metadata, si1Type *types.Si1Type, assetId string) ([]byte, error) {
+	if strings.HasPrefix(assetId, "0x") {
+		return types.HexDecodeString(assetId)
+	}
+	value, ok := new(big.Int).SetString(assetId, 10)
+	if !ok || value.Sign() < 0 {
+		return nil, errors.New("the asset id is not a number, please use the SCALE encoded asset id in hex")
+	}
+	def := si1Type.Def
+	switch {
+	case def.IsCompact:
+		return types.EncodeToBytes(types.NewUCompact(value))
+	case def.IsComposite && len(def.Composite.Fields) == 1:
+		return encodeAssetIdOfType(metadata, def.Composite.Fields[0].Type.Int64(), assetId)
+	case def.IsPrimitive:
+		return encodePrimitive(def.Primitive.Si0TypeDefPrimitive, value)
+	}
+	return nil, errors.New("the asset id type is not a number, please use the SCALE encoded asset id in hex")
+}
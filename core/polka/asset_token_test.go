@@ -0,0 +1,56 @@
+package polka
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeAssetIdOfTypeDef(t *testing.T) {
+	metadata := mockDecoderMetadata()
+	u8Type := metadata.AsMetadataV14.EfficientLookup[0]
+	u128Type := metadata.AsMetadataV14.EfficientLookup[1]
+	compactType := metadata.AsMetadataV14.EfficientLookup[2]
+
+	data, err := encodeAssetIdOfTypeDef(metadata, u128Type, "1984")
+	require.Nil(t, err)
+	require.Equal(t, mustEncode(t, types.NewU128(*big.NewInt(1984))), data)
+
+	data, err = encodeAssetIdOfTypeDef(metadata, compactType, "1984")
+	require.Nil(t, err)
+	require.Equal(t, mustEncode(t, types.NewUCompactFromUInt(1984)), data)
+
+	// the hex asset id will be used directly
+	data, err = encodeAssetIdOfTypeDef(metadata, u8Type, "0x0081")
+	require.Nil(t, err)
+	require.Equal(t, []byte{0x00, 0x81}, data)
+
+	_, err = encodeAssetIdOfTypeDef(metadata, u8Type, "256")
+	require.NotNil(t, err)
+	_, err = encodeAssetIdOfTypeDef(metadata, u8Type, "DOT")
+	require.NotNil(t, err)
+}
+
+func TestAssetToken(t *testing.T) {
+	chain, err := NewChainWithRpc("https://polkadot-asset-hub-rpc.polkadot.io", "")
+	require.Nil(t, err)
+	// USDT
+	token := chain.AssetToken(AssetPalletAssets, "1984")
+
+	info, err := token.TokenInfo()
+	require.Nil(t, err)
+	require.Equal(t, "USDT", info.Symbol)
+	require.Equal(t, int16(6), info.Decimal)
+
+	balance, err := token.AssetBalanceOfAddress("13wNbioJt44NKrcQ5ZUrshJqP7TKzQbzZt5nhkeL4joa3PAX")
+	require.Nil(t, err)
+	t.Log(balance.JsonString())
+
+	txn, err := token.BuildTransferTx(accountCase.address0, "1000000")
+	require.Nil(t, err)
+	fee, err := chain.EstimateFeeForTransaction(txn)
+	require.Nil(t, err)
+	t.Log(fee)
+}
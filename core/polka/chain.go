@@ -23,7 +23,7 @@ type Chain struct {
 }
 
 // @param rpcUrl will be used to get metadata, query balance, estimate fee, send signed tx.
-// @param scanUrl will be used to query transaction details
+// @param scanUrl will be used to query transaction details, if it's empty, use `SearchTransactionDetail` or `FetchTransactionDetailInBlock` instead
 func NewChainWithRpc(rpcUrl, scanUrl string) (*Chain, error) {
 	return &Chain{
 		RpcUrl:  rpcUrl,
@@ -42,6 +42,13 @@ func (c *Chain) XBTCToken() *XBTCToken {
 	return &XBTCToken{chain: c}
 }
 
+// The token of pallet-assets or orml-tokens.
+// @param pallet the pallet name, e.g. AssetPalletAssets, AssetPalletTokens
+// @param assetId the asset id number, or the SCALE encoded asset id in hex (e.g. the `CurrencyId` of orml-tokens).
+func (c *Chain) AssetToken(pallet, assetId string) *AssetToken {
+	return NewAssetToken(c, pallet, assetId)
+}
+
 func (c *Chain) BalanceOfAddress(address string) (*base.Balance, error) {
 	ss58Format := base58.Decode(address)
 	if len(ss58Format) == 0 {
@@ -84,6 +91,8 @@ func (c *Chain) SendRawTransaction(signedTx string) (s string, err error) {
 	return hashString, nil
 }
 
+// The detail is queried from the scan api, it returns an error if the scanUrl is empty.
+// Without the scan api, use `FetchTransactionDetailInBlock` if the block is known, or `SearchTransactionDetail`.
 func (c *Chain) FetchTransactionDetail(hashString string) (*base.TransactionDetail, error) {
 	if c.ScanUrl == "" {
 		return nil, errors.New("Scan url is Empty.")
@@ -171,18 +180,7 @@ func (c *Chain) queryBalance(pubkey []byte) (b *base.Balance, err error) {
 		return
 	}
 
-	data := struct {
-		Nonce       uint32
-		Consumers   uint32
-		Providers   uint32
-		Sufficients uint32
-		Data        struct {
-			Free       types.U128
-			Reserved   types.U128
-			MiscFrozen types.U128
-			FeeFrozen  types.U128
-		}
-	}{}
+	data := accountInfo{}
 
 	// Ok is true if the value is not empty.
 	ok, err := client.api.RPC.State.GetStorageLatest(call, &data)
@@ -193,12 +191,30 @@ func (c *Chain) queryBalance(pubkey []byte) (b *base.Balance, err error) {
 		return
 	}
 
-	totalInt := big.NewInt(0).Add(data.Data.Free.Int, data.Data.Reserved.Int)
-	locked := base.MaxBigInt(data.Data.MiscFrozen.Int, data.Data.FeeFrozen.Int)
-	usableInt := big.NewInt(0).Sub(data.Data.Free.Int, locked)
+	return data.balance(), nil
+}
+
+// The value of storage `System.Account`
+type accountInfo struct {
+	Nonce       uint32
+	Consumers   uint32
+	Providers   uint32
+	Sufficients uint32
+	Data        struct {
+		Free       types.U128
+		Reserved   types.U128
+		MiscFrozen types.U128
+		FeeFrozen  types.U128
+	}
+}
+
+func (a *accountInfo) balance() *base.Balance {
+	totalInt := big.NewInt(0).Add(a.Data.Free.Int, a.Data.Reserved.Int)
+	locked := base.MaxBigInt(a.Data.MiscFrozen.Int, a.Data.FeeFrozen.Int)
+	usableInt := big.NewInt(0).Sub(a.Data.Free.Int, locked)
 
 	return &base.Balance{
 		Total:  totalInt.String(),
 		Usable: usableInt.String(),
-	}, nil
+	}
 }
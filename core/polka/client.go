@@ -12,6 +12,9 @@ type polkaclient struct {
 	api      *gsrpc.SubstrateAPI
 	metadata *types.Metadata
 	rpcUrl   string
+
+	// nil if the properties has not been loaded
+	ss58Format *int
 }
 
 func newPolkaClient(rpcUrl, metadataString string) (*polkaclient, error) {
@@ -71,6 +74,30 @@ func (c *polkaclient) MetadataString() (string, error) {
 	return types.EncodeToHexString(c.metadata)
 }
 
+// The ss58 format declared in the chain properties, default is 42.
+func (c *polkaclient) SS58Format() (int, error) {
+	if c.ss58Format != nil {
+		return *c.ss58Format, nil
+	}
+	err := c.connectApiIfNeeded()
+	if err != nil {
+		return 0, err
+	}
+	properties := struct {
+		SS58Format *int `json:"ss58Format"`
+	}{}
+	err = c.api.Client.Call(&properties, "system_properties")
+	if err != nil {
+		return 0, base.MapAnyToBasicError(err)
+	}
+	format := 42
+	if properties.SS58Format != nil {
+		format = *properties.SS58Format
+	}
+	c.ss58Format = &format
+	return format, nil
+}
+
 // MARK: - client manager
 
 var clientConnections = make(map[string]*polkaclient)
@@ -32,6 +32,7 @@ var (
 
 	ErrPassword = errors.New("password err")
 
-	ErrNumber = errors.New("illegal number")
-	ErrSign   = errors.New("sign panic error")
+	ErrNumber   = errors.New("illegal number")
+	ErrEraBlock = errors.New("the block of mortal era is invalid")
+	ErrSign     = errors.New("sign panic error")
 )
@@ -2,18 +2,59 @@ package polka
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/centrifuge/go-substrate-rpc-client/v4/client"
 	"github.com/coming-chat/wallet-SDK/core/base"
 )
 
 func (c *Chain) EstimateFeeForTransaction(transaction *Transaction) (s string, err error) {
+	return c.EstimateFeeForTransactionWithOptions(transaction, nil)
+}
+
+// The tip and the era will change the length of the transaction, so the fee should be estimated with the same options as signing.
+// @param options nil means immortal with zero tip
+func (c *Chain) EstimateFeeForTransactionWithOptions(transaction *Transaction, options *SignOptions) (s string, err error) {
 	defer base.CatchPanicAndMapToBasicError(&err)
 	s = "0"
 
+	data, err := c.queryInfoOfTransaction(transaction, options)
+	if err != nil {
+		return
+	}
+	estimateFee, ok := data["partialFee"].(string)
+	if !ok {
+		return s, errors.New("get estimated fee result nil")
+	}
+
+	return estimateFee, nil
+}
+
+// Query the dispatch info of the transaction with `payment_queryInfo`, the transaction is signed by a mock account.
+func (c *Chain) queryInfoOfTransaction(transaction *Transaction, options *SignOptions) (data map[string]interface{}, err error) {
+	cl, err := getConnectedPolkaClient(c.RpcUrl)
+	if err != nil {
+		return
+	}
+
 	account := mockAccount()
 	fakeHash := "0x38c5a9f6fabb8d8583ed633c469cdeefb988b0d2384937b15e10e9c0a75aa744"
-	signData, err := transaction.GetSignData(fakeHash, 0, 0, 0)
+	if options != nil && options.EraPeriod > 0 {
+		// The era block does not affect the fee
+		fakeOptions := *options
+		fakeOptions.BlockNumber = 0
+		fakeOptions.BlockHash = fakeHash
+		options = &fakeOptions
+	}
+	// Signing a copy, so the original transaction will keep unsigned.
+	mockTransaction := transaction.copy()
+	if mockTransaction.metadata == nil {
+		if err = cl.LoadMetadataIfNotExists(); err != nil {
+			return
+		}
+		mockTransaction.metadata = cl.metadata
+	}
+	signData, err := mockTransaction.GetSignDataWithOptions(fakeHash, 0, 0, 0, options)
 	if err != nil {
 		return
 	}
@@ -21,22 +62,49 @@ func (c *Chain) EstimateFeeForTransaction(transaction *Transaction) (s string, e
 	if err != nil {
 		return
 	}
-	sendTx, err := transaction.GetTx(account.PublicKey(), signature)
+	sendTx, err := mockTransaction.GetTx(account.PublicKey(), signature)
 	if err != nil {
 		return
 	}
 
-	cl, err := getConnectedPolkaClient(c.RpcUrl)
-	data := make(map[string]interface{})
+	data = make(map[string]interface{})
 	err = client.CallWithBlockHash(cl.api.Client, &data, "payment_queryInfo", nil, sendTx)
+	return data, err
+}
+
+// Estimate the weight of the transaction's call.
+// @return refTime, proofSize; the proofSize is 0 if the chain is using weight v1.
+func (c *Chain) estimateWeightOfTransaction(transaction *Transaction) (refTime, proofSize uint64, err error) {
+	data, err := c.queryInfoOfTransaction(transaction, nil)
 	if err != nil {
 		return
 	}
-
-	estimateFee, ok := data["partialFee"].(string)
-	if !ok {
-		return s, errors.New("get estimated fee result nil")
+	parse := func(value interface{}) uint64 {
+		switch v := value.(type) {
+		case float64:
+			return uint64(v)
+		case string:
+			n, _ := strconv.ParseUint(v, 10, 64)
+			return n
+		}
+		return 0
 	}
-
-	return estimateFee, nil
+	switch weight := data["weight"].(type) {
+	case map[string]interface{}:
+		for _, key := range []string{"ref_time", "refTime"} {
+			if v, ok := weight[key]; ok {
+				refTime = parse(v)
+			}
+		}
+		for _, key := range []string{"proof_size", "proofSize"} {
+			if v, ok := weight[key]; ok {
+				proofSize = parse(v)
+			}
+		}
+	case nil:
+		return 0, 0, errors.New("get estimated weight result nil")
+	default:
+		refTime = parse(weight)
+	}
+	return refTime, proofSize, nil
 }
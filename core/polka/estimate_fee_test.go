@@ -1,6 +1,11 @@
 package polka
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
 
 func TestChain_EstimateFeeForTransaction(t *testing.T) {
 	type transactionCreator func(tx *Tx) (*Transaction, error)
@@ -9,6 +14,14 @@ func TestChain_EstimateFeeForTransaction(t *testing.T) {
 			return tx.NewBalanceTransferTx(address, amount)
 		}
 	}
+	remarks := func(tx *Tx, count int) *base.AnyArray {
+		arr := base.NewAnyArray()
+		for i := 0; i < count; i++ {
+			remark, _ := tx.NewExtrinsics("System.remark", types.NewBytes([]byte("wallet-SDK")))
+			arr.Append(remark.AsAny())
+		}
+		return arr
+	}
 	tests := []struct {
 		name    string
 		rpcinfo rpcInfo
@@ -70,6 +83,36 @@ func TestChain_EstimateFeeForTransaction(t *testing.T) {
 			rpcinfo: rpcs.kusama,
 			creator: balanceCreator(accountCase.address2, "1000"),
 		},
+		{
+			name:    "polkadot prod batch all",
+			rpcinfo: rpcs.polkadot,
+			creator: func(tx *Tx) (*Transaction, error) {
+				return tx.NewUtilityBatchAllTx(remarks(tx, 3))
+			},
+		},
+		{
+			name:    "polkadot prod proxy",
+			rpcinfo: rpcs.polkadot,
+			creator: func(tx *Tx) (*Transaction, error) {
+				remark, _ := tx.NewExtrinsics("System.remark", types.NewBytes([]byte("wallet-SDK")))
+				return tx.NewProxyTx(accountCase.address0, "Any", remark)
+			},
+		},
+		{
+			name:    "polkadot prod add proxy",
+			rpcinfo: rpcs.polkadot,
+			creator: func(tx *Tx) (*Transaction, error) {
+				return tx.NewAddProxyTx(accountCase.address0, "NonTransfer", 0)
+			},
+		},
+		{
+			name:    "polkadot prod error proxy type",
+			rpcinfo: rpcs.polkadot,
+			creator: func(tx *Tx) (*Transaction, error) {
+				return tx.NewAddProxyTx(accountCase.address0, "NotExistsType", 0)
+			},
+			wantErr: true,
+		},
 		{
 			name:    "error amount",
 			rpcinfo: rpcs.minixProd,
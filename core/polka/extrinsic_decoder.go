@@ -0,0 +1,558 @@
+package polka
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+type DecodedExtrinsic struct {
+	Signed bool `json:"signed"`
+	// The ss58 address of the signer, empty if the extrinsic is unsigned
+	Signer string          `json:"signer"`
+	Era    *DecodedEra     `json:"era"`
+	Nonce  int64           `json:"nonce"`
+	Tip    *DecodedBalance `json:"tip"`
+	// The asset used to pay the fee if the chain has the signed extension `ChargeAssetTxPayment`, nil means native token.
+	FeeAssetId interface{} `json:"feeAssetId"`
+
+	Call *DecodedCall `json:"call"`
+}
+
+type DecodedEra struct {
+	Immortal bool  `json:"immortal"`
+	Period   int64 `json:"period"`
+	Phase    int64 `json:"phase"`
+}
+
+type DecodedCall struct {
+	Pallet string            `json:"pallet"`
+	Call   string            `json:"call"`
+	Args   []*DecodedCallArg `json:"args"`
+}
+
+type DecodedCallArg struct {
+	Name string `json:"name"`
+	// The type name declared in the metadata, e.g. `T::Balance`, `AccountIdLookupOf<T>`
+	Type string `json:"type"`
+	// The value may be: string, number, bool, nil, address string, *DecodedBalance, *DecodedCall,
+	// hex string of bytes, array or map of the values above.
+	Value interface{} `json:"value"`
+}
+
+type DecodedBalance struct {
+	// The origin integer
+	Value string `json:"value"`
+	// The value formatted with the decimals, e.g. "1.5"
+	Formatted string `json:"formatted"`
+}
+
+func (d *DecodedExtrinsic) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(d)
+}
+
+func NewDecodedExtrinsicWithJsonString(str string) (*DecodedExtrinsic, error) {
+	var o DecodedExtrinsic
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+// Decode an extrinsic to be human-readable, it can be used to show the detail to user before signing.
+// Nested calls, e.g. `Utility.batch`, `Proxy.proxy`, `Multisig.as_multi`, will be decoded too.
+// @param txHex unsigned or signed extrinsic hex, e.g. the result of `Transaction.GetUnSignTx()`
+// @param decimals the decimals of the native token, used to format the balances
+// @param network the ss58 format of the chain, used to encode the addresses
+func (t *Tx) DecodeExtrinsic(txHex string, decimals int, network int) (e *DecodedExtrinsic, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	if t.metadata.Version != 14 {
+		return nil, errors.New("only metadata v14 is supported")
+	}
+	data, err := types.HexDecodeString(txHex)
+	if err != nil {
+		return nil, err
+	}
+	decoder := newExtrinsicDecoder(t.metadata, decimals, network)
+	return decoder.decodeExtrinsic(data)
+}
+
+func (t *Tx) DecodeExtrinsicJson(txHex string, decimals int, network int) (*base.OptionalString, error) {
+	extrinsic, err := t.DecodeExtrinsic(txHex, decimals, network)
+	if err != nil {
+		return nil, err
+	}
+	return extrinsic.JsonString()
+}
+
+type extrinsicDecoder struct {
+	metadata *types.Metadata
+	decimals int
+	network  int
+
+	// The type id of the runtime call, -1 if not found
+	callTypeId int64
+	// The reader of the extrinsic being decoded, it's used to check the lengths read from the data.
+	reader *bytes.Reader
+}
+
+func newExtrinsicDecoder(metadata *types.Metadata, decimals int, network int) *extrinsicDecoder {
+	return &extrinsicDecoder{
+		metadata:   metadata,
+		decimals:   decimals,
+		network:    network,
+		callTypeId: runtimeCallTypeId(metadata),
+	}
+}
+
+// The runtime call is an enum that each variant wraps the call enum of a pallet.
+func runtimeCallTypeId(metadata *types.Metadata) int64 {
+	palletCallTypes := make(map[int64]string)
+	for _, pallet := range metadata.AsMetadataV14.Pallets {
+		if pallet.HasCalls {
+			palletCallTypes[pallet.Calls.Type.Int64()] = string(pallet.Name)
+		}
+	}
+	for _, lookupType := range metadata.AsMetadataV14.Lookup.Types {
+		def := lookupType.Type.Def
+		if !def.IsVariant || len(def.Variant.Variants) == 0 {
+			continue
+		}
+		isCall := true
+		for _, variant := range def.Variant.Variants {
+			if len(variant.Fields) != 1 || palletCallTypes[variant.Fields[0].Type.Int64()] != string(variant.Name) {
+				isCall = false
+				break
+			}
+		}
+		if isCall {
+			return lookupType.ID.Int64()
+		}
+	}
+	return -1
+}
+
+func (d *extrinsicDecoder) decodeExtrinsic(data []byte) (*DecodedExtrinsic, error) {
+	d.reader = bytes.NewReader(data)
+	decoder := scale.NewDecoder(d.reader)
+	// length prefix
+	if _, err := decoder.DecodeUintCompact(); err != nil {
+		return nil, err
+	}
+	version, err := decoder.ReadOneByte()
+	if err != nil {
+		return nil, err
+	}
+	if version&0x7f != types.ExtrinsicVersion4 {
+		return nil, fmt.Errorf("unsupported extrinsic version: %v", version&0x7f)
+	}
+
+	extrinsic := &DecodedExtrinsic{
+		Signed: version&types.ExtrinsicBitSigned != 0,
+		Era:    &DecodedEra{Immortal: true},
+		Tip:    d.balance(big.NewInt(0)),
+	}
+	if extrinsic.Signed {
+		var signer types.MultiAddress
+		var signature types.MultiSignature
+		if err = decoder.Decode(&signer); err != nil {
+			return nil, err
+		}
+		if err = decoder.Decode(&signature); err != nil {
+			return nil, err
+		}
+		extrinsic.Signer, err = d.multiAddressString(signer)
+		if err != nil {
+			return nil, err
+		}
+		if err = d.decodeSignedExtensions(decoder, extrinsic); err != nil {
+			return nil, err
+		}
+	}
+	extrinsic.Call, err = d.decodeCall(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if d.reader.Len() != 0 {
+		return nil, fmt.Errorf("%v bytes are left after decoding the extrinsic", d.reader.Len())
+	}
+	return extrinsic, nil
+}
+
+// Decode a single value of the type, e.g. a storage value.
+func (d *extrinsicDecoder) decodeValue(data []byte, typeId int64) (interface{}, error) {
+	d.reader = bytes.NewReader(data)
+	return d.decodeType(scale.NewDecoder(d.reader), typeId, "")
+}
+
+func (d *extrinsicDecoder) decodeSignedExtensions(decoder *scale.Decoder, extrinsic *DecodedExtrinsic) error {
+	for _, ext := range signedExtensionsOfMetadata(d.metadata) {
+		switch ext.identifier {
+		case "CheckMortality", "CheckEra":
+			var era types.ExtrinsicEra
+			if err := decoder.Decode(&era); err != nil {
+				return err
+			}
+			extrinsic.Era = decodedEra(era)
+		case "CheckNonce":
+			var nonce types.UCompact
+			if err := decoder.Decode(&nonce); err != nil {
+				return err
+			}
+			bigNonce := big.Int(nonce)
+			extrinsic.Nonce = bigNonce.Int64()
+		case "ChargeTransactionPayment":
+			var tip types.UCompact
+			if err := decoder.Decode(&tip); err != nil {
+				return err
+			}
+			bigTip := big.Int(tip)
+			extrinsic.Tip = d.balance(&bigTip)
+		default:
+			if ext.metadata == nil {
+				continue
+			}
+			value, err := d.decodeType(decoder, ext.metadata.Type.Int64(), "")
+			if err != nil {
+				return fmt.Errorf("decode signed extension %v failed: %v", ext.identifier, err)
+			}
+			if ext.identifier == "ChargeAssetTxPayment" {
+				if fields, ok := value.(map[string]interface{}); ok {
+					if tip, ok := fields["tip"].(*DecodedBalance); ok {
+						extrinsic.Tip = tip
+					}
+					extrinsic.FeeAssetId = fields["asset_id"]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func decodedEra(era types.ExtrinsicEra) *DecodedEra {
+	if era.IsImmortalEra {
+		return &DecodedEra{Immortal: true}
+	}
+	encoded := uint64(era.AsMortalEra.First) | uint64(era.AsMortalEra.Second)<<8
+	period := uint64(2) << (encoded % 16)
+	quantizeFactor := period >> 12
+	if quantizeFactor < 1 {
+		quantizeFactor = 1
+	}
+	return &DecodedEra{
+		Period: int64(period),
+		Phase:  int64((encoded >> 4) * quantizeFactor),
+	}
+}
+
+func (d *extrinsicDecoder) decodeCall(decoder *scale.Decoder) (*DecodedCall, error) {
+	var index types.CallIndex
+	if err := decoder.Decode(&index); err != nil {
+		return nil, err
+	}
+	for _, pallet := range d.metadata.AsMetadataV14.Pallets {
+		if !pallet.HasCalls || uint8(pallet.Index) != index.SectionIndex {
+			continue
+		}
+		callType, ok := d.metadata.AsMetadataV14.EfficientLookup[pallet.Calls.Type.Int64()]
+		if !ok {
+			break
+		}
+		for _, variant := range callType.Def.Variant.Variants {
+			if uint8(variant.Index) != index.MethodIndex {
+				continue
+			}
+			call := &DecodedCall{
+				Pallet: string(pallet.Name),
+				Call:   string(variant.Name),
+				Args:   make([]*DecodedCallArg, 0, len(variant.Fields)),
+			}
+			for _, field := range variant.Fields {
+				value, err := d.decodeType(decoder, field.Type.Int64(), string(field.TypeName))
+				if err != nil {
+					return nil, fmt.Errorf("decode %v.%v argument %v failed: %v", call.Pallet, call.Call, field.Name, err)
+				}
+				call.Args = append(call.Args, &DecodedCallArg{
+					Name:  string(field.Name),
+					Type:  string(field.TypeName),
+					Value: value,
+				})
+			}
+			return call, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find the call with index %v-%v", index.SectionIndex, index.MethodIndex)
+}
+
+// @param typeName the type name of the field, it's used to find out the balances.
+func (d *extrinsicDecoder) decodeType(decoder *scale.Decoder, typeId int64, typeName string) (interface{}, error) {
+	if typeId == d.callTypeId {
+		return d.decodeCall(decoder)
+	}
+	si1Type, ok := d.metadata.AsMetadataV14.EfficientLookup[typeId]
+	if !ok {
+		return nil, fmt.Errorf("type %v not found", typeId)
+	}
+	path := si1Type.Path
+	lastPath := ""
+	if len(path) > 0 {
+		lastPath = string(path[len(path)-1])
+	}
+
+	def := si1Type.Def
+	switch {
+	case lastPath == "AccountId32":
+		var account [32]byte
+		if err := decoder.Read(account[:]); err != nil {
+			return nil, err
+		}
+		return EncodePublicKeyToAddress(types.HexEncodeToString(account[:]), d.network)
+	case lastPath == "AccountId20":
+		var account [20]byte
+		if err := decoder.Read(account[:]); err != nil {
+			return nil, err
+		}
+		return types.HexEncodeToString(account[:]), nil
+
+	case def.IsPrimitive:
+		return d.decodePrimitive(decoder, def.Primitive.Si0TypeDefPrimitive, typeName)
+	case def.IsCompact:
+		value, err := decoder.DecodeUintCompact()
+		if err != nil {
+			return nil, err
+		}
+		if isBalanceTypeName(typeName) {
+			return d.balance(value), nil
+		}
+		return value.String(), nil
+
+	case def.IsSequence:
+		length, err := decoder.DecodeUintCompact()
+		if err != nil {
+			return nil, err
+		}
+		if !length.IsUint64() || length.Uint64() > uint64(d.reader.Len()) {
+			return nil, fmt.Errorf("the sequence length %v exceeds the remaining %v bytes", length, d.reader.Len())
+		}
+		return d.decodeList(decoder, def.Sequence.Type.Int64(), int(length.Uint64()), typeName)
+	case def.IsArray:
+		return d.decodeList(decoder, def.Array.Type.Int64(), int(def.Array.Len), typeName)
+	case def.IsTuple:
+		values := make([]interface{}, 0, len(def.Tuple))
+		for _, itemType := range def.Tuple {
+			value, err := d.decodeType(decoder, itemType.Int64(), "")
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+
+	case def.IsComposite:
+		return d.decodeFields(decoder, def.Composite.Fields, typeName)
+	case def.IsVariant:
+		index, err := decoder.ReadOneByte()
+		if err != nil {
+			return nil, err
+		}
+		for _, variant := range def.Variant.Variants {
+			if uint8(variant.Index) != index {
+				continue
+			}
+			if lastPath == "Option" {
+				if len(variant.Fields) == 0 {
+					return nil, nil
+				}
+				return d.decodeType(decoder, variant.Fields[0].Type.Int64(), typeName)
+			}
+			if len(variant.Fields) == 0 {
+				return string(variant.Name), nil
+			}
+			value, err := d.decodeFields(decoder, variant.Fields, typeName)
+			if err != nil {
+				return nil, err
+			}
+			if lastPath == "MultiAddress" && variant.Name == "Id" {
+				return value, nil
+			}
+			return map[string]interface{}{string(variant.Name): value}, nil
+		}
+		return nil, fmt.Errorf("variant index %v of type %v not found", index, typeId)
+	}
+	return nil, fmt.Errorf("type %v is not supported", typeId)
+}
+
+// Fields with names will be decoded to a map, otherwise an array, a single unnamed field will be unwrapped.
+func (d *extrinsicDecoder) decodeFields(decoder *scale.Decoder, fields []types.Si1Field, typeName string) (interface{}, error) {
+	if len(fields) == 1 && !fields[0].HasName {
+		if fields[0].HasTypeName {
+			typeName = string(fields[0].TypeName)
+		}
+		return d.decodeType(decoder, fields[0].Type.Int64(), typeName)
+	}
+	if len(fields) > 0 && fields[0].HasName {
+		values := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			value, err := d.decodeType(decoder, field.Type.Int64(), string(field.TypeName))
+			if err != nil {
+				return nil, err
+			}
+			values[string(field.Name)] = value
+		}
+		return values, nil
+	}
+	values := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		value, err := d.decodeType(decoder, field.Type.Int64(), string(field.TypeName))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// The bytes will be decoded to hex string.
+// The length is checked against the remaining data before allocating, every item takes at least one byte in practice.
+func (d *extrinsicDecoder) decodeList(decoder *scale.Decoder, itemTypeId int64, length int, typeName string) (interface{}, error) {
+	if length > d.reader.Len() {
+		return nil, fmt.Errorf("the list length %v exceeds the remaining %v bytes", length, d.reader.Len())
+	}
+	if itemType, ok := d.metadata.AsMetadataV14.EfficientLookup[itemTypeId]; ok &&
+		itemType.Def.IsPrimitive && itemType.Def.Primitive.Si0TypeDefPrimitive == types.IsU8 {
+		data := make([]byte, length)
+		if err := decoder.Read(data); err != nil {
+			return nil, err
+		}
+		return types.HexEncodeToString(data), nil
+	}
+	values := []interface{}{}
+	for i := 0; i < length; i++ {
+		value, err := d.decodeType(decoder, itemTypeId, typeName)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (d *extrinsicDecoder) decodePrimitive(decoder *scale.Decoder, primitive types.Si0TypeDefPrimitive, typeName string) (interface{}, error) {
+	var size int
+	signed := false
+	switch primitive {
+	case types.IsBool:
+		var value bool
+		err := decoder.Decode(&value)
+		return value, err
+	case types.IsStr:
+		var value string
+		err := decoder.Decode(&value)
+		return value, err
+	case types.IsChar:
+		var value uint32
+		err := decoder.Decode(&value)
+		return string(rune(value)), err
+	case types.IsU8:
+		size = 1
+	case types.IsU16:
+		size = 2
+	case types.IsU32:
+		size = 4
+	case types.IsU64:
+		size = 8
+	case types.IsU128:
+		size = 16
+	case types.IsU256:
+		size = 32
+	case types.IsI8:
+		size, signed = 1, true
+	case types.IsI16:
+		size, signed = 2, true
+	case types.IsI32:
+		size, signed = 4, true
+	case types.IsI64:
+		size, signed = 8, true
+	case types.IsI128:
+		size, signed = 16, true
+	case types.IsI256:
+		size, signed = 32, true
+	default:
+		return nil, fmt.Errorf("primitive type %v is not supported", primitive)
+	}
+
+	data := make([]byte, size)
+	if err := decoder.Read(data); err != nil {
+		return nil, err
+	}
+	// little endian to big endian
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+	value := new(big.Int).SetBytes(data)
+	if signed && data[0]&0x80 != 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+	}
+	if isBalanceTypeName(typeName) {
+		return d.balance(value), nil
+	}
+	if size <= 4 {
+		return value.Int64(), nil
+	}
+	// large numbers are returned as string to avoid losing precision in json
+	return value.String(), nil
+}
+
+func isBalanceTypeName(typeName string) bool {
+	return strings.Contains(typeName, "Balance")
+}
+
+func (d *extrinsicDecoder) balance(value *big.Int) *DecodedBalance {
+	return &DecodedBalance{
+		Value:     value.String(),
+		Formatted: formatBalance(value, d.decimals),
+	}
+}
+
+func (d *extrinsicDecoder) multiAddressString(address types.MultiAddress) (string, error) {
+	switch {
+	case address.IsID:
+		return EncodePublicKeyToAddress(types.HexEncodeToString(address.AsID[:]), d.network)
+	case address.IsAddress32:
+		return EncodePublicKeyToAddress(types.HexEncodeToString(address.AsAddress32[:]), d.network)
+	case address.IsAddress20:
+		return types.HexEncodeToString(address.AsAddress20[:]), nil
+	case address.IsIndex:
+		return fmt.Sprintf("index:%v", address.AsIndex), nil
+	case address.IsRaw:
+		return types.HexEncodeToString(address.AsRaw), nil
+	}
+	return "", ErrAddress
+}
+
+// e.g. formatBalance(15000000000, 10) = "1.5"
+func formatBalance(value *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return value.String()
+	}
+	str := new(big.Int).Abs(value).String()
+	if len(str) <= decimals {
+		str = strings.Repeat("0", decimals-len(str)+1) + str
+	}
+	integer, fraction := str[:len(str)-decimals], strings.TrimRight(str[len(str)-decimals:], "0")
+	if fraction != "" {
+		integer += "." + fraction
+	}
+	if value.Sign() < 0 {
+		integer = "-" + integer
+	}
+	return integer
+}
@@ -0,0 +1,191 @@
+package polka
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/stretchr/testify/require"
+)
+
+// A tiny metadata contains `Balances.transfer` and `Utility.batch`
+func mockDecoderMetadata() *types.Metadata {
+	field := func(name string, typeId int64, typeName string) types.Si1Field {
+		return types.Si1Field{
+			HasName: name != "", Name: types.Text(name),
+			Type:        types.NewSi1LookupTypeIDFromUInt(uint64(typeId)),
+			HasTypeName: typeName != "", TypeName: types.Text(typeName),
+		}
+	}
+	variant := func(name string, index uint8, fields ...types.Si1Field) types.Si1Variant {
+		return types.Si1Variant{Name: types.Text(name), Index: types.NewU8(index), Fields: fields}
+	}
+	path := func(paths ...string) types.Si1Path {
+		res := types.Si1Path{}
+		for _, p := range paths {
+			res = append(res, types.Text(p))
+		}
+		return res
+	}
+	typeDefs := []types.Si1Type{
+		0: {Def: types.Si1TypeDef{IsPrimitive: true, Primitive: types.Si1TypeDefPrimitive{Si0TypeDefPrimitive: types.IsU8}}},
+		1: {Def: types.Si1TypeDef{IsPrimitive: true, Primitive: types.Si1TypeDefPrimitive{Si0TypeDefPrimitive: types.IsU128}}},
+		2: {Def: types.Si1TypeDef{IsCompact: true, Compact: types.Si1TypeDefCompact{Type: types.NewSi1LookupTypeIDFromUInt(1)}}},
+		3: {Path: path("sp_core", "crypto", "AccountId32"), Def: types.Si1TypeDef{IsComposite: true, Composite: types.Si1TypeDefComposite{Fields: []types.Si1Field{field("", 4, "[u8; 32]")}}}},
+		4: {Def: types.Si1TypeDef{IsArray: true, Array: types.Si1TypeDefArray{Len: 32, Type: types.NewSi1LookupTypeIDFromUInt(0)}}},
+		5: {Path: path("sp_runtime", "multiaddress", "MultiAddress"), Def: types.Si1TypeDef{IsVariant: true, Variant: types.Si1TypeDefVariant{Variants: []types.Si1Variant{
+			variant("Id", 0, field("", 3, "AccountId")),
+		}}}},
+		6: {Path: path("pallet_balances", "pallet", "Call"), Def: types.Si1TypeDef{IsVariant: true, Variant: types.Si1TypeDefVariant{Variants: []types.Si1Variant{
+			variant("transfer", 0, field("dest", 5, "AccountIdLookupOf<T>"), field("value", 2, "T::Balance")),
+		}}}},
+		7: {Path: path("node_runtime", "RuntimeCall"), Def: types.Si1TypeDef{IsVariant: true, Variant: types.Si1TypeDefVariant{Variants: []types.Si1Variant{
+			variant("Utility", 1, field("", 9, "")),
+			variant("Balances", 5, field("", 6, "")),
+		}}}},
+		8: {Def: types.Si1TypeDef{IsSequence: true, Sequence: types.Si1TypeDefSequence{Type: types.NewSi1LookupTypeIDFromUInt(7)}}},
+		9: {Path: path("pallet_utility", "pallet", "Call"), Def: types.Si1TypeDef{IsVariant: true, Variant: types.Si1TypeDefVariant{Variants: []types.Si1Variant{
+			variant("batch", 0, field("calls", 8, "Vec<<T as Config>::RuntimeCall>")),
+		}}}},
+	}
+
+	metadata := types.NewMetadataV14()
+	v14 := &metadata.AsMetadataV14
+	v14.EfficientLookup = make(map[int64]*types.Si1Type)
+	for i := range typeDefs {
+		v14.Lookup.Types = append(v14.Lookup.Types, types.PortableTypeV14{ID: types.NewSi1LookupTypeIDFromUInt(uint64(i)), Type: typeDefs[i]})
+		v14.EfficientLookup[int64(i)] = &typeDefs[i]
+	}
+	v14.Pallets = []types.PalletMetadataV14{
+		{Name: "Utility", Index: 1, HasCalls: true, Calls: types.FunctionMetadataV14{Type: types.NewSi1LookupTypeIDFromUInt(9)}},
+		{Name: "Balances", Index: 5, HasCalls: true, Calls: types.FunctionMetadataV14{Type: types.NewSi1LookupTypeIDFromUInt(6)}},
+	}
+	for _, name := range []string{"CheckMortality", "CheckNonce", "ChargeTransactionPayment"} {
+		v14.Extrinsic.SignedExtensions = append(v14.Extrinsic.SignedExtensions, types.SignedExtensionMetadataV14{Identifier: types.Text(name)})
+	}
+	return metadata
+}
+
+func TestTx_DecodeExtrinsic(t *testing.T) {
+	tx := &Tx{metadata: mockDecoderMetadata()}
+	publicKey := "0x" + "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"
+	account, _ := types.HexDecodeString(publicKey)
+	address, err := EncodePublicKeyToAddress(publicKey, 42)
+	require.Nil(t, err)
+
+	transfer := types.Call{
+		CallIndex: types.CallIndex{SectionIndex: 5, MethodIndex: 0},
+		Args:      append(append([]byte{0}, account...), mustEncode(t, types.NewUCompact(big.NewInt(15000000000)))...),
+	}
+	batch := types.Call{
+		CallIndex: types.CallIndex{SectionIndex: 1, MethodIndex: 0},
+		Args:      mustEncode(t, []types.Call{transfer, transfer}),
+	}
+	extrinsic := types.NewExtrinsic(batch)
+	transaction := &Transaction{extrinsic: &extrinsic, metadata: tx.metadata}
+	_, err = transaction.GetSignDataWithOptions(publicKey, 3, 1, 1, &SignOptions{Tip: "100", EraPeriod: 64, BlockNumber: 42, BlockHash: publicKey})
+	require.Nil(t, err)
+	txHex, err := transaction.GetTx(account, make([]byte, 64))
+	require.Nil(t, err)
+
+	decoded, err := tx.DecodeExtrinsic(txHex, 10, 42)
+	require.Nil(t, err)
+	require.True(t, decoded.Signed)
+	require.Equal(t, address, decoded.Signer)
+	require.Equal(t, int64(3), decoded.Nonce)
+	require.Equal(t, "100", decoded.Tip.Value)
+	require.Equal(t, &DecodedEra{Period: 64, Phase: 42}, decoded.Era)
+
+	require.Equal(t, "Utility", decoded.Call.Pallet)
+	require.Equal(t, "batch", decoded.Call.Call)
+	calls := decoded.Call.Args[0].Value.([]interface{})
+	require.Equal(t, 2, len(calls))
+	inner := calls[1].(*DecodedCall)
+	require.Equal(t, "Balances", inner.Pallet)
+	require.Equal(t, "transfer", inner.Call)
+	require.Equal(t, address, inner.Args[0].Value)
+	require.Equal(t, &DecodedBalance{Value: "15000000000", Formatted: "1.5"}, inner.Args[1].Value)
+
+	jsonString, err := decoded.JsonString()
+	require.Nil(t, err)
+	require.Contains(t, jsonString.Value, `"call":{"pallet":"Utility","call":"batch","args":[{"name":"calls"`)
+	require.Contains(t, jsonString.Value, `"tip":{"value":"100","formatted":"0.00000001"}`)
+	fromJson, err := NewDecodedExtrinsicWithJsonString(jsonString.Value)
+	require.Nil(t, err)
+	require.Equal(t, decoded.Signer, fromJson.Signer)
+	require.Equal(t, decoded.Era, fromJson.Era)
+
+	unsignedHex, err := types.EncodeToHexString(types.NewExtrinsic(transfer))
+	require.Nil(t, err)
+	decoded, err = tx.DecodeExtrinsic(unsignedHex, 10, 42)
+	require.Nil(t, err)
+	require.False(t, decoded.Signed)
+	require.Equal(t, "transfer", decoded.Call.Call)
+}
+
+func TestTx_DecodeExtrinsic_Invalid(t *testing.T) {
+	tx := &Tx{metadata: mockDecoderMetadata()}
+	publicKey := "0x" + "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"
+	account, _ := types.HexDecodeString(publicKey)
+	transfer := types.Call{
+		CallIndex: types.CallIndex{SectionIndex: 5, MethodIndex: 0},
+		Args:      append(append([]byte{0}, account...), mustEncode(t, types.NewUCompact(big.NewInt(15000000000)))...),
+	}
+	unsignedHex, err := types.EncodeToHexString(types.NewExtrinsic(transfer))
+	require.Nil(t, err)
+
+	// trailing bytes
+	_, err = tx.DecodeExtrinsic(unsignedHex+"00", 10, 42)
+	require.NotNil(t, err)
+
+	// Utility.batch with a huge length of calls
+	batch := types.Call{
+		CallIndex: types.CallIndex{SectionIndex: 1, MethodIndex: 0},
+		Args:      mustEncode(t, types.NewUCompact(new(big.Int).Lsh(big.NewInt(1), 60))),
+	}
+	batchHex, err := types.EncodeToHexString(types.NewExtrinsic(batch))
+	require.Nil(t, err)
+	_, err = tx.DecodeExtrinsic(batchHex, 10, 42)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "exceeds")
+}
+
+func TestFormatBalance(t *testing.T) {
+	require.Equal(t, "1.5", formatBalance(big.NewInt(15000000000), 10))
+	require.Equal(t, "0.0000000001", formatBalance(big.NewInt(1), 10))
+	require.Equal(t, "0", formatBalance(big.NewInt(0), 10))
+	require.Equal(t, "-20", formatBalance(big.NewInt(-2000), 2))
+	require.Equal(t, "123", formatBalance(big.NewInt(123), 0))
+}
+
+func mustEncode(t *testing.T, value interface{}) []byte {
+	data, err := types.EncodeToBytes(value)
+	require.Nil(t, err)
+	return data
+}
+
+func TestTx_NewUtilityBatchTx(t *testing.T) {
+	tx := &Tx{metadata: mockDecoderMetadata()}
+	address, err := EncodePublicKeyToAddress("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d", 42)
+	require.Nil(t, err)
+
+	transactions := base.NewAnyArray()
+	for i := 0; i < 3; i++ {
+		transfer, err := tx.NewBalanceTransferTx(address, "15000000000")
+		require.Nil(t, err)
+		transactions.Append(transfer.AsAny())
+	}
+	batch, err := tx.NewUtilityBatchTx(transactions)
+	require.Nil(t, err)
+	txHex, err := batch.GetUnSignTx()
+	require.Nil(t, err)
+
+	decoded, err := tx.DecodeExtrinsic(txHex, 10, 42)
+	require.Nil(t, err)
+	require.Equal(t, "batch", decoded.Call.Call)
+	require.Equal(t, 3, len(decoded.Call.Args[0].Value.([]interface{})))
+
+	_, err = tx.NewUtilityBatchTx(base.NewAnyArray())
+	require.NotNil(t, err)
+}
@@ -0,0 +1,478 @@
+package polka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/xxhash"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"golang.org/x/crypto/blake2b"
+)
+
+const multisigAccountPrefix = "modlpy/utilisuba"
+
+type MultisigTimepoint struct {
+	Height int64 `json:"height"`
+	Index  int64 `json:"index"`
+}
+
+func NewMultisigTimepoint(height, index int64) *MultisigTimepoint {
+	return &MultisigTimepoint{Height: height, Index: index}
+}
+
+// The max weight of the call which will be dispatched by the final approval.
+// The ProofSize will be ignored if the chain is using weight v1.
+type MultisigWeight struct {
+	RefTime   int64 `json:"refTime"`
+	ProofSize int64 `json:"proofSize"`
+}
+
+func NewMultisigWeight(refTime, proofSize int64) *MultisigWeight {
+	return &MultisigWeight{RefTime: refTime, ProofSize: proofSize}
+}
+
+// The pending operation of the multisig account.
+type MultisigOperation struct {
+	CallHash  string             `json:"callHash"`
+	Timepoint *MultisigTimepoint `json:"timepoint"`
+	Deposit   string             `json:"deposit"`
+	Depositor string             `json:"depositor"`
+	Approvals []string           `json:"approvals"`
+}
+
+func (o *MultisigOperation) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(o)
+}
+
+func NewMultisigOperationWithJsonString(str string) (*MultisigOperation, error) {
+	var o MultisigOperation
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func NewMultisigOperationArrayWithJsonString(str string) (*base.AnyArray, error) {
+	var o []*MultisigOperation
+	err := base.FromJsonString(str, &o)
+	arr := make([]any, len(o))
+	for i, v := range o {
+		arr[i] = v
+	}
+	return &base.AnyArray{Values: arr}, err
+}
+
+func (o *MultisigOperation) AsAny() *base.Any {
+	return &base.Any{Value: o}
+}
+func AsMultisigOperation(a *base.Any) *MultisigOperation {
+	if r, ok := a.Value.(*MultisigOperation); ok {
+		return r
+	}
+	if r, ok := a.Value.(MultisigOperation); ok {
+		return &r
+	}
+	return nil
+}
+
+// The value of storage `Multisig.Multisigs`
+type multisigStorage struct {
+	When struct {
+		Height types.U32
+		Index  types.U32
+	}
+	Deposit   types.U128
+	Depositor types.AccountID
+	Approvals []types.AccountID
+}
+
+// Derive the address of multisig account, it's the same as `createKeyMulti` of polkadot.js
+// @param signatories the addresses of all signatories (including the sender), separated by ","
+// @param threshold the number of approvals required to dispatch the call
+// @param network the ss58 format of the result address
+func CreateMultisigAddress(signatories string, threshold int, network int) (string, error) {
+	keys, err := multisigSignatoryKeys(signatories)
+	if err != nil {
+		return "", err
+	}
+	accountId, err := multisigAccountId(keys, threshold)
+	if err != nil {
+		return "", err
+	}
+	return EncodePublicKeyToAddress(types.HexEncodeToString(accountId), network)
+}
+
+// Decode and sort the public keys of signatories, the duplicated signatories will be removed.
+func multisigSignatoryKeys(signatories string) ([][]byte, error) {
+	keys := make([][]byte, 0)
+	for _, address := range strings.Split(signatories, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		publicKey, err := DecodeAddressToPublicKey(address)
+		if err != nil {
+			return nil, err
+		}
+		key := types.MustHexDecodeString(publicKey)
+		duplicated := false
+		for _, k := range keys {
+			if bytes.Equal(k, key) {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+	return keys, nil
+}
+
+// blake2_256("modlpy/utilisuba" ++ compact(len) ++ sorted signatories ++ threshold as u16)
+// @param keys the sorted public keys
+func multisigAccountId(keys [][]byte, threshold int) ([]byte, error) {
+	if len(keys) < 2 {
+		return nil, errors.New("the multisig account requires at least 2 signatories")
+	}
+	if threshold < 1 || threshold > len(keys) {
+		return nil, fmt.Errorf("invalid threshold %v, it should be between 1 and %v", threshold, len(keys))
+	}
+	length, err := types.EncodeToBytes(types.NewUCompactFromUInt(uint64(len(keys))))
+	if err != nil {
+		return nil, err
+	}
+	data := append([]byte(multisigAccountPrefix), length...)
+	for _, key := range keys {
+		data = append(data, key...)
+	}
+	data = append(data, byte(threshold), byte(threshold>>8))
+	hash := blake2b.Sum256(data)
+	return hash[:], nil
+}
+
+func otherSignatoriesArg(keys [][]byte, sender []byte) ([]types.AccountID, error) {
+	others := make([]types.AccountID, 0, len(keys))
+	found := false
+	for _, key := range keys {
+		if bytes.Equal(key, sender) {
+			found = true
+			continue
+		}
+		others = append(others, types.NewAccountID(key))
+	}
+	if !found && sender != nil {
+		return nil, errors.New("the sender is not a signatory of the multisig account")
+	}
+	return others, nil
+}
+
+// Encode the timepoint as `Option<Timepoint>`
+func timepointArg(timepoint *MultisigTimepoint) encodedArg {
+	if timepoint == nil {
+		return encodedArg{0}
+	}
+	data := make([]byte, 9)
+	data[0] = 1
+	binary.LittleEndian.PutUint32(data[1:], uint32(timepoint.Height))
+	binary.LittleEndian.PutUint32(data[5:], uint32(timepoint.Index))
+	return data
+}
+
+// Create the `Multisig.as_multi` call, it approves the call and dispatches it if the approvals reach the threshold.
+// @param otherSignatories the addresses of the other signatories (excluding the sender), separated by ","
+// @param timepoint the timepoint of the first approval, nil if it's the first approval.
+// @param maxWeight the max weight of the call, it's required by the final approval.
+func (t *Tx) NewMultisigAsMultiTx(threshold int, otherSignatories string, timepoint *MultisigTimepoint, transaction *Transaction, maxWeight *MultisigWeight) (*Transaction, error) {
+	keys, err := multisigSignatoryKeys(otherSignatories)
+	if err != nil {
+		return nil, err
+	}
+	return t.newMultisigAsMultiTx(threshold, keys, nil, timepoint, transaction, maxWeight)
+}
+
+// Create the `Multisig.approve_as_multi` call, it approves the call by hash without dispatching it.
+// @param callHash the blake2_256 hash of the encoded call, hex string.
+func (t *Tx) NewMultisigApproveAsMultiTx(threshold int, otherSignatories string, timepoint *MultisigTimepoint, callHash string, maxWeight *MultisigWeight) (*Transaction, error) {
+	keys, err := multisigSignatoryKeys(otherSignatories)
+	if err != nil {
+		return nil, err
+	}
+	return t.newMultisigApproveTx("Multisig.approve_as_multi", threshold, keys, nil, timepoint, callHash, maxWeight)
+}
+
+// Create the `Multisig.cancel_as_multi` call, only the depositor can cancel the operation.
+func (t *Tx) NewMultisigCancelAsMultiTx(threshold int, otherSignatories string, timepoint *MultisigTimepoint, callHash string) (*Transaction, error) {
+	if timepoint == nil {
+		return nil, errors.New("the timepoint is required to cancel the multisig operation")
+	}
+	keys, err := multisigSignatoryKeys(otherSignatories)
+	if err != nil {
+		return nil, err
+	}
+	return t.newMultisigApproveTx("Multisig.cancel_as_multi", threshold, keys, nil, timepoint, callHash, nil)
+}
+
+func (t *Tx) newMultisigAsMultiTx(threshold int, keys [][]byte, sender []byte, timepoint *MultisigTimepoint, transaction *Transaction, maxWeight *MultisigWeight) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	const callName = "Multisig.as_multi"
+	others, err := otherSignatoriesArg(keys, sender)
+	if err != nil {
+		return nil, err
+	}
+	call, err := transaction.call()
+	if err != nil {
+		return nil, err
+	}
+	callArg, err := t.callArg(callName, "call", call)
+	if err != nil {
+		return nil, err
+	}
+	if maxWeight == nil {
+		maxWeight = &MultisigWeight{}
+	}
+	weightArg, err := t.weightArg(callName, "max_weight", uint64(maxWeight.RefTime), uint64(maxWeight.ProofSize))
+	if err != nil {
+		return nil, err
+	}
+	return t.newExtrinsicsWithNamedArgs(callName, map[string]interface{}{
+		"threshold":         types.NewU16(uint16(threshold)),
+		"other_signatories": others,
+		"maybe_timepoint":   timepointArg(timepoint),
+		"call":              callArg,
+		"store_call":        types.NewBool(false),
+		"max_weight":        weightArg,
+	})
+}
+
+func (t *Tx) newMultisigApproveTx(callName string, threshold int, keys [][]byte, sender []byte, timepoint *MultisigTimepoint, callHash string, maxWeight *MultisigWeight) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	others, err := otherSignatoriesArg(keys, sender)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := types.NewHashFromHexString(callHash)
+	if err != nil {
+		return nil, err
+	}
+	args := map[string]interface{}{
+		"threshold":         types.NewU16(uint16(threshold)),
+		"other_signatories": others,
+		"maybe_timepoint":   timepointArg(timepoint),
+		"call_hash":         hash,
+	}
+	if timepoint != nil {
+		// `cancel_as_multi` requires the timepoint which is not optional.
+		args["timepoint"] = timepointArg(timepoint)[1:]
+	}
+	if maxWeight == nil {
+		maxWeight = &MultisigWeight{}
+	}
+	if _, err := typeOfCallField(t.metadata, callName, "max_weight"); err == nil {
+		args["max_weight"], err = t.weightArg(callName, "max_weight", uint64(maxWeight.RefTime), uint64(maxWeight.ProofSize))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.newExtrinsicsWithNamedArgs(callName, args)
+}
+
+// Create the `Multisig.as_multi` call, the timepoint and the max weight will be queried from the chain.
+// @param sender the address of the signer, it must be one of the signatories.
+// @param signatories the addresses of all signatories, separated by ","
+func (c *Chain) NewMultisigAsMultiTx(sender, signatories string, threshold int, transaction *Transaction) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	tx, keys, senderKey, err := c.multisigTxArgs(sender, signatories)
+	if err != nil {
+		return
+	}
+	call, err := transaction.call()
+	if err != nil {
+		return
+	}
+	callData, err := types.EncodeToBytes(call)
+	if err != nil {
+		return
+	}
+	callHash := blake2b.Sum256(callData)
+	timepoint, err := c.queryMultisigTimepoint(keys, threshold, callHash[:])
+	if err != nil {
+		return
+	}
+	refTime, proofSize, err := c.estimateWeightOfTransaction(transaction)
+	if err != nil {
+		return
+	}
+	weight := &MultisigWeight{RefTime: int64(refTime), ProofSize: int64(proofSize)}
+	return tx.newMultisigAsMultiTx(threshold, keys, senderKey, timepoint, transaction, weight)
+}
+
+// Create the `Multisig.approve_as_multi` call, the timepoint will be queried from the chain.
+// @param callHash the blake2_256 hash of the encoded call, hex string.
+func (c *Chain) NewMultisigApproveAsMultiTx(sender, signatories string, threshold int, callHash string) (txn *Transaction, err error) {
+	return c.newMultisigApproveTx("Multisig.approve_as_multi", sender, signatories, threshold, callHash)
+}
+
+// Create the `Multisig.cancel_as_multi` call, the timepoint will be queried from the chain.
+func (c *Chain) NewMultisigCancelAsMultiTx(sender, signatories string, threshold int, callHash string) (txn *Transaction, err error) {
+	return c.newMultisigApproveTx("Multisig.cancel_as_multi", sender, signatories, threshold, callHash)
+}
+
+func (c *Chain) newMultisigApproveTx(callName, sender, signatories string, threshold int, callHash string) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	tx, keys, senderKey, err := c.multisigTxArgs(sender, signatories)
+	if err != nil {
+		return
+	}
+	hash, err := types.HexDecodeString(callHash)
+	if err != nil {
+		return
+	}
+	timepoint, err := c.queryMultisigTimepoint(keys, threshold, hash)
+	if err != nil {
+		return
+	}
+	if timepoint == nil && callName == "Multisig.cancel_as_multi" {
+		return nil, errors.New("the multisig operation not found")
+	}
+	return tx.newMultisigApproveTx(callName, threshold, keys, senderKey, timepoint, callHash, nil)
+}
+
+func (c *Chain) multisigTxArgs(sender, signatories string) (tx *Tx, keys [][]byte, senderKey []byte, err error) {
+	cl, err := getConnectedPolkaClient(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	if err = cl.LoadMetadataIfNotExists(); err != nil {
+		return
+	}
+	keys, err = multisigSignatoryKeys(signatories)
+	if err != nil {
+		return
+	}
+	senderPublicKey, err := DecodeAddressToPublicKey(sender)
+	if err != nil {
+		return
+	}
+	return &Tx{metadata: cl.metadata}, keys, types.MustHexDecodeString(senderPublicKey), nil
+}
+
+// Query the timepoint of the first approval from storage `Multisig.Multisigs`
+// @return nil if the operation has not been approved by anyone.
+func (c *Chain) queryMultisigTimepoint(keys [][]byte, threshold int, callHash []byte) (*MultisigTimepoint, error) {
+	cl, err := getConnectedPolkaClient(c.RpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	accountId, err := multisigAccountId(keys, threshold)
+	if err != nil {
+		return nil, err
+	}
+	key, err := types.CreateStorageKey(cl.metadata, "Multisig", "Multisigs", accountId, callHash)
+	if err != nil {
+		return nil, err
+	}
+	var storage multisigStorage
+	ok, err := cl.api.RPC.State.GetStorageLatest(key, &storage)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &MultisigTimepoint{Height: int64(storage.When.Height), Index: int64(storage.When.Index)}, nil
+}
+
+// Fetch the pending operations of the multisig account from storage `Multisig.Multisigs`
+// @return the array of *MultisigOperation, sorted by the timepoint.
+func (c *Chain) FetchPendingMultisigOperations(multisigAddress string) (arr *base.AnyArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, err := getConnectedPolkaClient(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	network, err := cl.SS58Format()
+	if err != nil {
+		return
+	}
+	publicKey, err := DecodeAddressToPublicKey(multisigAddress)
+	if err != nil {
+		return
+	}
+	accountId := types.MustHexDecodeString(publicKey)
+
+	// The first key is hashed by Twox64Concat.
+	prefix := append(xxhash.New128([]byte("Multisig")).Sum(nil), xxhash.New128([]byte("Multisigs")).Sum(nil)...)
+	prefix = append(prefix, xxhash.New64(accountId).Sum(nil)...)
+	prefix = append(prefix, accountId...)
+	keys, err := cl.api.RPC.State.GetKeysLatest(prefix)
+	if err != nil {
+		return
+	}
+
+	operations := make([]*MultisigOperation, 0, len(keys))
+	for _, key := range keys {
+		if len(key) < len(prefix)+32 {
+			continue
+		}
+		var storage multisigStorage
+		ok, err := cl.api.RPC.State.GetStorageLatest(key, &storage)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		operation, err := multisigOperationOfStorage(key[len(key)-32:], &storage, network)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		a, b := operations[i].Timepoint, operations[j].Timepoint
+		return a.Height < b.Height || (a.Height == b.Height && a.Index < b.Index)
+	})
+
+	arr = base.NewAnyArray()
+	for _, operation := range operations {
+		arr.Append(operation.AsAny())
+	}
+	return arr, nil
+}
+
+func multisigOperationOfStorage(callHash []byte, storage *multisigStorage, network int) (*MultisigOperation, error) {
+	depositor, err := EncodePublicKeyToAddress(types.HexEncodeToString(storage.Depositor[:]), network)
+	if err != nil {
+		return nil, err
+	}
+	approvals := make([]string, 0, len(storage.Approvals))
+	for _, approval := range storage.Approvals {
+		address, err := EncodePublicKeyToAddress(types.HexEncodeToString(approval[:]), network)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, address)
+	}
+	deposit := "0"
+	if storage.Deposit.Int != nil {
+		deposit = storage.Deposit.String()
+	}
+	return &MultisigOperation{
+		CallHash:  types.HexEncodeToString(callHash),
+		Timepoint: &MultisigTimepoint{Height: int64(storage.When.Height), Index: int64(storage.When.Index)},
+		Deposit:   deposit,
+		Depositor: depositor,
+		Approvals: approvals,
+	}, nil
+}
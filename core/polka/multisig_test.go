@@ -0,0 +1,56 @@
+package polka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateMultisigAddress(t *testing.T) {
+	alice := "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"
+	bob := "5FHneW46xGXgs5mUiveU4sbTyGBzmstUspZC92UhjJM694ty"
+	charlie := "5FLSigC9HGRKVhB9FiEo4Y3koPsNmBmLJbpXg2mp1hXcS59Y"
+
+	address, err := CreateMultisigAddress(alice+","+bob+","+charlie, 2, 42)
+	require.Nil(t, err)
+	require.Equal(t, "5DjYJStmdZ2rcqXbXGX7TW85JsrW6uG4y9MUcLq2BoPMpRA7", address)
+
+	// the order of signatories does not matter
+	address2, err := CreateMultisigAddress(charlie+", "+alice+","+bob, 2, 42)
+	require.Nil(t, err)
+	require.Equal(t, address, address2)
+
+	address3, err := CreateMultisigAddress(alice+","+bob+","+charlie, 3, 42)
+	require.Nil(t, err)
+	require.NotEqual(t, address, address3)
+
+	_, err = CreateMultisigAddress(alice+","+bob, 3, 42)
+	require.NotNil(t, err)
+	_, err = CreateMultisigAddress(alice+","+alice, 1, 42)
+	require.NotNil(t, err)
+}
+
+func TestChain_MultisigAsMultiTx(t *testing.T) {
+	chain, err := rpcs.polkadot.Chain()
+	require.Nil(t, err)
+	tx, err := chain.GetTx()
+	require.Nil(t, err)
+
+	signatories := accountCase.address0 + "," + "13wNbioJt44NKrcQ5ZUrshJqP7TKzQbzZt5nhkeL4joa3PAX"
+	multisigAddress, err := CreateMultisigAddress(signatories, 2, rpcs.polkadot.net)
+	require.Nil(t, err)
+
+	transfer, err := tx.NewBalanceTransferTx(accountCase.address0, "10000000000")
+	require.Nil(t, err)
+	asMulti, err := chain.NewMultisigAsMultiTx(accountCase.address0, signatories, 2, transfer)
+	require.Nil(t, err)
+	fee, err := chain.EstimateFeeForTransaction(asMulti)
+	require.Nil(t, err)
+	t.Log(fee)
+
+	operations, err := chain.FetchPendingMultisigOperations(multisigAddress)
+	require.Nil(t, err)
+	jsonString, err := operations.JsonString()
+	require.Nil(t, err)
+	t.Log(jsonString.Value)
+}
@@ -0,0 +1,202 @@
+package polka
+
+import (
+	"encoding/binary"
+	"math/big"
+	"sort"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+type NominationPool struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+	// Open, Blocked or Destroying
+	State       string `json:"state"`
+	Points      string `json:"points"`
+	MemberCount int64  `json:"memberCount"`
+	// The commission percent, e.g. 5.5 means 5.5%
+	Commission float64 `json:"commission"`
+
+	Depositor string `json:"depositor"`
+	Root      string `json:"root"`
+	Nominator string `json:"nominator"`
+	Bouncer   string `json:"bouncer"`
+}
+
+func (p *NominationPool) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(p)
+}
+
+func NewNominationPoolWithJsonString(str string) (*NominationPool, error) {
+	var o NominationPool
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func NewNominationPoolArrayWithJsonString(str string) (*base.AnyArray, error) {
+	var o []*NominationPool
+	err := base.FromJsonString(str, &o)
+	arr := make([]any, len(o))
+	for i, v := range o {
+		arr[i] = v
+	}
+	return &base.AnyArray{Values: arr}, err
+}
+
+func (p *NominationPool) AsAny() *base.Any {
+	return &base.Any{Value: p}
+}
+func AsNominationPool(a *base.Any) *NominationPool {
+	if r, ok := a.Value.(*NominationPool); ok {
+		return r
+	}
+	if r, ok := a.Value.(NominationPool); ok {
+		return &r
+	}
+	return nil
+}
+
+type NominationPoolMember struct {
+	Address string `json:"address"`
+	// 0 if the account is not a member of any pool.
+	PoolId int64  `json:"poolId"`
+	Points string `json:"points"`
+	// The pending rewards which can be claimed with `claim_payout`
+	ClaimableRewards string              `json:"claimableRewards"`
+	Unbonding        []*StakingUnlocking `json:"unbonding"`
+}
+
+func (m *NominationPoolMember) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(m)
+}
+
+func NewNominationPoolMemberWithJsonString(str string) (*NominationPoolMember, error) {
+	var o NominationPoolMember
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+// Fetch all the nomination pools.
+// @return the array of *NominationPool, sorted by id.
+func (c *Chain) FetchNominationPools() (arr *base.AnyArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, network, err := c.storageClient()
+	if err != nil {
+		return
+	}
+	keys, values, err := cl.queryStorageEntries("NominationPools", "BondedPools", network)
+	if err != nil {
+		return
+	}
+	pools := make([]*NominationPool, 0, len(keys))
+	nameKeys := make([]types.StorageKey, 0, len(keys))
+	for i, key := range keys {
+		if values[i] == nil || len(key) < 4 {
+			continue
+		}
+		// The pool id is hashed by Twox64Concat, it's the last 4 bytes of the key.
+		poolId := binary.LittleEndian.Uint32(key[len(key)-4:])
+		nameKey, err := types.CreateStorageKey(cl.metadata, "NominationPools", "Metadata", u32StorageArg(poolId))
+		if err != nil {
+			return nil, err
+		}
+		nameKeys = append(nameKeys, nameKey)
+		pools = append(pools, nominationPoolOfValue(int64(poolId), values[i]))
+	}
+	names, err := cl.queryStorageValues("NominationPools", "Metadata", network, nameKeys)
+	if err != nil {
+		return
+	}
+	for i, name := range names {
+		pools[i].Name = textOfHexValue(name)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Id < pools[j].Id
+	})
+
+	arr = base.NewAnyArray()
+	for _, pool := range pools {
+		arr.Append(pool.AsAny())
+	}
+	return arr, nil
+}
+
+func nominationPoolOfValue(poolId int64, value interface{}) *NominationPool {
+	pool := &NominationPool{
+		Id:          poolId,
+		State:       stringOfValue(fieldOfValue(value, "state")),
+		Points:      bigIntOfValue(fieldOfValue(value, "points")).String(),
+		MemberCount: int64OfValue(fieldOfValue(value, "member_counter")),
+		Depositor:   stringOfValue(fieldOfValue(value, "roles", "depositor")),
+		Root:        stringOfValue(fieldOfValue(value, "roles", "root")),
+		Nominator:   stringOfValue(fieldOfValue(value, "roles", "nominator")),
+		Bouncer:     stringOfValue(fieldOfValue(value, "roles", "bouncer")),
+	}
+	// current: Option<(Perbill, AccountId)>, the commission is not supported by the old runtimes.
+	if current := listOfValue(fieldOfValue(value, "commission", "current")); len(current) == 2 {
+		pool.Commission = perbillPercent(bigIntOfValue(current[0]))
+	}
+	return pool
+}
+
+// Fetch the pool membership and the claimable rewards of the account.
+func (c *Chain) FetchNominationPoolMember(address string) (member *NominationPoolMember, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, network, err := c.storageClient()
+	if err != nil {
+		return
+	}
+	publicKey, err := DecodeAddressToPublicKey(address)
+	if err != nil {
+		return
+	}
+	accountId := types.MustHexDecodeString(publicKey)
+	value, err := cl.queryStorageValue("NominationPools", "PoolMembers", network, accountId)
+	if err != nil {
+		return
+	}
+	member = &NominationPoolMember{
+		Address:          address,
+		PoolId:           int64OfValue(fieldOfValue(value, "pool_id")),
+		Points:           bigIntOfValue(fieldOfValue(value, "points")).String(),
+		ClaimableRewards: "0",
+		Unbonding:        []*StakingUnlocking{},
+	}
+	if value == nil {
+		return member, nil
+	}
+	// unbonding_eras: BoundedBTreeMap<EraIndex, Balance>
+	for _, item := range listOfValue(fieldOfValue(value, "unbonding_eras")) {
+		if pair := listOfValue(item); len(pair) == 2 {
+			member.Unbonding = append(member.Unbonding, &StakingUnlocking{
+				Era:   int64OfValue(pair[0]),
+				Value: bigIntOfValue(pair[1]).String(),
+			})
+		}
+	}
+
+	rewards, err := queryNominationPoolPendingRewards(cl, accountId)
+	if err != nil {
+		return
+	}
+	member.ClaimableRewards = rewards.String()
+	return member, nil
+}
+
+// Call the runtime api `NominationPoolsApi_pending_rewards`
+func queryNominationPoolPendingRewards(cl *polkaclient, accountId []byte) (*big.Int, error) {
+	var result string
+	err := cl.api.Client.Call(&result, "state_call", "NominationPoolsApi_pending_rewards", types.HexEncodeToString(accountId))
+	if err != nil {
+		return nil, err
+	}
+	var rewards types.U128
+	if err = types.DecodeFromHexString(result, &rewards); err != nil {
+		return nil, err
+	}
+	return rewards.Int, nil
+}
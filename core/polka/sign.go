@@ -14,12 +14,25 @@ import (
 
 // 功能和 GetSignData 相同，不需要提供 nonce, version 等参数，但需要提供 chain 对象和地址
 func (c *Chain) GetSignData(t *Transaction, walletAddress string) (data []byte, err error) {
+	return c.GetSignDataWithOptions(t, walletAddress, nil)
+}
+
+// 功能和 GetSignDataWithOptions 相同，不需要提供 nonce, version 等参数，但需要提供 chain 对象和地址
+// 如果 options 设置了 EraPeriod 但没有提供 BlockHash, 会以最新的 finalized 区块作为 mortal era 的起点
+// @param options nil 表示 immortal 并且 tip 为 0
+func (c *Chain) GetSignDataWithOptions(t *Transaction, walletAddress string, options *SignOptions) (data []byte, err error) {
 	defer base.CatchPanicAndMapToBasicError(&err)
 
 	cl, err := getConnectedPolkaClient(c.RpcUrl)
 	if err != nil {
 		return
 	}
+	if t.metadata == nil {
+		if err = cl.LoadMetadataIfNotExists(); err != nil {
+			return
+		}
+		t.metadata = cl.metadata
+	}
 	var nonce int64
 	err = client.CallWithBlockHash(cl.api.Client, &nonce, "system_accountNextIndex", nil, walletAddress)
 	if err != nil {
@@ -33,8 +46,40 @@ func (c *Chain) GetSignData(t *Transaction, walletAddress string) (data []byte,
 	if err != nil {
 		return
 	}
+	options, err = cl.fillMortalEraBlock(options)
+	if err != nil {
+		return
+	}
+
+	return t.GetSignDataWithOptions(genesisHash.Hex(), nonce, int32(runtimeVersion.SpecVersion), int32(runtimeVersion.TransactionVersion), options)
+}
 
-	return t.GetSignData(genesisHash.Hex(), nonce, int32(runtimeVersion.SpecVersion), int32(runtimeVersion.TransactionVersion))
+// If the options is mortal but has no block, the birth block of the era based on the latest finalized block will be used.
+// @return a copy of options with the block filled
+func (c *polkaclient) fillMortalEraBlock(options *SignOptions) (*SignOptions, error) {
+	if options == nil || options.EraPeriod <= 0 || options.BlockHash != "" {
+		return options, nil
+	}
+	finalizedHash, err := c.api.RPC.Chain.GetFinalizedHead()
+	if err != nil {
+		return nil, err
+	}
+	header, err := c.api.RPC.Chain.GetHeader(finalizedHash)
+	if err != nil {
+		return nil, err
+	}
+	_, birth := mortalEra(uint64(header.Number), uint64(options.EraPeriod))
+	blockHash := finalizedHash
+	if birth != uint64(header.Number) {
+		blockHash, err = c.api.RPC.Chain.GetBlockHash(birth)
+		if err != nil {
+			return nil, err
+		}
+	}
+	filled := *options
+	filled.BlockNumber = int64(birth)
+	filled.BlockHash = blockHash.Hex()
+	return &filled, nil
 }
 
 type MiniXScriptHash struct {
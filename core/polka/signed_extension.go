@@ -0,0 +1,309 @@
+package polka
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/substrate/types/customscale"
+)
+
+const (
+	// The default number of blocks that a mortal transaction is valid for, it's same as polkadot.js.
+	DefaultEraPeriod = 64
+
+	minEraPeriod = 4
+	maxEraPeriod = 1 << 16
+)
+
+// Used when the metadata is not available or its version does not contain signed extensions.
+// It's the layout of `types.ExtrinsicPayloadV4`.
+var defaultSignedExtensions = []string{
+	"CheckSpecVersion",
+	"CheckTxVersion",
+	"CheckGenesis",
+	"CheckMortality",
+	"CheckNonce",
+	"CheckWeight",
+	"ChargeTransactionPayment",
+}
+
+type SignOptions struct {
+	// The tip paid to the block author, decimal string, empty means 0.
+	Tip string
+
+	// The number of blocks that the transaction is valid for, it will be rounded up to a power of two in [4, 65536].
+	// 0 means the transaction is immortal.
+	EraPeriod int64
+	// The block where the mortal era starts, the transaction is only valid after it.
+	// The block number must be the birth block of the era, you can get it by `Chain.GetSignDataWithOptions` automatically.
+	BlockNumber int64
+	BlockHash   string
+
+	// The asset used to pay the fee, only works on chains with the signed extension `ChargeAssetTxPayment`.
+	// Decimal string for integer asset id, or hex string of the scale encoded asset id (e.g. MultiLocation).
+	// Empty means paying with the native token.
+	AssetId string
+	// The hex string of the metadata hash, only works on chains with the signed extension `CheckMetadataHash`.
+	// Empty means the metadata hash check is disabled.
+	MetadataHash string
+}
+
+// Immortal transaction with zero tip
+func NewSignOptions() *SignOptions {
+	return &SignOptions{}
+}
+
+// Mortal transaction with the default era period and zero tip
+func NewMortalSignOptions() *SignOptions {
+	return &SignOptions{EraPeriod: DefaultEraPeriod}
+}
+
+// The values used to encode the signed extensions of an extrinsic
+type signedExtensionValues struct {
+	era          types.ExtrinsicEra
+	nonce        types.UCompact
+	tip          types.UCompact
+	specVersion  types.U32
+	transVersion types.U32
+	genesisHash  types.Hash
+	blockHash    types.Hash
+
+	assetId      string
+	metadataHash []byte
+}
+
+type signedExtension struct {
+	identifier string
+	// nil if the metadata version is lower than 14
+	metadata *types.SignedExtensionMetadataV14
+}
+
+func signedExtensionsOfMetadata(metadata *types.Metadata) []signedExtension {
+	var names []string
+	if metadata != nil {
+		switch metadata.Version {
+		case 14:
+			extensions := make([]signedExtension, len(metadata.AsMetadataV14.Extrinsic.SignedExtensions))
+			for i := range metadata.AsMetadataV14.Extrinsic.SignedExtensions {
+				ext := &metadata.AsMetadataV14.Extrinsic.SignedExtensions[i]
+				extensions[i] = signedExtension{identifier: string(ext.Identifier), metadata: ext}
+			}
+			return extensions
+		case 13:
+			names = metadata.AsMetadataV13.Extrinsic.SignedExtensions
+		case 12:
+			names = metadata.AsMetadataV12.Extrinsic.SignedExtensions
+		case 11:
+			names = metadata.AsMetadataV11.Extrinsic.SignedExtensions
+		}
+	}
+	if len(names) == 0 {
+		names = defaultSignedExtensions
+	}
+	extensions := make([]signedExtension, len(names))
+	for i, name := range names {
+		extensions[i] = signedExtension{identifier: name}
+	}
+	return extensions
+}
+
+// Encode the signed extensions in the order declared by the metadata.
+// @return extra the data placed in the extrinsic after the signature
+// @return additional the data only included in the sign payload
+func encodeSignedExtensions(metadata *types.Metadata, values *signedExtensionValues) (extra []byte, additional []byte, err error) {
+	extraBuffer, additionalBuffer := bytes.Buffer{}, bytes.Buffer{}
+	extraEncoder, additionalEncoder := scale.NewEncoder(&extraBuffer), scale.NewEncoder(&additionalBuffer)
+	for _, ext := range signedExtensionsOfMetadata(metadata) {
+		switch ext.identifier {
+		case "CheckSpecVersion":
+			err = additionalEncoder.Encode(values.specVersion)
+		case "CheckTxVersion":
+			err = additionalEncoder.Encode(values.transVersion)
+		case "CheckGenesis":
+			err = additionalEncoder.Encode(values.genesisHash)
+		case "CheckMortality", "CheckEra":
+			if err = extraEncoder.Encode(values.era); err == nil {
+				err = additionalEncoder.Encode(values.blockHash)
+			}
+		case "CheckNonce":
+			err = extraEncoder.Encode(values.nonce)
+		case "ChargeTransactionPayment":
+			err = extraEncoder.Encode(values.tip)
+		case "ChargeAssetTxPayment":
+			if err = extraEncoder.Encode(values.tip); err != nil {
+				break
+			}
+			var assetId []byte
+			if assetId, err = encodeOptionAssetId(metadata, ext.metadata, values.assetId); err == nil {
+				err = extraEncoder.Write(assetId)
+			}
+		case "CheckMetadataHash":
+			if values.metadataHash == nil {
+				// mode disabled, additional None
+				if err = extraEncoder.PushByte(0); err == nil {
+					err = additionalEncoder.PushByte(0)
+				}
+			} else {
+				// mode enabled, additional Some(hash)
+				if err = extraEncoder.PushByte(1); err == nil {
+					err = additionalEncoder.Write(append([]byte{1}, values.metadataHash...))
+				}
+			}
+		default:
+			if ext.metadata != nil &&
+				(!isEmptyType(metadata, ext.metadata.Type) || !isEmptyType(metadata, ext.metadata.AdditionalSigned)) {
+				return nil, nil, fmt.Errorf("unsupported signed extension: %v", ext.identifier)
+			}
+			// e.g. CheckNonZeroSender, CheckWeight, they have nothing to encode.
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return extraBuffer.Bytes(), additionalBuffer.Bytes(), nil
+}
+
+// Whether the type is encoded to empty bytes, e.g. `()` or an empty struct.
+func isEmptyType(metadata *types.Metadata, typeId types.Si1LookupTypeID) bool {
+	si1Type := customscale.GetSi1TypeFromMetadata(metadata, typeId)
+	if si1Type == nil {
+		return false
+	}
+	switch {
+	case si1Type.Def.IsTuple:
+		return len(si1Type.Def.Tuple) == 0
+	case si1Type.Def.IsComposite:
+		for _, field := range si1Type.Def.Composite.Fields {
+			if !isEmptyType(metadata, field.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Encode the `Option<AssetId>` of the signed extension `ChargeAssetTxPayment`.
+func encodeOptionAssetId(metadata *types.Metadata, ext *types.SignedExtensionMetadataV14, assetId string) ([]byte, error) {
+	if assetId == "" {
+		return []byte{0}, nil
+	}
+	if strings.HasPrefix(assetId, "0x") {
+		encoded, err := types.HexDecodeString(assetId)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{1}, encoded...), nil
+	}
+	id, ok := new(big.Int).SetString(assetId, 10)
+	if !ok || id.Sign() < 0 {
+		return nil, errors.New("invalid asset id")
+	}
+	if ext == nil {
+		return nil, errors.New("the asset id type is unknown, please provide the scale encoded asset id")
+	}
+	assetType := assetIdTypeOfExtension(metadata, ext)
+	if assetType == nil {
+		return nil, errors.New("the asset id type is unknown, please provide the scale encoded asset id")
+	}
+	var encoded []byte
+	var err error
+	switch {
+	case assetType.Def.IsCompact:
+		encoded, err = types.EncodeToBytes(types.NewUCompact(id))
+	case assetType.Def.IsPrimitive:
+		size := 0
+		switch assetType.Def.Primitive.Si0TypeDefPrimitive {
+		case types.IsU8:
+			size = 1
+		case types.IsU16:
+			size = 2
+		case types.IsU32:
+			size = 4
+		case types.IsU64:
+			size = 8
+		case types.IsU128:
+			size = 16
+		}
+		if size == 0 || id.BitLen() > size*8 {
+			return nil, errors.New("the asset id is out of range")
+		}
+		// scale integers are little endian
+		encoded = make([]byte, size)
+		be := id.Bytes()
+		for i := range be {
+			encoded[i] = be[len(be)-1-i]
+		}
+	default:
+		return nil, errors.New("the asset id is not an integer, please provide the scale encoded asset id")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{1}, encoded...), nil
+}
+
+// Find the type `AssetId` of the field `asset_id: Option<AssetId>`
+func assetIdTypeOfExtension(metadata *types.Metadata, ext *types.SignedExtensionMetadataV14) *types.Si1Type {
+	extType := customscale.GetSi1TypeFromMetadata(metadata, ext.Type)
+	if extType == nil || !extType.Def.IsComposite {
+		return nil
+	}
+	for _, field := range extType.Def.Composite.Fields {
+		if !field.HasName || string(field.Name) != "asset_id" {
+			continue
+		}
+		optionType := customscale.GetSi1TypeFromMetadata(metadata, field.Type)
+		if optionType == nil || !optionType.Def.IsVariant {
+			return nil
+		}
+		for _, variant := range optionType.Def.Variant.Variants {
+			if string(variant.Name) == "Some" && len(variant.Fields) == 1 {
+				return customscale.GetSi1TypeFromMetadata(metadata, variant.Fields[0].Type)
+			}
+		}
+	}
+	return nil
+}
+
+// Calculate the mortal era starting from the block, the implementation is same as substrate's `Era::mortal`.
+// @return birth the block number where the era starts, it may be earlier than the blockNumber if the period is larger than 4096.
+func mortalEra(blockNumber uint64, period uint64) (era types.ExtrinsicEra, birth uint64) {
+	if period < minEraPeriod {
+		period = minEraPeriod
+	}
+	if period > maxEraPeriod {
+		period = maxEraPeriod
+	}
+	// round up to the next power of two
+	period = 1 << bits.Len64(period-1)
+
+	phase := blockNumber % period
+	quantizeFactor := period >> 12
+	if quantizeFactor < 1 {
+		quantizeFactor = 1
+	}
+	quantizedPhase := phase / quantizeFactor * quantizeFactor
+
+	trailingZeros := uint64(bits.TrailingZeros64(period))
+	low := trailingZeros - 1
+	if low < 1 {
+		low = 1
+	}
+	if low > 15 {
+		low = 15
+	}
+	encoded := uint16(low) | uint16(quantizedPhase/quantizeFactor)<<4
+	era = types.ExtrinsicEra{
+		IsMortalEra: true,
+		AsMortalEra: types.MortalEra{First: byte(encoded & 0xff), Second: byte(encoded >> 8)},
+	}
+	birth = (blockNumber-quantizedPhase)/period*period + quantizedPhase
+	return era, birth
+}
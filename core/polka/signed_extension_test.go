@@ -0,0 +1,90 @@
+package polka
+
+import (
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMortalEra(t *testing.T) {
+	era, birth := mortalEra(42, 64)
+	require.Equal(t, types.MortalEra{First: 0xa5, Second: 0x02}, era.AsMortalEra)
+	require.Equal(t, uint64(42), birth)
+
+	// period will be rounded up to the next power of two
+	era2, birth2 := mortalEra(100, 50)
+	era3, birth3 := mortalEra(100, 64)
+	require.Equal(t, era3, era2)
+	require.Equal(t, birth3, birth2)
+	require.Equal(t, uint64(100), birth2)
+
+	// the phase will be quantized if the period is larger than 4096
+	_, birth = mortalEra(1000001, 1<<16)
+	require.Equal(t, uint64(1000000), birth)
+}
+
+func TestTransaction_GetSignDataWithOptions_DefaultLayout(t *testing.T) {
+	genesisHash := "0x2e25d2145e9ecf2d1c185b052e085e3c39340edf3dba74f702653afcdd0a9c37"
+	blockHash := "0x38c5a9f6fabb8d8583ed633c469cdeefb988b0d2384937b15e10e9c0a75aa744"
+	call := types.Call{CallIndex: types.CallIndex{SectionIndex: 6, MethodIndex: 0}, Args: []byte{1, 2, 3}}
+	extrinsic := types.NewExtrinsic(call)
+	transaction := &Transaction{extrinsic: &extrinsic}
+
+	options := &SignOptions{Tip: "1000", EraPeriod: 64, BlockNumber: 42, BlockHash: blockHash}
+	signData, err := transaction.GetSignDataWithOptions(genesisHash, 5, 21, 4, options)
+	require.Nil(t, err)
+
+	// the payload without metadata should be same as ExtrinsicPayloadV4
+	genesis, _ := types.NewHashFromHexString(genesisHash)
+	block, _ := types.NewHashFromHexString(blockHash)
+	methodBytes, _ := types.EncodeToBytes(call)
+	era, _ := mortalEra(42, 64)
+	want, err := types.EncodeToBytes(types.ExtrinsicPayloadV4{
+		ExtrinsicPayloadV3: types.ExtrinsicPayloadV3{
+			Method:      methodBytes,
+			Era:         era,
+			Nonce:       types.NewUCompactFromUInt(5),
+			Tip:         types.NewUCompactFromUInt(1000),
+			SpecVersion: 21,
+			GenesisHash: genesis,
+			BlockHash:   block,
+		},
+		TransactionVersion: 4,
+	})
+	require.Nil(t, err)
+	require.Equal(t, want, signData)
+
+	// the signed extrinsic should be same as types.Extrinsic
+	publicKey := make([]byte, 32)
+	signature := make([]byte, 64)
+	txHex, err := transaction.GetTx(publicKey, signature)
+	require.Nil(t, err)
+	wantHex, err := types.EncodeToHexString(transaction.extrinsic)
+	require.Nil(t, err)
+	require.Equal(t, wantHex, txHex)
+
+	// the block of era must be the birth block
+	options.EraPeriod = 1 << 16
+	options.BlockNumber = 1000001
+	_, err = transaction.GetSignDataWithOptions(genesisHash, 5, 21, 4, options)
+	require.Equal(t, ErrEraBlock, err)
+}
+
+func TestEncodeSignedExtensions_MetadataHash(t *testing.T) {
+	metadata := types.NewMetadataV14()
+	for _, name := range []string{"CheckNonce", "ChargeAssetTxPayment", "CheckMetadataHash"} {
+		metadata.AsMetadataV14.Extrinsic.SignedExtensions = append(metadata.AsMetadataV14.Extrinsic.SignedExtensions,
+			types.SignedExtensionMetadataV14{Identifier: types.Text(name)})
+	}
+	values := &signedExtensionValues{
+		nonce:        types.NewUCompactFromUInt(1),
+		tip:          types.NewUCompactFromUInt(0),
+		assetId:      "0x0102",
+		metadataHash: make([]byte, 32),
+	}
+	extra, additional, err := encodeSignedExtensions(metadata, values)
+	require.Nil(t, err)
+	require.Equal(t, []byte{0x04, 0x00, 0x01, 0x01, 0x02, 0x01}, extra)
+	require.Equal(t, append([]byte{0x01}, make([]byte, 32)...), additional)
+}
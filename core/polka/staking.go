@@ -0,0 +1,780 @@
+package polka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+// The default `Staking.HistoryDepth` of polkadot & kusama
+const defaultStakingHistoryDepth = 84
+
+// 100% in Perbill
+var perbillBase = big.NewInt(1000000000)
+
+type StakingEra struct {
+	Index int64 `json:"index"`
+	// The timestamp (milliseconds) of the era start, 0 if the era has not started.
+	Start int64 `json:"start"`
+}
+
+func (e *StakingEra) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(e)
+}
+
+func NewStakingEraWithJsonString(str string) (*StakingEra, error) {
+	var o StakingEra
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+type StakingValidator struct {
+	Address string `json:"address"`
+	// The display name of the on-chain identity, empty if the identity is not set.
+	Identity string `json:"identity"`
+	// The commission percent, e.g. 5.5 means 5.5%
+	Commission float64 `json:"commission"`
+	// The validator does not accept new nominations if it's blocked.
+	Blocked bool `json:"blocked"`
+
+	TotalStake     string `json:"totalStake"`
+	OwnStake       string `json:"ownStake"`
+	NominatorCount int64  `json:"nominatorCount"`
+}
+
+func (v *StakingValidator) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(v)
+}
+
+func NewStakingValidatorWithJsonString(str string) (*StakingValidator, error) {
+	var o StakingValidator
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func NewStakingValidatorArrayWithJsonString(str string) (*base.AnyArray, error) {
+	var o []*StakingValidator
+	err := base.FromJsonString(str, &o)
+	arr := make([]any, len(o))
+	for i, v := range o {
+		arr[i] = v
+	}
+	return &base.AnyArray{Values: arr}, err
+}
+
+func (v *StakingValidator) AsAny() *base.Any {
+	return &base.Any{Value: v}
+}
+func AsStakingValidator(a *base.Any) *StakingValidator {
+	if r, ok := a.Value.(*StakingValidator); ok {
+		return r
+	}
+	if r, ok := a.Value.(StakingValidator); ok {
+		return &r
+	}
+	return nil
+}
+
+type StakingNominations struct {
+	Targets     []string `json:"targets"`
+	SubmittedIn int64    `json:"submittedIn"`
+	Suppressed  bool     `json:"suppressed"`
+}
+
+func (n *StakingNominations) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(n)
+}
+
+func NewStakingNominationsWithJsonString(str string) (*StakingNominations, error) {
+	var o StakingNominations
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+// The unbonding funds which can be withdrawn after the era.
+type StakingUnlocking struct {
+	Value string `json:"value"`
+	Era   int64  `json:"era"`
+}
+
+type StakingLedger struct {
+	Stash      string `json:"stash"`
+	Controller string `json:"controller"`
+	// The total bonded amount, including the unlocking funds.
+	Total     string              `json:"total"`
+	Active    string              `json:"active"`
+	Unlocking []*StakingUnlocking `json:"unlocking"`
+}
+
+func (l *StakingLedger) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(l)
+}
+
+func NewStakingLedgerWithJsonString(str string) (*StakingLedger, error) {
+	var o StakingLedger
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+// The reward of the era which has not been claimed, it can be claimed with `payout_stakers`.
+type StakingPayout struct {
+	Era       int64  `json:"era"`
+	Validator string `json:"validator"`
+	// The page of the exposure which contains the staker, it's always 0 in the old runtimes.
+	Page   int64  `json:"page"`
+	Amount string `json:"amount"`
+}
+
+func (p *StakingPayout) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(p)
+}
+
+func NewStakingPayoutWithJsonString(str string) (*StakingPayout, error) {
+	var o StakingPayout
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func NewStakingPayoutArrayWithJsonString(str string) (*base.AnyArray, error) {
+	var o []*StakingPayout
+	err := base.FromJsonString(str, &o)
+	arr := make([]any, len(o))
+	for i, v := range o {
+		arr[i] = v
+	}
+	return &base.AnyArray{Values: arr}, err
+}
+
+func (p *StakingPayout) AsAny() *base.Any {
+	return &base.Any{Value: p}
+}
+func AsStakingPayout(a *base.Any) *StakingPayout {
+	if r, ok := a.Value.(*StakingPayout); ok {
+		return r
+	}
+	if r, ok := a.Value.(StakingPayout); ok {
+		return &r
+	}
+	return nil
+}
+
+func (c *Chain) FetchStakingActiveEra() (era *StakingEra, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, network, err := c.storageClient()
+	if err != nil {
+		return
+	}
+	return queryStakingActiveEra(cl, network)
+}
+
+func queryStakingActiveEra(cl *polkaclient, network int) (*StakingEra, error) {
+	value, err := cl.queryStorageValue("Staking", "ActiveEra", network)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errors.New("the active era is not found")
+	}
+	return &StakingEra{
+		Index: int64OfValue(fieldOfValue(value, "index")),
+		Start: int64OfValue(fieldOfValue(value, "start")),
+	}, nil
+}
+
+// Fetch the validators of the active era, with the commission, the identity and the exposure.
+// @return the array of *StakingValidator
+func (c *Chain) FetchStakingValidators() (arr *base.AnyArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, network, err := c.storageClient()
+	if err != nil {
+		return
+	}
+	era, err := queryStakingActiveEra(cl, network)
+	if err != nil {
+		return
+	}
+	value, err := cl.queryStorageValue("Session", "Validators", network)
+	if err != nil {
+		return
+	}
+	addresses := stringsOfValue(value)
+	validators, err := queryStakingValidators(cl, network, addresses, era.Index)
+	if err != nil {
+		return
+	}
+	arr = base.NewAnyArray()
+	for _, validator := range validators {
+		arr.Append(validator.AsAny())
+	}
+	return arr, nil
+}
+
+func queryStakingValidators(cl *polkaclient, network int, addresses []string, era int64) ([]*StakingValidator, error) {
+	accountIds := make([][]byte, 0, len(addresses))
+	for _, address := range addresses {
+		publicKey, err := DecodeAddressToPublicKey(address)
+		if err != nil {
+			return nil, err
+		}
+		accountIds = append(accountIds, types.MustHexDecodeString(publicKey))
+	}
+	prefs, err := queryStorageValuesOfAccounts(cl, network, "Staking", "Validators", accountIds)
+	if err != nil {
+		return nil, err
+	}
+	// The exposure storage is `ErasStakersOverview` in the paged runtimes, `ErasStakers` in the old runtimes.
+	exposureItem := "ErasStakersOverview"
+	if !hasStorageEntry(cl.metadata, "Staking", exposureItem) {
+		exposureItem = "ErasStakers"
+	}
+	exposureKeys := make([]types.StorageKey, 0, len(accountIds))
+	for _, accountId := range accountIds {
+		key, err := types.CreateStorageKey(cl.metadata, "Staking", exposureItem, u32StorageArg(uint32(era)), accountId)
+		if err != nil {
+			return nil, err
+		}
+		exposureKeys = append(exposureKeys, key)
+	}
+	exposures, err := cl.queryStorageValues("Staking", exposureItem, network, exposureKeys)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := queryIdentities(cl, network, accountIds)
+	if err != nil {
+		return nil, err
+	}
+
+	validators := make([]*StakingValidator, 0, len(addresses))
+	for i, address := range addresses {
+		nominatorCount := int64(len(listOfValue(fieldOfValue(exposures[i], "others"))))
+		if exposureItem == "ErasStakersOverview" {
+			nominatorCount = int64OfValue(fieldOfValue(exposures[i], "nominator_count"))
+		}
+		validators = append(validators, &StakingValidator{
+			Address:        address,
+			Identity:       identities[i],
+			Commission:     perbillPercent(bigIntOfValue(fieldOfValue(prefs[i], "commission"))),
+			Blocked:        fieldOfValue(prefs[i], "blocked") == true,
+			TotalStake:     bigIntOfValue(fieldOfValue(exposures[i], "total")).String(),
+			OwnStake:       bigIntOfValue(fieldOfValue(exposures[i], "own")).String(),
+			NominatorCount: nominatorCount,
+		})
+	}
+	return validators, nil
+}
+
+func queryStorageValuesOfAccounts(cl *polkaclient, network int, pallet, item string, accountIds [][]byte) ([]interface{}, error) {
+	keys := make([]types.StorageKey, 0, len(accountIds))
+	for _, accountId := range accountIds {
+		key, err := types.CreateStorageKey(cl.metadata, pallet, item, accountId)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return cl.queryStorageValues(pallet, item, network, keys)
+}
+
+// Query the display names of the on-chain identities, the sub identity will be displayed as `parent/sub`
+// @return empty names if the chain has no identity pallet.
+func queryIdentities(cl *polkaclient, network int, accountIds [][]byte) ([]string, error) {
+	names := make([]string, len(accountIds))
+	if !hasStorageEntry(cl.metadata, "Identity", "IdentityOf") {
+		return names, nil
+	}
+	identities, err := queryStorageValuesOfAccounts(cl, network, "Identity", "IdentityOf", accountIds)
+	if err != nil {
+		return nil, err
+	}
+	subIndexes := make([]int, 0)
+	subAccountIds := make([][]byte, 0)
+	for i, identity := range identities {
+		if identity == nil {
+			subIndexes = append(subIndexes, i)
+			subAccountIds = append(subAccountIds, accountIds[i])
+			continue
+		}
+		names[i] = identityDisplayName(identity)
+	}
+	if len(subAccountIds) == 0 || !hasStorageEntry(cl.metadata, "Identity", "SuperOf") {
+		return names, nil
+	}
+
+	// SuperOf: (parent, sub name)
+	supers, err := queryStorageValuesOfAccounts(cl, network, "Identity", "SuperOf", subAccountIds)
+	if err != nil {
+		return nil, err
+	}
+	parentIds := make([][]byte, 0)
+	parentIndexes := make([]int, 0)
+	for i, super := range supers {
+		values := listOfValue(super)
+		if len(values) != 2 {
+			continue
+		}
+		publicKey, err := DecodeAddressToPublicKey(stringOfValue(values[0]))
+		if err != nil {
+			continue
+		}
+		names[subIndexes[i]] = identityData(values[1])
+		parentIds = append(parentIds, types.MustHexDecodeString(publicKey))
+		parentIndexes = append(parentIndexes, subIndexes[i])
+	}
+	if len(parentIds) == 0 {
+		return names, nil
+	}
+	parents, err := queryStorageValuesOfAccounts(cl, network, "Identity", "IdentityOf", parentIds)
+	if err != nil {
+		return nil, err
+	}
+	for i, parent := range parents {
+		index := parentIndexes[i]
+		if parentName := identityDisplayName(parent); parentName != "" && names[index] != "" {
+			names[index] = parentName + "/" + names[index]
+		} else if parentName != "" {
+			names[index] = parentName
+		}
+	}
+	return names, nil
+}
+
+func identityDisplayName(identity interface{}) string {
+	// The new runtimes store `(Registration, Option<Username>)`
+	if values := listOfValue(identity); len(values) > 0 {
+		identity = values[0]
+	}
+	return identityData(fieldOfValue(identity, "info", "display"))
+}
+
+// The `Data` of identity is an enum, only the raw data can be displayed, e.g. {"Raw5": "0x..."}
+func identityData(data interface{}) string {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	for name, value := range m {
+		if len(name) > 3 && name[:3] == "Raw" {
+			return textOfHexValue(value)
+		}
+	}
+	return ""
+}
+
+// @param perbill the ratio in Perbill
+// @return the percent, e.g. 5.5 means 5.5%
+func perbillPercent(perbill *big.Int) float64 {
+	percent, _ := new(big.Float).Quo(new(big.Float).SetInt(perbill), big.NewFloat(1e7)).Float64()
+	return percent
+}
+
+// Fetch the validators nominated by the stash account.
+// @return the nominations with empty targets if the account has not nominated.
+func (c *Chain) FetchStakingNominations(stash string) (nominations *StakingNominations, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, network, err := c.storageClient()
+	if err != nil {
+		return
+	}
+	return queryStakingNominations(cl, network, stash)
+}
+
+func queryStakingNominations(cl *polkaclient, network int, stash string) (*StakingNominations, error) {
+	publicKey, err := DecodeAddressToPublicKey(stash)
+	if err != nil {
+		return nil, err
+	}
+	value, err := cl.queryStorageValue("Staking", "Nominators", network, types.MustHexDecodeString(publicKey))
+	if err != nil {
+		return nil, err
+	}
+	return &StakingNominations{
+		Targets:     stringsOfValue(fieldOfValue(value, "targets")),
+		SubmittedIn: int64OfValue(fieldOfValue(value, "submitted_in")),
+		Suppressed:  fieldOfValue(value, "suppressed") == true,
+	}, nil
+}
+
+// Fetch the bonded ledger of the stash account.
+// @return the ledger with zero amount if the account has not bonded.
+func (c *Chain) FetchStakingLedger(stash string) (ledger *StakingLedger, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, network, err := c.storageClient()
+	if err != nil {
+		return
+	}
+	value, controller, err := queryStakingLedger(cl, network, stash)
+	if err != nil {
+		return
+	}
+	ledger = &StakingLedger{
+		Stash:      stash,
+		Controller: controller,
+		Total:      bigIntOfValue(fieldOfValue(value, "total")).String(),
+		Active:     bigIntOfValue(fieldOfValue(value, "active")).String(),
+		Unlocking:  []*StakingUnlocking{},
+	}
+	for _, chunk := range listOfValue(fieldOfValue(value, "unlocking")) {
+		ledger.Unlocking = append(ledger.Unlocking, &StakingUnlocking{
+			Value: bigIntOfValue(fieldOfValue(chunk, "value")).String(),
+			Era:   int64OfValue(fieldOfValue(chunk, "era")),
+		})
+	}
+	return ledger, nil
+}
+
+// The ledger is stored by the controller, the controller is the stash itself in the new runtimes.
+// @return the decoded ledger (nil if not bonded) and the controller address.
+func queryStakingLedger(cl *polkaclient, network int, stash string) (ledger interface{}, controller string, err error) {
+	publicKey, err := DecodeAddressToPublicKey(stash)
+	if err != nil {
+		return
+	}
+	bonded, err := cl.queryStorageValue("Staking", "Bonded", network, types.MustHexDecodeString(publicKey))
+	if err != nil {
+		return
+	}
+	controller = stringOfValue(bonded)
+	if controller == "" {
+		return nil, "", nil
+	}
+	controllerKey, err := DecodeAddressToPublicKey(controller)
+	if err != nil {
+		return
+	}
+	ledger, err = cl.queryStorageValue("Staking", "Ledger", network, types.MustHexDecodeString(controllerKey))
+	return ledger, controller, err
+}
+
+// Fetch the rewards which have not been claimed by the validators of the stash's nominations.
+// The amounts are estimated with the current nominations, the commission of paged payouts is not split by pages.
+// @param eraCount the number of recent eras to be searched, it's limited by `Staking.HistoryDepth`; 0 means all the eras in history.
+// @return the array of *StakingPayout, sorted by era.
+func (c *Chain) FetchStakingPendingPayouts(stash string, eraCount int) (arr *base.AnyArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, network, err := c.storageClient()
+	if err != nil {
+		return
+	}
+	era, err := queryStakingActiveEra(cl, network)
+	if err != nil {
+		return
+	}
+	nominations, err := queryStakingNominations(cl, network, stash)
+	if err != nil {
+		return
+	}
+	addresses := nominations.Targets
+	stashId, err := accountIdOfAddress(stash)
+	if err != nil {
+		return
+	}
+	if prefs, err := cl.queryStorageValue("Staking", "Validators", network, stashId); err == nil && prefs != nil {
+		// the stash may be encoded in the other ss58 format, re-encode it as the nominated validators.
+		address, err := EncodePublicKeyToAddress(types.HexEncodeToString(stashId), network)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+
+	historyDepth := defaultStakingHistoryDepth
+	if data, err := cl.metadata.FindConstantValue("Staking", "HistoryDepth"); err == nil && len(data) == 4 {
+		historyDepth = int(binary.LittleEndian.Uint32(data))
+	}
+	if eraCount <= 0 || eraCount > historyDepth {
+		eraCount = historyDepth
+	}
+
+	arr = base.NewAnyArray()
+	if len(addresses) == 0 {
+		return arr, nil
+	}
+	validators, err := queryPayoutValidators(cl, network, addresses)
+	if err != nil {
+		return
+	}
+	for e := era.Index - int64(eraCount); e < era.Index; e++ {
+		if e < 0 {
+			continue
+		}
+		payouts, err := queryStakingPayoutsOfEra(cl, network, stashId, validators, e)
+		if err != nil {
+			return nil, err
+		}
+		for _, payout := range payouts {
+			arr.Append(payout.AsAny())
+		}
+	}
+	return arr, nil
+}
+
+// The validator that pays the rewards to the stash.
+type payoutValidator struct {
+	address   string
+	accountId []byte
+	// The claimed eras stored in the validator's ledger in the old runtimes.
+	legacyClaimedEras map[int64]bool
+}
+
+func queryPayoutValidators(cl *polkaclient, network int, addresses []string) ([]*payoutValidator, error) {
+	validators := make([]*payoutValidator, 0, len(addresses))
+	accountIds := make([][]byte, 0, len(addresses))
+	for _, address := range addresses {
+		accountId, err := accountIdOfAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, &payoutValidator{address: address, accountId: accountId, legacyClaimedEras: map[int64]bool{}})
+		accountIds = append(accountIds, accountId)
+	}
+
+	// The ledger is stored by the controller, the controller is the stash itself in the new runtimes.
+	bonded, err := queryStorageValuesOfAccounts(cl, network, "Staking", "Bonded", accountIds)
+	if err != nil {
+		return nil, err
+	}
+	bondedValidators := []*payoutValidator{}
+	controllerIds := [][]byte{}
+	for i, controller := range bonded {
+		controllerId, err := accountIdOfAddress(stringOfValue(controller))
+		if err != nil {
+			continue
+		}
+		bondedValidators = append(bondedValidators, validators[i])
+		controllerIds = append(controllerIds, controllerId)
+	}
+	ledgers, err := queryStorageValuesOfAccounts(cl, network, "Staking", "Ledger", controllerIds)
+	if err != nil {
+		return nil, err
+	}
+	for i, ledger := range ledgers {
+		for _, e := range listOfValue(anyFieldOfValue(ledger, "legacy_claimed_rewards", "claimed_rewards")) {
+			bondedValidators[i].legacyClaimedEras[int64OfValue(e)] = true
+		}
+	}
+	return validators, nil
+}
+
+func queryStakingPayoutsOfEra(cl *polkaclient, network int, stashId []byte, validators []*payoutValidator, era int64) ([]*StakingPayout, error) {
+	eraArg := u32StorageArg(uint32(era))
+	eraReward, err := cl.queryStorageValue("Staking", "ErasValidatorReward", network, eraArg)
+	if err != nil || eraReward == nil {
+		return nil, err
+	}
+	rewardPoints, err := cl.queryStorageValue("Staking", "ErasRewardPoints", network, eraArg)
+	if err != nil {
+		return nil, err
+	}
+	totalPoints := bigIntOfValue(fieldOfValue(rewardPoints, "total"))
+	if totalPoints.Sign() == 0 {
+		return nil, nil
+	}
+	// individual: BTreeMap<AccountId, RewardPoint>
+	points := make(map[string]*big.Int)
+	for _, item := range listOfValue(fieldOfValue(rewardPoints, "individual")) {
+		if pair := listOfValue(item); len(pair) == 2 {
+			if accountId, err := accountIdOfAddress(stringOfValue(pair[0])); err == nil {
+				points[string(accountId)] = bigIntOfValue(pair[1])
+			}
+		}
+	}
+
+	rewarded := []*payoutValidator{}
+	accountIds := [][]byte{}
+	for _, validator := range validators {
+		if p, ok := points[string(validator.accountId)]; ok && p.Sign() > 0 {
+			rewarded = append(rewarded, validator)
+			accountIds = append(accountIds, validator.accountId)
+		}
+	}
+	if len(rewarded) == 0 {
+		return nil, nil
+	}
+	exposures, err := queryStakerExposures(cl, network, stashId, accountIds, era)
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := queryEraStorageValues(cl, network, "ErasValidatorPrefs", era, accountIds)
+	if err != nil {
+		return nil, err
+	}
+	// The claimed pages are stored in `ClaimedRewards` in the paged runtimes.
+	claimedPages := make([]interface{}, len(accountIds))
+	if hasStorageEntry(cl.metadata, "Staking", "ClaimedRewards") {
+		claimedPages, err = queryEraStorageValues(cl, network, "ClaimedRewards", era, accountIds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	payouts := make([]*StakingPayout, 0)
+	for i, validator := range rewarded {
+		exposure := exposures[i]
+		if exposure == nil || exposure.total.Sign() == 0 {
+			continue
+		}
+		if validator.legacyClaimedEras[era] {
+			continue
+		}
+		claimed := false
+		for _, p := range listOfValue(claimedPages[i]) {
+			if int64OfValue(p) == exposure.page {
+				claimed = true
+				break
+			}
+		}
+		if claimed {
+			continue
+		}
+		commission := bigIntOfValue(fieldOfValue(prefs[i], "commission"))
+		isValidator := bytes.Equal(validator.accountId, stashId)
+		amount := stakingRewardOfExposure(bigIntOfValue(eraReward), points[string(validator.accountId)], totalPoints, commission, exposure, isValidator)
+		if amount.Sign() == 0 {
+			continue
+		}
+		payouts = append(payouts, &StakingPayout{
+			Era:       era,
+			Validator: validator.address,
+			Page:      exposure.page,
+			Amount:    amount.String(),
+		})
+	}
+	sort.SliceStable(payouts, func(i, j int) bool {
+		return payouts[i].Validator < payouts[j].Validator
+	})
+	return payouts, nil
+}
+
+type stakerExposure struct {
+	// The total stake of the validator
+	total *big.Int
+	// The stake of the staker
+	value *big.Int
+	page  int64
+}
+
+// Find the stake of the staker in the validators' exposures.
+// @return the exposures in the same order as validators, nil if the staker is not exposed to the validator.
+func queryStakerExposures(cl *polkaclient, network int, stashId []byte, validatorIds [][]byte, era int64) ([]*stakerExposure, error) {
+	res := make([]*stakerExposure, len(validatorIds))
+	if !hasStorageEntry(cl.metadata, "Staking", "ErasStakersOverview") {
+		exposures, err := queryEraStorageValues(cl, network, "ErasStakersClipped", era, validatorIds)
+		if err != nil {
+			return nil, err
+		}
+		for i, exposure := range exposures {
+			if exposure != nil {
+				isValidator := bytes.Equal(validatorIds[i], stashId)
+				res[i] = stakerExposureOfPage(stashId, isValidator, exposure, bigIntOfValue(fieldOfValue(exposure, "total")), 0)
+			}
+		}
+		return res, nil
+	}
+
+	overviews, err := queryEraStorageValues(cl, network, "ErasStakersOverview", era, validatorIds)
+	if err != nil {
+		return nil, err
+	}
+	// the nominator should be searched in all pages of the validator.
+	pageKeys := []types.StorageKey{}
+	pageOwners := []int{}
+	pageIndexes := []int64{}
+	for i, overview := range overviews {
+		if overview == nil {
+			continue
+		}
+		total := bigIntOfValue(fieldOfValue(overview, "total"))
+		if bytes.Equal(validatorIds[i], stashId) {
+			res[i] = &stakerExposure{total: total, value: bigIntOfValue(fieldOfValue(overview, "own"))}
+			continue
+		}
+		pageCount := int64OfValue(fieldOfValue(overview, "page_count"))
+		for page := int64(0); page < pageCount; page++ {
+			key, err := types.CreateStorageKey(cl.metadata, "Staking", "ErasStakersPaged", u32StorageArg(uint32(era)), validatorIds[i], u32StorageArg(uint32(page)))
+			if err != nil {
+				return nil, err
+			}
+			pageKeys = append(pageKeys, key)
+			pageOwners = append(pageOwners, i)
+			pageIndexes = append(pageIndexes, page)
+		}
+	}
+	if len(pageKeys) == 0 {
+		return res, nil
+	}
+	pages, err := cl.queryStorageValues("Staking", "ErasStakersPaged", network, pageKeys)
+	if err != nil {
+		return nil, err
+	}
+	for j, exposure := range pages {
+		i := pageOwners[j]
+		if res[i] != nil {
+			continue
+		}
+		total := bigIntOfValue(fieldOfValue(overviews[i], "total"))
+		res[i] = stakerExposureOfPage(stashId, false, exposure, total, pageIndexes[j])
+	}
+	return res, nil
+}
+
+func stakerExposureOfPage(stashId []byte, isValidator bool, exposure interface{}, total *big.Int, page int64) *stakerExposure {
+	if isValidator {
+		return &stakerExposure{total: total, value: bigIntOfValue(fieldOfValue(exposure, "own")), page: page}
+	}
+	for _, other := range listOfValue(fieldOfValue(exposure, "others")) {
+		who, err := accountIdOfAddress(stringOfValue(fieldOfValue(other, "who")))
+		if err == nil && bytes.Equal(who, stashId) {
+			return &stakerExposure{total: total, value: bigIntOfValue(fieldOfValue(other, "value")), page: page}
+		}
+	}
+	return nil
+}
+
+// Query the storage values of the double map `Staking.item(era, accountId)`
+func queryEraStorageValues(cl *polkaclient, network int, item string, era int64, accountIds [][]byte) ([]interface{}, error) {
+	keys := make([]types.StorageKey, 0, len(accountIds))
+	for _, accountId := range accountIds {
+		key, err := types.CreateStorageKey(cl.metadata, "Staking", item, u32StorageArg(uint32(era)), accountId)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return cl.queryStorageValues("Staking", item, network, keys)
+}
+
+// The raw account id of the ss58 address, the addresses encoded in different formats have the same account id.
+func accountIdOfAddress(address string) ([]byte, error) {
+	publicKey, err := DecodeAddressToPublicKey(address)
+	if err != nil {
+		return nil, err
+	}
+	return types.HexDecodeString(publicKey)
+}
+
+// validator payout = era reward * validator points / total points
+// staker payout = (validator payout - commission) * stake / total stake, the validator also gets the commission.
+func stakingRewardOfExposure(eraReward, validatorPoints, totalPoints, commission *big.Int, exposure *stakerExposure, isValidator bool) *big.Int {
+	validatorPayout := new(big.Int).Mul(eraReward, validatorPoints)
+	validatorPayout.Quo(validatorPayout, totalPoints)
+	commissionPayout := new(big.Int).Mul(validatorPayout, commission)
+	commissionPayout.Quo(commissionPayout, perbillBase)
+	leftover := new(big.Int).Sub(validatorPayout, commissionPayout)
+
+	reward := new(big.Int).Mul(leftover, exposure.value)
+	reward.Quo(reward, exposure.total)
+	if isValidator {
+		reward.Add(reward, commissionPayout)
+	}
+	return reward
+}
@@ -0,0 +1,140 @@
+package polka
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStakingRewardOfExposure(t *testing.T) {
+	eraReward := big.NewInt(1000000)
+	exposure := &stakerExposure{total: big.NewInt(100), value: big.NewInt(25)}
+	commission := big.NewInt(100000000) // 10%
+
+	// validator payout = 1000000 * 20 / 100 = 200000, commission = 20000
+	reward := stakingRewardOfExposure(eraReward, big.NewInt(20), big.NewInt(100), commission, exposure, false)
+	require.Equal(t, "45000", reward.String())
+	reward = stakingRewardOfExposure(eraReward, big.NewInt(20), big.NewInt(100), commission, exposure, true)
+	require.Equal(t, "65000", reward.String())
+
+	require.Equal(t, 10.0, perbillPercent(commission))
+}
+
+func TestStakerExposureOfPage(t *testing.T) {
+	publicKey := "0x8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48"
+	polkadotAddress, err := EncodePublicKeyToAddress(publicKey, 0)
+	require.Nil(t, err)
+	substrateAddress, err := EncodePublicKeyToAddress(publicKey, 42)
+	require.Nil(t, err)
+
+	// the exposure is decoded in the chain's format, the stash is passed in the other format.
+	stashId, err := accountIdOfAddress(substrateAddress)
+	require.Nil(t, err)
+	exposure := map[string]interface{}{
+		"own": "100",
+		"others": []interface{}{
+			map[string]interface{}{"who": polkadotAddress, "value": "25"},
+		},
+	}
+	res := stakerExposureOfPage(stashId, false, exposure, big.NewInt(125), 1)
+	require.NotNil(t, res)
+	require.Equal(t, "25", res.value.String())
+	require.Equal(t, int64(1), res.page)
+
+	res = stakerExposureOfPage(stashId, true, exposure, big.NewInt(125), 0)
+	require.Equal(t, "100", res.value.String())
+
+	otherId := make([]byte, 32)
+	require.Nil(t, stakerExposureOfPage(otherId, false, exposure, big.NewInt(125), 0))
+}
+
+func TestIdentityDisplayName(t *testing.T) {
+	registration := map[string]interface{}{
+		"info": map[string]interface{}{
+			"display": map[string]interface{}{"Raw5": "0x68656c6c6f"},
+		},
+	}
+	require.Equal(t, "hello", identityDisplayName(registration))
+	require.Equal(t, "hello", identityDisplayName([]interface{}{registration, nil}))
+	require.Equal(t, "", identityDisplayName(nil))
+}
+
+func TestChain_StakingQueries(t *testing.T) {
+	chain, err := rpcs.polkadot.Chain()
+	require.Nil(t, err)
+
+	era, err := chain.FetchStakingActiveEra()
+	require.Nil(t, err)
+	t.Log(era.Index)
+
+	validators, err := chain.FetchStakingValidators()
+	require.Nil(t, err)
+	require.Greater(t, validators.Count(), 0)
+	validator := AsStakingValidator(validators.ValueOf(0))
+	t.Log(validator.JsonString())
+
+	stash := "13wNbioJt44NKrcQ5ZUrshJqP7TKzQbzZt5nhkeL4joa3PAX"
+	nominations, err := chain.FetchStakingNominations(stash)
+	require.Nil(t, err)
+	t.Log(nominations.JsonString())
+
+	ledger, err := chain.FetchStakingLedger(stash)
+	require.Nil(t, err)
+	t.Log(ledger.JsonString())
+
+	payouts, err := chain.FetchStakingPendingPayouts(stash, 2)
+	require.Nil(t, err)
+	t.Log(payouts.JsonString())
+
+	pools, err := chain.FetchNominationPools()
+	require.Nil(t, err)
+	require.Greater(t, pools.Count(), 0)
+	t.Log(AsNominationPool(pools.ValueOf(0)).JsonString())
+
+	member, err := chain.FetchNominationPoolMember(stash)
+	require.Nil(t, err)
+	t.Log(member.JsonString())
+}
+
+func TestTx_StakingTx(t *testing.T) {
+	chain, err := rpcs.polkadot.Chain()
+	require.Nil(t, err)
+	tx, err := chain.GetTx()
+	require.Nil(t, err)
+
+	validator := "13wNbioJt44NKrcQ5ZUrshJqP7TKzQbzZt5nhkeL4joa3PAX"
+	txs := []func() (*Transaction, error){
+		func() (*Transaction, error) { return tx.NewStakingBondTx("10000000000", "Staked") },
+		func() (*Transaction, error) { return tx.NewStakingBondTx("10000000000", accountCase.address0) },
+		func() (*Transaction, error) { return tx.NewStakingBondExtraTx("10000000000") },
+		func() (*Transaction, error) { return tx.NewStakingNominateTx(validator + "," + accountCase.address0) },
+		func() (*Transaction, error) { return tx.NewStakingUnbondTx("10000000000") },
+		func() (*Transaction, error) { return tx.NewStakingWithdrawUnbondedTx(0) },
+		func() (*Transaction, error) { return tx.NewStakingChillTx() },
+		func() (*Transaction, error) { return tx.NewStakingPayoutStakersTx(validator, 1000) },
+		func() (*Transaction, error) { return tx.NewNominationPoolsJoinTx("10000000000", 1) },
+		func() (*Transaction, error) { return tx.NewNominationPoolsClaimPayoutTx() },
+		func() (*Transaction, error) {
+			return tx.NewNominationPoolsUnbondTx(accountCase.address0, "10000000000")
+		},
+	}
+	for _, build := range txs {
+		transaction, err := build()
+		require.Nil(t, err)
+		decoded, err := tx.DecodeExtrinsicJson(mustUnsignedTx(t, transaction), 10, 0)
+		require.Nil(t, err)
+		t.Log(decoded.Value)
+	}
+
+	_, err = tx.NewStakingBondTx("10000000000", "Unknown")
+	require.NotNil(t, err)
+	_, err = tx.NewStakingBondExtraTx("-1")
+	require.NotNil(t, err)
+}
+
+func mustUnsignedTx(t *testing.T, transaction *Transaction) string {
+	txHex, err := transaction.GetUnSignTx()
+	require.Nil(t, err)
+	return txHex
+}
@@ -0,0 +1,233 @@
+package polka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/xxhash"
+)
+
+// The storage layouts of the same pallet may be different in different runtimes,
+// these helpers decode the storage values with the types declared in metadata v14,
+// the composites are decoded to maps, and the accounts are decoded to ss58 addresses.
+
+const queryStorageBatchSize = 200
+
+func storageEntryOfMetadata(metadata *types.Metadata, pallet, item string) (*types.StorageEntryMetadataV14, error) {
+	if metadata.Version != 14 {
+		return nil, fmt.Errorf("cannot find the storage %v.%v, only metadata v14 is supported", pallet, item)
+	}
+	for _, p := range metadata.AsMetadataV14.Pallets {
+		if !p.HasStorage || string(p.Storage.Prefix) != pallet {
+			continue
+		}
+		for i := range p.Storage.Items {
+			if string(p.Storage.Items[i].Name) == item {
+				return &p.Storage.Items[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("storage %v.%v not found in metadata", pallet, item)
+}
+
+func hasStorageEntry(metadata *types.Metadata, pallet, item string) bool {
+	_, err := storageEntryOfMetadata(metadata, pallet, item)
+	return err == nil
+}
+
+// The prefix of all the keys of the storage map, `twox128(pallet) ++ twox128(item)`
+func storagePrefix(pallet, item string) types.StorageKey {
+	return append(xxhash.New128([]byte(pallet)).Sum(nil), xxhash.New128([]byte(item)).Sum(nil)...)
+}
+
+func u32StorageArg(value uint32) []byte {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, value)
+	return data
+}
+
+// Get the connected client with metadata loaded, and the ss58 format of the chain.
+func (c *Chain) storageClient() (cl *polkaclient, network int, err error) {
+	cl, err = getConnectedPolkaClient(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	if err = cl.LoadMetadataIfNotExists(); err != nil {
+		return
+	}
+	network, err = cl.SS58Format()
+	return
+}
+
+// Query the storage values of the keys in batches with `state_queryStorageAt`
+// @return the raw values in the same order as keys, nil if the storage is empty.
+func (c *polkaclient) queryStorageRawValues(keys []types.StorageKey) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	for start := 0; start < len(keys); start += queryStorageBatchSize {
+		end := start + queryStorageBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		hexKeys := make([]string, 0, end-start)
+		for _, key := range keys[start:end] {
+			hexKeys = append(hexKeys, key.Hex())
+		}
+		var sets []types.StorageChangeSet
+		if err := c.api.Client.Call(&sets, "state_queryStorageAt", hexKeys); err != nil {
+			return nil, err
+		}
+		changes := make(map[string][]byte)
+		for _, set := range sets {
+			for _, change := range set.Changes {
+				if change.HasStorageData && len(change.StorageData) > 0 {
+					changes[change.StorageKey.Hex()] = change.StorageData
+				}
+			}
+		}
+		for i, key := range keys[start:end] {
+			values[start+i] = changes[key.Hex()]
+		}
+	}
+	return values, nil
+}
+
+// Query and decode the storage values of the keys.
+// @return the decoded values in the same order as keys, nil if the storage is empty.
+func (c *polkaclient) queryStorageValues(pallet, item string, network int, keys []types.StorageKey) ([]interface{}, error) {
+	entry, err := storageEntryOfMetadata(c.metadata, pallet, item)
+	if err != nil {
+		return nil, err
+	}
+	valueType := entry.Type.AsPlainType
+	if entry.Type.IsMap {
+		valueType = entry.Type.AsMap.Value
+	}
+	rawValues, err := c.queryStorageRawValues(keys)
+	if err != nil {
+		return nil, err
+	}
+	decoder := newExtrinsicDecoder(c.metadata, 0, network)
+	values := make([]interface{}, len(rawValues))
+	for i, raw := range rawValues {
+		if raw == nil {
+			continue
+		}
+		values[i], err = decoder.decodeValue(raw, valueType.Int64())
+		if err != nil {
+			return nil, fmt.Errorf("decode storage %v.%v failed: %v", pallet, item, err)
+		}
+	}
+	return values, nil
+}
+
+// Query and decode the storage value
+// @param args the encoded keys of the storage map
+// @return nil if the storage is empty.
+func (c *polkaclient) queryStorageValue(pallet, item string, network int, args ...[]byte) (interface{}, error) {
+	key, err := types.CreateStorageKey(c.metadata, pallet, item, args...)
+	if err != nil {
+		return nil, err
+	}
+	values, err := c.queryStorageValues(pallet, item, network, []types.StorageKey{key})
+	if err != nil {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// Query all the entries of the storage map.
+// @return the keys and the decoded values
+func (c *polkaclient) queryStorageEntries(pallet, item string, network int) ([]types.StorageKey, []interface{}, error) {
+	keys, err := c.api.RPC.State.GetKeysLatest(storagePrefix(pallet, item))
+	if err != nil {
+		return nil, nil, err
+	}
+	values, err := c.queryStorageValues(pallet, item, network, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys, values, nil
+}
+
+// MARK - decoded value accessors
+
+// Get the field of the decoded composite by path, nil if not found.
+func fieldOfValue(value interface{}, path ...string) interface{} {
+	for _, name := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[name]
+	}
+	return value
+}
+
+// Get the first existing field of the decoded composite, it's used to read the renamed fields.
+func anyFieldOfValue(value interface{}, names ...string) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for _, name := range names {
+		if v, ok := m[name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// @return zero if the value is not a number
+func bigIntOfValue(value interface{}) *big.Int {
+	switch v := value.(type) {
+	case *DecodedBalance:
+		if n, ok := new(big.Int).SetString(v.Value, 10); ok {
+			return n
+		}
+	case string:
+		if n, ok := new(big.Int).SetString(v, 10); ok {
+			return n
+		}
+	case int64:
+		return big.NewInt(v)
+	}
+	return big.NewInt(0)
+}
+
+func int64OfValue(value interface{}) int64 {
+	return bigIntOfValue(value).Int64()
+}
+
+func stringOfValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func listOfValue(value interface{}) []interface{} {
+	if l, ok := value.([]interface{}); ok {
+		return l
+	}
+	return nil
+}
+
+func stringsOfValue(value interface{}) []string {
+	list := listOfValue(value)
+	res := make([]string, 0, len(list))
+	for _, item := range list {
+		res = append(res, stringOfValue(item))
+	}
+	return res
+}
+
+// The bytes are decoded to hex string, e.g. the pool name, the raw data of identity.
+func textOfHexValue(value interface{}) string {
+	data, err := types.HexDecodeString(stringOfValue(value))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
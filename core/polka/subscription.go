@@ -0,0 +1,112 @@
+package polka
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/xxhash"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Submit the signed transaction and watch its status, the message is the json of status,
+// e.g. `"ready"`, `{"inBlock":"0x..."}`, `{"finalized":"0x..."}`.
+// The subscription is finished when the transaction is finalized, dropped or invalid, and it will not reconnect.
+func NewSubmitAndWatchExtrinsicSubscription(wsUrl, signedTx string) *base.Subscription {
+	sub := base.NewSubscription(wsUrl, "author_submitAndWatchExtrinsic", []any{signedTx}, "author_unwatchExtrinsic")
+	// resubmitting the transaction after reconnection is meaningless.
+	sub.ReconnectCount = 0
+	return sub.SetFinished(isExtrinsicStatusFinished)
+}
+
+func isExtrinsicStatusFinished(result json.RawMessage) bool {
+	var status string
+	if json.Unmarshal(result, &status) == nil {
+		return status == "dropped" || status == "invalid"
+	}
+	var statusMap map[string]json.RawMessage
+	if json.Unmarshal(result, &statusMap) != nil {
+		return false
+	}
+	for key := range statusMap {
+		switch key {
+		case "finalized", "usurped", "finalityTimeout", "dropped", "invalid":
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe the balance changes of the address, the message is the json of `base.Balance`.
+func NewAccountBalanceSubscription(wsUrl, address string) (*base.Subscription, error) {
+	key, err := systemAccountStorageKey(address)
+	if err != nil {
+		return nil, err
+	}
+	sub := base.NewSubscription(wsUrl, "state_subscribeStorage", []any{[]string{key}}, "state_unsubscribeStorage")
+	return sub.SetTransform(balanceOfStorageChangeSet), nil
+}
+
+// twox128("System") ++ twox128("Account") ++ blake2_128_concat(accountId)
+func systemAccountStorageKey(address string) (string, error) {
+	publicKey, err := DecodeAddressToPublicKey(address)
+	if err != nil {
+		return "", err
+	}
+	accountId := types.MustHexDecodeString(publicKey)
+	hasher, err := blake2b.New(16, nil)
+	if err != nil {
+		return "", err
+	}
+	hasher.Write(accountId)
+
+	key := append(xxhash.New128([]byte("System")).Sum(nil), xxhash.New128([]byte("Account")).Sum(nil)...)
+	key = append(key, hasher.Sum(nil)...)
+	key = append(key, accountId...)
+	return types.HexEncodeToString(key), nil
+}
+
+func balanceOfStorageChangeSet(result json.RawMessage) (string, error) {
+	var changeSet types.StorageChangeSet
+	if err := json.Unmarshal(result, &changeSet); err != nil {
+		return "", err
+	}
+	balance := base.EmptyBalance()
+	for _, change := range changeSet.Changes {
+		if !change.HasStorageData {
+			continue
+		}
+		info := accountInfo{}
+		if err := types.DecodeFromBytes(change.StorageData, &info); err != nil {
+			return "", err
+		}
+		balance = info.balance()
+	}
+	data, err := json.Marshal(balance)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// The websocket url of the rpc url, e.g. https://rpc.polkadot.io => wss://rpc.polkadot.io
+func websocketUrlOf(rpcUrl string) string {
+	switch {
+	case strings.HasPrefix(rpcUrl, "https://"):
+		return "wss://" + strings.TrimPrefix(rpcUrl, "https://")
+	case strings.HasPrefix(rpcUrl, "http://"):
+		return "ws://" + strings.TrimPrefix(rpcUrl, "http://")
+	}
+	return rpcUrl
+}
+
+// Submit the signed transaction and watch its status with the rpc of the chain.
+func (c *Chain) SubmitAndWatchExtrinsic(signedTx string) *base.Subscription {
+	return NewSubmitAndWatchExtrinsicSubscription(websocketUrlOf(c.RpcUrl), signedTx)
+}
+
+// Subscribe the balance changes of the address with the rpc of the chain.
+func (c *Chain) SubscribeBalanceOfAddress(address string) (*base.Subscription, error) {
+	return NewAccountBalanceSubscription(websocketUrlOf(c.RpcUrl), address)
+}
@@ -0,0 +1,19 @@
+package polka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemAccountStorageKey(t *testing.T) {
+	alice := "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"
+	key, err := systemAccountStorageKey(alice)
+	require.Nil(t, err)
+	require.Equal(t, "0x26aa394eea5630e07c48ae0c9558cef7b99d880ec681799c0cf30e8886371da9de1e86a9a8c739864cf3cc5ec2bea59fd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d", key)
+
+	require.True(t, isExtrinsicStatusFinished([]byte(`{"finalized":"0x01"}`)))
+	require.True(t, isExtrinsicStatusFinished([]byte(`"dropped"`)))
+	require.False(t, isExtrinsicStatusFinished([]byte(`{"inBlock":"0x01"}`)))
+	require.False(t, isExtrinsicStatusFinished([]byte(`"ready"`)))
+}
@@ -1,11 +1,15 @@
 package polka
 
 import (
+	"bytes"
+	"errors"
 	"math/big"
 	"strconv"
 	"strings"
 
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/blake2b"
 )
 
 type Tx struct {
@@ -36,39 +40,93 @@ func (c *Chain) GetTx() (*Tx, error) {
 
 type Transaction struct {
 	extrinsic *types.Extrinsic
+	metadata  *types.Metadata
+
+	// The encoded signed extensions generated by GetSignData, it will be placed after the signature.
+	extra []byte
 }
 
+// Get the data to be signed, the transaction is immortal and the tip is zero.
 func (t *Transaction) GetSignData(genesisHashString string, nonce int64, specVersion, transVersion int32) ([]byte, error) {
-	var methodBytes []byte
+	return t.GetSignDataWithOptions(genesisHashString, nonce, specVersion, transVersion, nil)
+}
+
+// Get the data to be signed, the payload is built with the signed extensions declared by the metadata.
+// @param options the era and tip of the transaction, nil means immortal with zero tip.
+// @return the payload, it's already hashed with blake2_256 if the length is greater than 256
+func (t *Transaction) GetSignDataWithOptions(genesisHashString string, nonce int64, specVersion, transVersion int32, options *SignOptions) ([]byte, error) {
+	if t.extrinsic == nil {
+		return nil, ErrNilExtrinsic
+	}
 	genesisHash, err := types.NewHashFromHexString(genesisHashString)
 	if err != nil {
 		return nil, err
 	}
-	if t.extrinsic == nil {
-		return nil, ErrNilExtrinsic
+	if options == nil {
+		options = NewSignOptions()
+	}
+	values := &signedExtensionValues{
+		era:          types.ExtrinsicEra{IsImmortalEra: true},
+		nonce:        types.NewUCompactFromUInt(uint64(nonce)),
+		tip:          types.NewUCompactFromUInt(0),
+		specVersion:  types.NewU32(uint32(specVersion)),
+		transVersion: types.NewU32(uint32(transVersion)),
+		genesisHash:  genesisHash,
+		blockHash:    genesisHash,
+		assetId:      options.AssetId,
+	}
+	if options.Tip != "" {
+		tip, ok := new(big.Int).SetString(options.Tip, 10)
+		if !ok || tip.Sign() < 0 {
+			return nil, ErrNumber
+		}
+		values.tip = types.NewUCompact(tip)
 	}
-	t.extrinsic.Signature = types.ExtrinsicSignatureV4{
-		Nonce: types.NewUCompactFromUInt(uint64(nonce)),
-		Era:   types.ExtrinsicEra{IsImmortalEra: true},
-		Tip:   types.NewUCompactFromUInt(0),
+	if options.EraPeriod > 0 {
+		if options.BlockNumber < 0 || options.BlockHash == "" {
+			return nil, ErrEraBlock
+		}
+		era, birth := mortalEra(uint64(options.BlockNumber), uint64(options.EraPeriod))
+		if birth != uint64(options.BlockNumber) {
+			return nil, ErrEraBlock
+		}
+		values.era = era
+		values.blockHash, err = types.NewHashFromHexString(options.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if options.MetadataHash != "" {
+		values.metadataHash, err = types.HexDecodeString(options.MetadataHash)
+		if err != nil {
+			return nil, err
+		}
+		if len(values.metadataHash) != 32 {
+			return nil, errors.New("invalid metadata hash")
+		}
+	}
+
+	extra, additional, err := encodeSignedExtensions(t.metadata, values)
+	if err != nil {
+		return nil, err
 	}
-	methodBytes, err = types.EncodeToBytes(t.extrinsic.Method)
+	methodBytes, err := types.EncodeToBytes(t.extrinsic.Method)
 	if err != nil {
 		return nil, err
 	}
-	return types.EncodeToBytes(types.ExtrinsicPayloadV4{
-		ExtrinsicPayloadV3: types.ExtrinsicPayloadV3{
-			Method:      methodBytes,
-			Era:         t.extrinsic.Signature.Era,
-			Nonce:       t.extrinsic.Signature.Nonce,
-			Tip:         t.extrinsic.Signature.Tip,
-			SpecVersion: types.NewU32(uint32(specVersion)),
-			GenesisHash: genesisHash,
-			BlockHash:   genesisHash,
-		},
-		TransactionVersion: types.NewU32(uint32(transVersion)),
-	})
+	t.extrinsic.Signature = types.ExtrinsicSignatureV4{
+		Nonce: values.nonce,
+		Era:   values.era,
+		Tip:   values.tip,
+	}
+	t.extra = extra
 
+	payload := append(append(methodBytes, extra...), additional...)
+	if len(payload) > 256 {
+		hash := blake2b.Sum256(payload)
+		return hash[:], nil
+	}
+	return payload, nil
 }
 
 func (t *Transaction) GetUnSignTx() (string, error) {
@@ -102,7 +160,47 @@ func (t *Transaction) GetTx(signerPublicKey []byte, signatureData []byte) (strin
 	t.extrinsic.Signature.Signer = types.NewMultiAddressFromAccountID(signerPublicKey)
 	t.extrinsic.Signature.Signature = types.MultiSignature{IsSr25519: true, AsSr25519: types.NewSignature(signatureData)}
 	t.extrinsic.Version |= types.ExtrinsicBitSigned
-	return types.EncodeToHexString(t.extrinsic)
+	if t.extra == nil {
+		return types.EncodeToHexString(t.extrinsic)
+	}
+	return t.encodeSignedExtrinsic()
+}
+
+// The `types.Extrinsic` can only encode the signed extensions of `ExtrinsicSignatureV4`,
+// so we need to encode the extrinsic with the extra data generated by the metadata.
+func (t *Transaction) encodeSignedExtrinsic() (string, error) {
+	buffer := bytes.Buffer{}
+	encoder := scale.NewEncoder(&buffer)
+	for _, value := range []interface{}{t.extrinsic.Version, t.extrinsic.Signature.Signer, t.extrinsic.Signature.Signature} {
+		if err := encoder.Encode(value); err != nil {
+			return "", err
+		}
+	}
+	if err := encoder.Write(t.extra); err != nil {
+		return "", err
+	}
+	if err := encoder.Encode(t.extrinsic.Method); err != nil {
+		return "", err
+	}
+
+	encoded := bytes.Buffer{}
+	encoder = scale.NewEncoder(&encoded)
+	if err := encoder.EncodeUintCompact(*big.NewInt(int64(buffer.Len()))); err != nil {
+		return "", err
+	}
+	if err := encoder.Write(buffer.Bytes()); err != nil {
+		return "", err
+	}
+	return types.HexEncodeToString(encoded.Bytes()), nil
+}
+
+// Copy the transaction, so that signing the copy will not change the original transaction.
+func (t *Transaction) copy() *Transaction {
+	if t.extrinsic == nil {
+		return &Transaction{metadata: t.metadata}
+	}
+	extrinsic := *t.extrinsic
+	return &Transaction{extrinsic: &extrinsic, metadata: t.metadata}
 }
 
 func (t *Tx) NewTransactionFromHex(txHex string) (*Transaction, error) {
@@ -114,6 +212,7 @@ func (t *Tx) NewTransactionFromHex(txHex string) (*Transaction, error) {
 		return nil, ErrNilMetadata
 	}
 
+	transaction.metadata = t.metadata
 	transaction.extrinsic = &types.Extrinsic{}
 	err := types.DecodeFromHexString(txHex, &transaction.extrinsic)
 	if err != nil {
@@ -158,6 +257,7 @@ func (t *Tx) NewExtrinsics(call string, args ...interface{}) (*Transaction, erro
 
 	extrinsic := types.NewExtrinsic(callType)
 	transaction.extrinsic = &extrinsic
+	transaction.metadata = t.metadata
 	return transaction, nil
 }
 
@@ -0,0 +1,387 @@
+package polka
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/substrate/types/customscale"
+	"golang.org/x/crypto/blake2b"
+)
+
+// The number of latest blocks to search the extrinsic if the block is unknown, it's same as the default era period.
+const DefaultSearchBlockCount = DefaultEraPeriod
+
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+type rawSignedBlock struct {
+	Block struct {
+		Header     types.Header `json:"header"`
+		Extrinsics []string     `json:"extrinsics"`
+	} `json:"block"`
+}
+
+// Fetch the transaction detail from the rpc node without the scan api.
+// The result is decoded from the events `System.ExtrinsicSuccess`, `System.ExtrinsicFailed`, `Balances.Transfer`
+// and the fee events of the extrinsic.
+// @param hashString the hash of the extrinsic
+// @param blockHashString the hash of the block which contains the extrinsic
+func (c *Chain) FetchTransactionDetailInBlock(hashString, blockHashString string) (detail *base.TransactionDetail, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, err := getConnectedPolkaClient(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	blockHash, err := types.NewHashFromHexString(blockHashString)
+	if err != nil {
+		return
+	}
+	block, err := cl.fetchRawBlock(blockHash)
+	if err != nil {
+		return
+	}
+	index, extrinsic, err := findExtrinsicInBlock(block, hashString)
+	if err != nil {
+		return
+	}
+	return cl.transactionDetailOfExtrinsic(hashString, blockHash, block, index, extrinsic)
+}
+
+// Search the extrinsic in the latest blocks and fetch the detail without the scan api, see `FetchTransactionDetailInBlock`
+// It costs two rpc calls for each searched block (about 130 calls with the default count),
+// and returns `ErrTransactionNotFound` if the extrinsic is older than the searched blocks.
+// @param blockCount the number of latest blocks to search, 0 means `DefaultSearchBlockCount`
+func (c *Chain) SearchTransactionDetail(hashString string, blockCount int) (detail *base.TransactionDetail, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	cl, err := getConnectedPolkaClient(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	if blockCount <= 0 {
+		blockCount = DefaultSearchBlockCount
+	}
+	header, err := cl.api.RPC.Chain.GetHeaderLatest()
+	if err != nil {
+		return
+	}
+	latest := int64(header.Number)
+
+	type found struct {
+		blockHash types.Hash
+		block     *rawSignedBlock
+		index     int
+		extrinsic []byte
+	}
+	// Search from the latest block in batches, the extrinsic is more likely in the recent blocks.
+	const batchSize = 10
+	for start := latest; start > latest-int64(blockCount) && start >= 0; start -= batchSize {
+		numbers := []interface{}{}
+		for n := start; n > start-batchSize && n > latest-int64(blockCount) && n >= 0; n-- {
+			numbers = append(numbers, n)
+		}
+		results, err := base.MapListConcurrent(numbers, batchSize, func(i interface{}) (interface{}, error) {
+			blockHash, err := cl.api.RPC.Chain.GetBlockHash(uint64(i.(int64)))
+			if err != nil {
+				return nil, err
+			}
+			block, err := cl.fetchRawBlock(blockHash)
+			if err != nil {
+				return nil, err
+			}
+			index, extrinsic, err := findExtrinsicInBlock(block, hashString)
+			if err != nil {
+				return nil, nil
+			}
+			return &found{blockHash, block, index, extrinsic}, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range results {
+			if f, ok := res.(*found); ok {
+				return cl.transactionDetailOfExtrinsic(hashString, f.blockHash, f.block, f.index, f.extrinsic)
+			}
+		}
+	}
+	return nil, ErrTransactionNotFound
+}
+
+func (c *polkaclient) fetchRawBlock(blockHash types.Hash) (*rawSignedBlock, error) {
+	var block rawSignedBlock
+	err := c.api.Client.Call(&block, "chain_getBlock", blockHash.Hex())
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	return &block, nil
+}
+
+// The extrinsic hash is the blake2_256 of the encoded extrinsic.
+func findExtrinsicInBlock(block *rawSignedBlock, hashString string) (index int, extrinsic []byte, err error) {
+	hash, err := types.NewHashFromHexString(hashString)
+	if err != nil {
+		return
+	}
+	for i, extrinsicHex := range block.Block.Extrinsics {
+		data, err := types.HexDecodeString(extrinsicHex)
+		if err != nil {
+			return 0, nil, err
+		}
+		if blake2b.Sum256(data) == hash {
+			return i, data, nil
+		}
+	}
+	return 0, nil, ErrTransactionNotFound
+}
+
+func (c *polkaclient) transactionDetailOfExtrinsic(hashString string, blockHash types.Hash, block *rawSignedBlock, index int, extrinsic []byte) (*base.TransactionDetail, error) {
+	err := c.LoadMetadataIfNotExists()
+	if err != nil {
+		return nil, err
+	}
+	network, err := c.SS58Format()
+	if err != nil {
+		return nil, err
+	}
+	signer, err := signerOfExtrinsic(extrinsic)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := types.CreateStorageKey(c.metadata, "System", "Events")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := c.api.RPC.State.GetStorageRaw(key, blockHash)
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	eventRaw := customscale.EventRaw(*raw)
+	events, err := eventRaw.DecodeRaw(c.metadata)
+	if err != nil {
+		return nil, err
+	}
+	extrinsicEvents := make([]*customscale.Event, 0)
+	for _, event := range events {
+		if event.Phase.IsApplyExtrinsic && event.Phase.AsApplyExtrinsic == uint32(index) {
+			extrinsicEvents = append(extrinsicEvents, event)
+		}
+	}
+
+	detail := &base.TransactionDetail{
+		HashString:   hashString,
+		Amount:       "0",
+		EstimateFees: "0",
+		Status:       base.TransactionStatusPending,
+	}
+	if signer != nil {
+		detail.FromAddress, err = EncodePublicKeyToAddress(types.HexEncodeToString(signer), network)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fillDetailWithEvents(c.metadata, detail, extrinsicEvents, signer, network)
+
+	// The result is not reliable until the block is finalized.
+	finalizedHash, err := c.api.RPC.Chain.GetFinalizedHead()
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	finalized, err := c.api.RPC.Chain.GetHeader(finalizedHash)
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	if block.Block.Header.Number > finalized.Number {
+		detail.Status = base.TransactionStatusPending
+	}
+	if detail.Status != base.TransactionStatusPending {
+		var now types.U64
+		nowKey, err := types.CreateStorageKey(c.metadata, "Timestamp", "Now")
+		if err == nil {
+			if ok, err := c.api.RPC.State.GetStorage(nowKey, &now, blockHash); err == nil && ok {
+				detail.FinishTimestamp = int64(now) / 1000
+			}
+		}
+	}
+	return detail, nil
+}
+
+// @return the public key of the signer, nil if the extrinsic is unsigned
+func signerOfExtrinsic(extrinsic []byte) ([]byte, error) {
+	decoder := scale.NewDecoder(bytes.NewReader(extrinsic))
+	if _, err := decoder.DecodeUintCompact(); err != nil {
+		return nil, err
+	}
+	version, err := decoder.ReadOneByte()
+	if err != nil {
+		return nil, err
+	}
+	if version&types.ExtrinsicBitSigned == 0 {
+		return nil, nil
+	}
+	var signer types.MultiAddress
+	if err = decoder.Decode(&signer); err != nil {
+		return nil, err
+	}
+	switch {
+	case signer.IsID:
+		return signer.AsID[:], nil
+	case signer.IsAddress32:
+		return signer.AsAddress32[:], nil
+	}
+	return nil, nil
+}
+
+func fillDetailWithEvents(metadata *types.Metadata, detail *base.TransactionDetail, events []*customscale.Event, signer []byte, network int) {
+	var withdrawFee *big.Int
+	for _, event := range events {
+		args := event.EventData.Args
+		switch string(event.EventData.ModuleName) + "." + string(event.EventData.EventName) {
+		case "System.ExtrinsicSuccess":
+			detail.Status = base.TransactionStatusSuccess
+		case "System.ExtrinsicFailed":
+			detail.Status = base.TransactionStatusFailure
+			detail.FailureMessage = dispatchErrorMessage(metadata, eventArg(args, "dispatch_error", 0))
+		case "Balances.Transfer":
+			from, _ := bytesOfEventArg(eventArg(args, "from", 0))
+			if detail.ToAddress != "" || (signer != nil && !bytes.Equal(from, signer)) {
+				continue
+			}
+			to, _ := bytesOfEventArg(eventArg(args, "to", 1))
+			detail.ToAddress, _ = EncodePublicKeyToAddress(types.HexEncodeToString(to), network)
+			if amount := bigIntOfEventArg(eventArg(args, "amount", 2)); amount != nil {
+				detail.Amount = amount.String()
+			}
+		case "TransactionPayment.TransactionFeePaid":
+			if fee := bigIntOfEventArg(eventArg(args, "actual_fee", 1)); fee != nil {
+				detail.EstimateFees = fee.String()
+			}
+		case "Balances.Withdraw":
+			// The chains without event `TransactionFeePaid` withdraw the fee from the signer.
+			who, _ := bytesOfEventArg(eventArg(args, "who", 0))
+			if signer != nil && bytes.Equal(who, signer) && withdrawFee == nil {
+				withdrawFee = bigIntOfEventArg(eventArg(args, "amount", 1))
+			}
+		}
+	}
+	if detail.EstimateFees == "0" && withdrawFee != nil {
+		detail.EstimateFees = withdrawFee.String()
+	}
+}
+
+// Find the event argument by name, or by index if the fields have no names.
+func eventArg(args []*customscale.CallArg, name string, index int) interface{} {
+	for _, arg := range args {
+		if arg.FieldName == name {
+			return arg.Value
+		}
+	}
+	if index < len(args) && strings.HasPrefix(args[index].FieldName, "unknown") {
+		return args[index].Value
+	}
+	return nil
+}
+
+// The account id is decoded as nested arrays of `types.U8`
+func bytesOfEventArg(value interface{}) ([]byte, bool) {
+	switch v := value.(type) {
+	case types.U8:
+		return []byte{byte(v)}, true
+	case []interface{}:
+		data := make([]byte, 0, len(v))
+		for _, item := range v {
+			itemBytes, ok := bytesOfEventArg(item)
+			if !ok {
+				return nil, false
+			}
+			data = append(data, itemBytes...)
+		}
+		return data, true
+	}
+	return nil, false
+}
+
+func bigIntOfEventArg(value interface{}) *big.Int {
+	switch v := value.(type) {
+	case types.U128:
+		return v.Int
+	case types.U64:
+		return new(big.Int).SetUint64(uint64(v))
+	case types.U32:
+		return big.NewInt(int64(v))
+	case types.UCompact:
+		bigValue := big.Int(v)
+		return &bigValue
+	case []interface{}:
+		// a composite type wrapped the number
+		if len(v) == 1 {
+			return bigIntOfEventArg(v[0])
+		}
+	}
+	return nil
+}
+
+// e.g. "Balances.InsufficientBalance: Balance too low to send value.", "BadOrigin", "Token.FundsUnavailable"
+func dispatchErrorMessage(metadata *types.Metadata, dispatchError interface{}) string {
+	variant, ok := dispatchError.(customscale.Variants)
+	if !ok {
+		return "unknown error"
+	}
+	if variant.MethodName == "Module" {
+		fields := variant.Value
+		if len(fields) == 1 {
+			if inner, ok := fields[0].([]interface{}); ok {
+				fields = inner
+			}
+		}
+		if len(fields) >= 2 {
+			palletIndex, ok1 := bytesOfEventArg(fields[0])
+			errorIndex, ok2 := bytesOfEventArg(fields[1])
+			if ok1 && ok2 && len(palletIndex) > 0 && len(errorIndex) > 0 {
+				if message, ok := moduleErrorMessage(metadata, palletIndex[0], errorIndex[0]); ok {
+					return message
+				}
+				return fmt.Sprintf("Module error, index: %v, error: %v", palletIndex[0], errorIndex[0])
+			}
+		}
+	}
+	message := variant.MethodName
+	for _, value := range variant.Value {
+		if inner, ok := value.(customscale.Variants); ok {
+			message += "." + dispatchErrorMessage(metadata, inner)
+		}
+	}
+	return message
+}
+
+func moduleErrorMessage(metadata *types.Metadata, palletIndex, errorIndex uint8) (string, bool) {
+	if metadata.Version != 14 {
+		return "", false
+	}
+	for _, pallet := range metadata.AsMetadataV14.Pallets {
+		if !pallet.HasErrors || uint8(pallet.Index) != palletIndex {
+			continue
+		}
+		errorType, ok := metadata.AsMetadataV14.EfficientLookup[pallet.Errors.Type.Int64()]
+		if !ok {
+			return "", false
+		}
+		for _, variant := range errorType.Def.Variant.Variants {
+			if uint8(variant.Index) != errorIndex {
+				continue
+			}
+			message := fmt.Sprintf("%v.%v", pallet.Name, variant.Name)
+			if len(variant.Docs) > 0 {
+				message += ": " + strings.TrimSpace(string(variant.Docs[0]))
+			}
+			return message, true
+		}
+	}
+	return "", false
+}
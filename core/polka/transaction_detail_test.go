@@ -0,0 +1,71 @@
+package polka
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/substrate/types/customscale"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFillDetailWithEvents(t *testing.T) {
+	accountArg := func(b byte) interface{} {
+		bytes := make([]interface{}, 32)
+		for i := range bytes {
+			bytes[i] = types.U8(b)
+		}
+		return []interface{}{bytes}
+	}
+	event := func(module, name string, args ...*customscale.CallArg) *customscale.Event {
+		return &customscale.Event{EventData: &customscale.EventData{ModuleName: types.Text(module), EventName: types.Text(name), Args: args}}
+	}
+	signer := make32(1)
+
+	detail := &base.TransactionDetail{Amount: "0", EstimateFees: "0"}
+	fillDetailWithEvents(nil, detail, []*customscale.Event{
+		event("Balances", "Withdraw", &customscale.CallArg{FieldName: "who", Value: accountArg(1)}, &customscale.CallArg{FieldName: "amount", Value: types.NewU128(*big.NewInt(123))}),
+		event("Balances", "Transfer",
+			&customscale.CallArg{FieldName: "from", Value: accountArg(1)},
+			&customscale.CallArg{FieldName: "to", Value: accountArg(2)},
+			&customscale.CallArg{FieldName: "amount", Value: types.NewU128(*big.NewInt(10000000000))}),
+		event("TransactionPayment", "TransactionFeePaid",
+			&customscale.CallArg{FieldName: "who", Value: accountArg(1)},
+			&customscale.CallArg{FieldName: "actual_fee", Value: types.NewU128(*big.NewInt(156))},
+			&customscale.CallArg{FieldName: "tip", Value: types.NewU128(*big.NewInt(0))}),
+		event("System", "ExtrinsicSuccess"),
+	}, signer, 0)
+
+	require.Equal(t, base.TransactionStatusSuccess, detail.Status)
+	require.Equal(t, "10000000000", detail.Amount)
+	require.Equal(t, "156", detail.EstimateFees)
+	to, _ := EncodePublicKeyToAddress(types.HexEncodeToString(make32(2)), 0)
+	require.Equal(t, to, detail.ToAddress)
+
+	// failed with a module error, the fee is withdrawn without TransactionFeePaid
+	detail = &base.TransactionDetail{Amount: "0", EstimateFees: "0"}
+	fillDetailWithEvents(types.NewMetadataV14(), detail, []*customscale.Event{
+		event("Balances", "Withdraw", &customscale.CallArg{FieldName: "who", Value: accountArg(1)}, &customscale.CallArg{FieldName: "amount", Value: types.NewU128(*big.NewInt(123))}),
+		event("System", "ExtrinsicFailed", &customscale.CallArg{FieldName: "dispatch_error", Value: customscale.Variants{
+			MethodName: "Module",
+			Value:      []interface{}{[]interface{}{types.U8(5), []interface{}{types.U8(2), types.U8(0), types.U8(0), types.U8(0)}}},
+		}}),
+	}, signer, 0)
+	require.Equal(t, base.TransactionStatusFailure, detail.Status)
+	require.Equal(t, "123", detail.EstimateFees)
+	require.Equal(t, "Module error, index: 5, error: 2", detail.FailureMessage)
+
+	require.Equal(t, "Token.FundsUnavailable", dispatchErrorMessage(nil, customscale.Variants{
+		MethodName: "Token",
+		Value:      []interface{}{customscale.Variants{MethodName: "FundsUnavailable"}},
+	}))
+}
+
+func make32(b byte) []byte {
+	data := make([]byte, 32)
+	for i := range data {
+		data[i] = b
+	}
+	return data
+}
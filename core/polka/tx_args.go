@@ -0,0 +1,224 @@
+package polka
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/substrate/types/customscale"
+)
+
+// The arguments of the same call may have different types in different runtimes,
+// these helpers read the argument types from metadata v14 to encode the arguments.
+
+// @param call e.g. "Proxy.proxy"
+func callFieldsOfMetadata(metadata *types.Metadata, call string) ([]types.Si1Field, error) {
+	if metadata.Version != 14 {
+		return nil, fmt.Errorf("cannot find the arguments of call %v, only metadata v14 is supported", call)
+	}
+	names := strings.Split(call, ".")
+	if len(names) != 2 {
+		return nil, fmt.Errorf("invalid call name %v", call)
+	}
+	for _, pallet := range metadata.AsMetadataV14.Pallets {
+		if !pallet.HasCalls || string(pallet.Name) != names[0] {
+			continue
+		}
+		callType, ok := metadata.AsMetadataV14.EfficientLookup[pallet.Calls.Type.Int64()]
+		if !ok {
+			break
+		}
+		for _, variant := range callType.Def.Variant.Variants {
+			if string(variant.Name) == names[1] {
+				return variant.Fields, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("call %v not found in metadata", call)
+}
+
+func typeOfCallField(metadata *types.Metadata, call, fieldName string) (*types.Si1Type, error) {
+	fields, err := callFieldsOfMetadata(metadata, call)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		if string(field.Name) == fieldName {
+			if si1Type, ok := metadata.AsMetadataV14.EfficientLookup[field.Type.Int64()]; ok {
+				return si1Type, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("argument %v of call %v not found in metadata", fieldName, call)
+}
+
+func lastPathOfType(si1Type *types.Si1Type) string {
+	if len(si1Type.Path) == 0 {
+		return ""
+	}
+	return string(si1Type.Path[len(si1Type.Path)-1])
+}
+
+// Encode the address as the argument type, it's `MultiAddress` in the new runtimes and `AccountId` in the old runtimes.
+func (t *Tx) accountArg(call, fieldName, address string) (interface{}, error) {
+	publicKey, err := DecodeAddressToPublicKey(address)
+	if err != nil {
+		return nil, err
+	}
+	if fieldType, err := typeOfCallField(t.metadata, call, fieldName); err == nil && lastPathOfType(fieldType) == "AccountId32" {
+		return types.NewAccountID(types.MustHexDecodeString(publicKey)), nil
+	}
+	return types.NewMultiAddressFromHexAccountID(publicKey)
+}
+
+// Find the index of the enum variant by name, the enum can be wrapped in `Option`.
+// e.g. the `ProxyType` of `Proxy.add_proxy`, the `RewardDestination` of `Staking.bond`
+func (t *Tx) enumIndexArg(call, fieldName, variantName string) (types.U8, error) {
+	fieldType, err := typeOfCallField(t.metadata, call, fieldName)
+	if err != nil {
+		return 0, err
+	}
+	if lastPathOfType(fieldType) == "Option" {
+		for _, variant := range fieldType.Def.Variant.Variants {
+			if string(variant.Name) == "Some" && len(variant.Fields) == 1 {
+				fieldType = customscale.GetSi1TypeFromMetadata(t.metadata, variant.Fields[0].Type)
+				break
+			}
+		}
+	}
+	if fieldType == nil || !fieldType.Def.IsVariant {
+		return 0, fmt.Errorf("argument %v of call %v is not an enum", fieldName, call)
+	}
+	names := make([]string, 0, len(fieldType.Def.Variant.Variants))
+	for _, variant := range fieldType.Def.Variant.Variants {
+		if strings.EqualFold(string(variant.Name), variantName) {
+			return variant.Index, nil
+		}
+		names = append(names, string(variant.Name))
+	}
+	return 0, fmt.Errorf("invalid %v: %v, it should be one of %v", fieldName, variantName, strings.Join(names, ", "))
+}
+
+// The argument has been encoded, it will be written to the call directly.
+type encodedArg []byte
+
+func (e encodedArg) Encode(encoder scale.Encoder) error {
+	return encoder.Write(e)
+}
+
+// Create the call with the arguments in the order declared by metadata.
+// The arguments not declared in metadata will be ignored, so it can be compatible with different runtimes.
+func (t *Tx) newExtrinsicsWithNamedArgs(call string, args map[string]interface{}) (*Transaction, error) {
+	fields, err := callFieldsOfMetadata(t.metadata, call)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		value, ok := args[string(field.Name)]
+		if !ok {
+			return nil, fmt.Errorf("the argument %v of call %v is not supported", field.Name, call)
+		}
+		values = append(values, value)
+	}
+	return t.NewExtrinsics(call, values...)
+}
+
+// Encode the weight as the argument type, it's `Weight { ref_time, proof_size }` in weight v2 and `u64` in weight v1.
+func (t *Tx) weightArg(call, fieldName string, refTime, proofSize uint64) (interface{}, error) {
+	fieldType, err := typeOfCallField(t.metadata, call, fieldName)
+	if err != nil {
+		return nil, err
+	}
+	encodeNumber := func(si1Type *types.Si1Type, value uint64) (interface{}, error) {
+		switch {
+		case si1Type == nil:
+		case si1Type.Def.IsCompact:
+			return types.NewUCompactFromUInt(value), nil
+		case si1Type.Def.IsPrimitive && si1Type.Def.Primitive.Si0TypeDefPrimitive == types.IsU64:
+			return types.NewU64(value), nil
+		}
+		return nil, fmt.Errorf("the weight type of call %v is not supported", call)
+	}
+	if !fieldType.Def.IsComposite {
+		return encodeNumber(fieldType, refTime)
+	}
+	encoded := []byte{}
+	for _, field := range fieldType.Def.Composite.Fields {
+		value := refTime
+		if field.Name == "proof_size" {
+			value = proofSize
+		}
+		arg, err := encodeNumber(customscale.GetSi1TypeFromMetadata(t.metadata, field.Type), value)
+		if err != nil {
+			return nil, err
+		}
+		data, err := types.EncodeToBytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, data...)
+	}
+	return encodedArg(encoded), nil
+}
+
+// Encode the call as the argument type, it's `Box<RuntimeCall>` in the new runtimes and `OpaqueCall` (encoded bytes) in the old runtimes.
+func (t *Tx) callArg(call, fieldName string, value types.Call) (interface{}, error) {
+	fieldType, err := typeOfCallField(t.metadata, call, fieldName)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(lastPathOfType(fieldType), "WrapperKeepOpaque") || lastPathOfType(fieldType) == "WrapperOpaque" {
+		data, err := types.EncodeToBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewBytes(data), nil
+	}
+	return value, nil
+}
+
+// Encode the addresses as the argument type, it's `Vec<MultiAddress>` in the new runtimes and `Vec<AccountId>` in the old runtimes.
+func (t *Tx) accountListArg(call, fieldName string, addresses []string) (interface{}, error) {
+	fieldType, err := typeOfCallField(t.metadata, call, fieldName)
+	if err != nil {
+		return nil, err
+	}
+	if !fieldType.Def.IsSequence {
+		return nil, fmt.Errorf("argument %v of call %v is not a list", fieldName, call)
+	}
+	itemType := customscale.GetSi1TypeFromMetadata(t.metadata, fieldType.Def.Sequence.Type)
+	isAccountId := itemType != nil && lastPathOfType(itemType) == "AccountId32"
+	encoded, err := types.EncodeToBytes(types.NewUCompactFromUInt(uint64(len(addresses))))
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		publicKey, err := DecodeAddressToPublicKey(address)
+		if err != nil {
+			return nil, err
+		}
+		var item interface{} = types.NewAccountID(types.MustHexDecodeString(publicKey))
+		if !isAccountId {
+			if item, err = types.NewMultiAddressFromHexAccountID(publicKey); err != nil {
+				return nil, err
+			}
+		}
+		data, err := types.EncodeToBytes(item)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, data...)
+	}
+	return encodedArg(encoded), nil
+}
+
+func compactBalanceArg(amount string) (types.UCompact, error) {
+	amountBigint, ok := new(big.Int).SetString(amount, 10)
+	if !ok || amountBigint.Sign() < 0 {
+		return types.UCompact{}, ErrNumber
+	}
+	return types.NewUCompact(amountBigint), nil
+}
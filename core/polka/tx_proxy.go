@@ -0,0 +1,60 @@
+package polka
+
+import (
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// Dispatch the call of transaction through the proxy account, the signer should be the proxy (delegate) account.
+// @param real the address of the account that the call is dispatched from
+// @param forceProxyType the proxy type name, e.g. "Any", "NonTransfer", "Staking"; empty means any type which is allowed.
+// @param transaction the call to be dispatched
+func (t *Tx) NewProxyTx(real, forceProxyType string, transaction *Transaction) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	const callName = "Proxy.proxy"
+	realArg, err := t.accountArg(callName, "real", real)
+	if err != nil {
+		return nil, err
+	}
+	proxyType := types.NewOptionU8Empty()
+	if forceProxyType != "" {
+		index, err := t.enumIndexArg(callName, "force_proxy_type", forceProxyType)
+		if err != nil {
+			return nil, err
+		}
+		proxyType = types.NewOptionU8(index)
+	}
+	call, err := transaction.call()
+	if err != nil {
+		return nil, err
+	}
+	return t.NewExtrinsics(callName, realArg, proxyType, call)
+}
+
+// Register the delegate as a proxy of the signer.
+// @param proxyType the proxy type name, e.g. "Any", "NonTransfer", "Staking"
+// @param delay the number of blocks that the announcement must be in place before the call can be dispatched, 0 means no announcement.
+func (t *Tx) NewAddProxyTx(delegate, proxyType string, delay int32) (*Transaction, error) {
+	return t.newProxyManagementTx("Proxy.add_proxy", delegate, proxyType, delay)
+}
+
+// Unregister the proxy of the signer, the arguments must be the same as `NewAddProxyTx`
+func (t *Tx) NewRemoveProxyTx(delegate, proxyType string, delay int32) (*Transaction, error) {
+	return t.newProxyManagementTx("Proxy.remove_proxy", delegate, proxyType, delay)
+}
+
+func (t *Tx) newProxyManagementTx(callName, delegate, proxyType string, delay int32) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	delegateArg, err := t.accountArg(callName, "delegate", delegate)
+	if err != nil {
+		return nil, err
+	}
+	proxyTypeIndex, err := t.enumIndexArg(callName, "proxy_type", proxyType)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewExtrinsics(callName, delegateArg, proxyTypeIndex, types.NewU32(uint32(delay)))
+}
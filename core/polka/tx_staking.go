@@ -0,0 +1,145 @@
+package polka
+
+import (
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// MARK - Staking
+
+// Bond the funds of the signer (the stash) for staking, the signer is also the controller.
+// @param payee the reward destination, "Staked", "Stash", "None" or an address to receive the rewards.
+func (t *Tx) NewStakingBondTx(value, payee string) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	const callName = "Staking.bond"
+	valueArg, err := compactBalanceArg(value)
+	if err != nil {
+		return nil, err
+	}
+	payeeArg, err := t.rewardDestinationArg(callName, "payee", payee)
+	if err != nil {
+		return nil, err
+	}
+	return t.newExtrinsicsWithNamedArgs(callName, map[string]interface{}{
+		"value": valueArg,
+		"payee": payeeArg,
+	})
+}
+
+// Add more funds to the bonded stake of the signer.
+func (t *Tx) NewStakingBondExtraTx(maxAdditional string) (*Transaction, error) {
+	valueArg, err := compactBalanceArg(maxAdditional)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewExtrinsics("Staking.bond_extra", valueArg)
+}
+
+// Nominate the validators with the bonded funds.
+// @param targets the addresses of validators, separated by ","
+func (t *Tx) NewStakingNominateTx(targets string) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	addresses := make([]string, 0)
+	for _, address := range strings.Split(targets, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	targetsArg, err := t.accountListArg("Staking.nominate", "targets", addresses)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewExtrinsics("Staking.nominate", targetsArg)
+}
+
+// Schedule the funds to be unbonded, they can be withdrawn after the bonding duration.
+func (t *Tx) NewStakingUnbondTx(value string) (*Transaction, error) {
+	valueArg, err := compactBalanceArg(value)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewExtrinsics("Staking.unbond", valueArg)
+}
+
+// Withdraw the unbonded funds which have passed the bonding duration.
+// @param numSlashingSpans the number of slashing spans of the stash, it's usually 0.
+func (t *Tx) NewStakingWithdrawUnbondedTx(numSlashingSpans int32) (*Transaction, error) {
+	return t.NewExtrinsics("Staking.withdraw_unbonded", types.NewU32(uint32(numSlashingSpans)))
+}
+
+// Stop nominating and validating.
+func (t *Tx) NewStakingChillTx() (*Transaction, error) {
+	return t.NewExtrinsics("Staking.chill")
+}
+
+// Pay out the rewards of the era for the validator and its nominators, anyone can call it.
+func (t *Tx) NewStakingPayoutStakersTx(validatorStash string, era int32) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	const callName = "Staking.payout_stakers"
+	validatorArg, err := t.accountArg(callName, "validator_stash", validatorStash)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewExtrinsics(callName, validatorArg, types.NewU32(uint32(era)))
+}
+
+// Encode the `RewardDestination`, the `Account` variant carries the address.
+func (t *Tx) rewardDestinationArg(call, fieldName, payee string) (interface{}, error) {
+	variant := payee
+	if IsValidAddress(payee) {
+		variant = "Account"
+	}
+	index, err := t.enumIndexArg(call, fieldName, variant)
+	if err != nil {
+		return nil, err
+	}
+	if variant != "Account" {
+		return index, nil
+	}
+	publicKey, err := DecodeAddressToPublicKey(payee)
+	if err != nil {
+		return nil, err
+	}
+	return encodedArg(append([]byte{byte(index)}, types.MustHexDecodeString(publicKey)...)), nil
+}
+
+// MARK - NominationPools
+
+// Join the nomination pool with the funds.
+func (t *Tx) NewNominationPoolsJoinTx(amount string, poolId int32) (*Transaction, error) {
+	amountArg, err := compactBalanceArg(amount)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewExtrinsics("NominationPools.join", amountArg, types.NewU32(uint32(poolId)))
+}
+
+// Claim the pending rewards of the signer from the pool.
+func (t *Tx) NewNominationPoolsClaimPayoutTx() (*Transaction, error) {
+	return t.NewExtrinsics("NominationPools.claim_payout")
+}
+
+// Unbond the points of the member, the funds can be withdrawn after the bonding duration.
+// @param memberAccount the address of the member, it's usually the signer.
+func (t *Tx) NewNominationPoolsUnbondTx(memberAccount, unbondingPoints string) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	const callName = "NominationPools.unbond"
+	memberArg, err := t.accountArg(callName, "member_account", memberAccount)
+	if err != nil {
+		return nil, err
+	}
+	pointsArg, err := compactBalanceArg(unbondingPoints)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewExtrinsics(callName, memberArg, pointsArg)
+}
@@ -0,0 +1,64 @@
+package polka
+
+import (
+	"errors"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+func (t *Transaction) AsAny() *base.Any {
+	return &base.Any{Value: t}
+}
+
+func AsTransaction(a *base.Any) *Transaction {
+	if r, ok := a.Value.(*Transaction); ok {
+		return r
+	}
+	return nil
+}
+
+// The call of the transaction, it can be wrapped in another call, e.g. `Utility.batch`, `Proxy.proxy`
+func (t *Transaction) call() (types.Call, error) {
+	if t.extrinsic == nil {
+		return types.Call{}, ErrNilExtrinsic
+	}
+	return t.extrinsic.Method, nil
+}
+
+// Dispatch the calls in order, it stops at the first failed call, but the succeeded calls will not be reverted.
+// @param transactions the array of `*Transaction`
+func (t *Tx) NewUtilityBatchTx(transactions *base.AnyArray) (*Transaction, error) {
+	return t.newUtilityBatchTx("Utility.batch", transactions)
+}
+
+// Dispatch the calls atomically, all calls will be reverted if any call failed.
+// @param transactions the array of `*Transaction`
+func (t *Tx) NewUtilityBatchAllTx(transactions *base.AnyArray) (*Transaction, error) {
+	return t.newUtilityBatchTx("Utility.batch_all", transactions)
+}
+
+// Dispatch all the calls, the failed calls will not stop the others.
+// @param transactions the array of `*Transaction`
+func (t *Tx) NewUtilityForceBatchTx(transactions *base.AnyArray) (*Transaction, error) {
+	return t.newUtilityBatchTx("Utility.force_batch", transactions)
+}
+
+func (t *Tx) newUtilityBatchTx(call string, transactions *base.AnyArray) (*Transaction, error) {
+	if transactions == nil || transactions.Count() == 0 {
+		return nil, errors.New("the transactions to be batched is empty")
+	}
+	calls := make([]types.Call, 0, transactions.Count())
+	for _, value := range transactions.Values {
+		transaction, ok := value.(*Transaction)
+		if !ok {
+			return nil, errors.New("the value in the array is not a transaction")
+		}
+		innerCall, err := transaction.call()
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, innerCall)
+	}
+	return t.NewExtrinsics(call, calls)
+}
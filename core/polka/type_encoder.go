@@ -0,0 +1,220 @@
+package polka
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// The value of enum, e.g. `variantValue{"Parachain", 1000}`; the unit variant can also be given as its name.
+type variantValue struct {
+	name  string
+	value interface{}
+}
+
+// Encode the value with the type declared in metadata v14, it's the reverse of `extrinsicDecoder.decodeType`.
+//   - composite: map[string]interface{} for named fields, []interface{} for unnamed fields, a single unnamed field can be given as the value itself.
+//   - variant: variantValue or the name of unit variant; `Option` is nil for None, otherwise the value itself.
+//   - sequence, array and tuple: []interface{}; []byte for u8 items.
+//   - number: int, int64, uint32, uint64, *big.Int or decimal string.
+func encodeType(metadata *types.Metadata, typeId int64, value interface{}) ([]byte, error) {
+	si1Type, ok := metadata.AsMetadataV14.EfficientLookup[typeId]
+	if !ok {
+		return nil, fmt.Errorf("type %v not found", typeId)
+	}
+	def := si1Type.Def
+	switch {
+	case def.IsPrimitive:
+		return encodePrimitive(def.Primitive.Si0TypeDefPrimitive, value)
+	case def.IsCompact:
+		number, err := bigIntOfArg(value)
+		if err != nil {
+			return nil, err
+		}
+		return types.EncodeToBytes(types.NewUCompact(number))
+
+	case def.IsSequence:
+		if data, ok := value.([]byte); ok {
+			return types.EncodeToBytes(types.NewBytes(data))
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the value of type %v should be a list", typeId)
+		}
+		prefix, err := types.EncodeToBytes(types.NewUCompactFromUInt(uint64(len(list))))
+		if err != nil {
+			return nil, err
+		}
+		return encodeList(metadata, def.Sequence.Type.Int64(), list, prefix)
+	case def.IsArray:
+		if data, ok := value.([]byte); ok {
+			if len(data) != int(def.Array.Len) {
+				return nil, fmt.Errorf("the length of type %v should be %v", typeId, def.Array.Len)
+			}
+			return data, nil
+		}
+		list, ok := value.([]interface{})
+		if !ok || len(list) != int(def.Array.Len) {
+			return nil, fmt.Errorf("the value of type %v should be a list with length %v", typeId, def.Array.Len)
+		}
+		return encodeList(metadata, def.Array.Type.Int64(), list, nil)
+	case def.IsTuple:
+		list, ok := value.([]interface{})
+		if !ok || len(list) != len(def.Tuple) {
+			return nil, fmt.Errorf("the value of type %v should be a list with length %v", typeId, len(def.Tuple))
+		}
+		encoded := []byte{}
+		for i, itemType := range def.Tuple {
+			data, err := encodeType(metadata, itemType.Int64(), list[i])
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, data...)
+		}
+		return encoded, nil
+
+	case def.IsComposite:
+		return encodeFields(metadata, def.Composite.Fields, value)
+	case def.IsVariant:
+		if lastPathOfType(si1Type) == "Option" {
+			if value == nil {
+				return []byte{0}, nil
+			}
+			value = variantValue{name: "Some", value: value}
+		}
+		variant, ok := value.(variantValue)
+		if !ok {
+			name, isName := value.(string)
+			if !isName {
+				return nil, fmt.Errorf("the value of type %v should be an enum", typeId)
+			}
+			variant = variantValue{name: name}
+		}
+		for _, v := range def.Variant.Variants {
+			if string(v.Name) != variant.name {
+				continue
+			}
+			data, err := encodeFields(metadata, v.Fields, variant.value)
+			if err != nil {
+				return nil, err
+			}
+			return append([]byte{byte(v.Index)}, data...), nil
+		}
+		return nil, fmt.Errorf("variant %v of type %v not found", variant.name, typeId)
+	}
+	return nil, fmt.Errorf("type %v is not supported", typeId)
+}
+
+func encodeList(metadata *types.Metadata, itemTypeId int64, list []interface{}, prefix []byte) ([]byte, error) {
+	encoded := prefix
+	for _, item := range list {
+		data, err := encodeType(metadata, itemTypeId, item)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, data...)
+	}
+	return encoded, nil
+}
+
+func encodeFields(metadata *types.Metadata, fields []types.Si1Field, value interface{}) ([]byte, error) {
+	switch {
+	case len(fields) == 0:
+		return []byte{}, nil
+	case len(fields) == 1 && !fields[0].HasName:
+		return encodeType(metadata, fields[0].Type.Int64(), value)
+	case fields[0].HasName:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the value of fields %v should be a map", fields[0].Name)
+		}
+		encoded := []byte{}
+		for _, field := range fields {
+			v, ok := values[string(field.Name)]
+			if !ok {
+				return nil, fmt.Errorf("the value of field %v not found", field.Name)
+			}
+			data, err := encodeType(metadata, field.Type.Int64(), v)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, data...)
+		}
+		return encoded, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok || len(list) != len(fields) {
+		return nil, fmt.Errorf("the value of fields should be a list with length %v", len(fields))
+	}
+	encoded := []byte{}
+	for i, field := range fields {
+		data, err := encodeType(metadata, field.Type.Int64(), list[i])
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, data...)
+	}
+	return encoded, nil
+}
+
+func encodePrimitive(primitive types.Si0TypeDefPrimitive, value interface{}) ([]byte, error) {
+	switch primitive {
+	case types.IsBool:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("the value should be a bool")
+		}
+		return types.EncodeToBytes(b)
+	case types.IsStr:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("the value should be a string")
+		}
+		return types.EncodeToBytes(s)
+	}
+	size := map[types.Si0TypeDefPrimitive]int{
+		types.IsU8: 1, types.IsU16: 2, types.IsU32: 4, types.IsU64: 8, types.IsU128: 16, types.IsU256: 32,
+	}[primitive]
+	if size == 0 {
+		return nil, fmt.Errorf("primitive type %v is not supported", primitive)
+	}
+	number, err := bigIntOfArg(value)
+	if err != nil {
+		return nil, err
+	}
+	if number.Sign() < 0 || number.BitLen() > size*8 {
+		return nil, fmt.Errorf("the number %v is out of range", number)
+	}
+	data := make([]byte, size)
+	number.FillBytes(data)
+	// big endian to little endian
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+	return data, nil
+}
+
+func bigIntOfArg(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int32:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint8:
+		return big.NewInt(int64(v)), nil
+	case uint32:
+		return big.NewInt(int64(v)), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case string:
+		if n, ok := new(big.Int).SetString(v, 10); ok {
+			return n, nil
+		}
+	}
+	return nil, ErrNumber
+}
@@ -35,7 +35,15 @@ func (t *XBTCToken) BalanceOfAddress(address string) (*base.Balance, error) {
 }
 
 func (t *XBTCToken) BalanceOfPublicKey(publicKey string) (*base.Balance, error) {
-	address, err := EncodePublicKeyToAddress(publicKey, 44)
+	client, err := getConnectedPolkaClient(t.chain.RpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	network, err := client.SS58Format()
+	if err != nil {
+		return nil, err
+	}
+	address, err := EncodePublicKeyToAddress(publicKey, network)
 	if err != nil {
 		return nil, err
 	}
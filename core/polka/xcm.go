@@ -0,0 +1,379 @@
+package polka
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	XcmJunctionParachain      = "Parachain"
+	XcmJunctionAccountId32    = "AccountId32"
+	XcmJunctionAccountKey20   = "AccountKey20"
+	XcmJunctionPalletInstance = "PalletInstance"
+	XcmJunctionGeneralIndex   = "GeneralIndex"
+)
+
+// The number of instructions executed on the destination of reserve transfer and teleport,
+// (ReserveAssetDeposited | ReceiveTeleportedAsset), ClearOrigin, BuyExecution, DepositAsset
+const XcmTransferInstructionCount = 4
+
+// The weight of each xcm instruction, it's the `BaseXcmWeight` of polkadot & kusama.
+const (
+	xcmInstructionRefTime   uint64 = 1000000000
+	xcmInstructionProofSize uint64 = 64 * 1024
+)
+
+type XcmJunction struct {
+	// e.g. XcmJunctionParachain, XcmJunctionAccountId32
+	Type string `json:"type"`
+	// The number of Parachain, PalletInstance and GeneralIndex; the address of AccountId32 and AccountKey20.
+	Value string `json:"value"`
+}
+
+// The MultiLocation relative to the current chain, e.g.
+//   - the relay chain from the parachain: { parents: 1, junctions: [] }
+//   - the parachain 1000 from the relay chain: { parents: 0, junctions: [Parachain(1000)] }
+//   - the asset 1984 on the asset hub: { parents: 0, junctions: [PalletInstance(50), GeneralIndex(1984)] }
+type XcmLocation struct {
+	Parents   int            `json:"parents"`
+	Junctions []*XcmJunction `json:"junctions"`
+}
+
+func NewXcmLocation(parents int) *XcmLocation {
+	return &XcmLocation{Parents: parents, Junctions: []*XcmJunction{}}
+}
+
+// The location of the relay chain from the parachain.
+func NewXcmRelayLocation() *XcmLocation {
+	return NewXcmLocation(1)
+}
+
+// The location of the parachain.
+// @param fromRelay true if the current chain is the relay chain, otherwise the location is relative to the sibling parachain.
+func NewXcmParachainLocation(paraId int32, fromRelay bool) *XcmLocation {
+	parents := 1
+	if fromRelay {
+		parents = 0
+	}
+	return NewXcmLocation(parents).AppendJunction(XcmJunctionParachain, fmt.Sprint(paraId))
+}
+
+func NewXcmLocationWithJsonString(str string) (*XcmLocation, error) {
+	var o XcmLocation
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (l *XcmLocation) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(l)
+}
+
+// @return the location itself, so the junctions can be appended by chain.
+func (l *XcmLocation) AppendJunction(junctionType, value string) *XcmLocation {
+	l.Junctions = append(l.Junctions, &XcmJunction{Type: junctionType, Value: value})
+	return l
+}
+
+// Encode the xcm types with the highest version declared by metadata, V2, V3, V4 and V5 are supported.
+type xcmEncoder struct {
+	metadata *types.Metadata
+	version  int
+}
+
+// Find the highest supported version from the variants of the versioned type, e.g. VersionedMultiLocation
+func newXcmEncoder(metadata *types.Metadata, versionedType *types.Si1Type) (*xcmEncoder, error) {
+	if !versionedType.Def.IsVariant {
+		return nil, errors.New("the xcm versioned type is not an enum")
+	}
+	version := 0
+	for _, variant := range versionedType.Def.Variant.Variants {
+		var v int
+		if _, err := fmt.Sscanf(string(variant.Name), "V%d", &v); err == nil && v >= 2 && v <= 5 && v > version {
+			version = v
+		}
+	}
+	if version == 0 {
+		return nil, errors.New("no supported xcm version found in metadata")
+	}
+	return &xcmEncoder{metadata: metadata, version: version}, nil
+}
+
+func (e *xcmEncoder) versioned(value interface{}) variantValue {
+	return variantValue{name: fmt.Sprintf("V%d", e.version), value: value}
+}
+
+func (e *xcmEncoder) location(location *XcmLocation) (interface{}, error) {
+	junctions := make([]interface{}, 0, len(location.Junctions))
+	for _, junction := range location.Junctions {
+		value, err := e.junction(junction)
+		if err != nil {
+			return nil, err
+		}
+		junctions = append(junctions, value)
+	}
+	var interior interface{} = "Here"
+	switch {
+	case len(junctions) > 8:
+		return nil, errors.New("the xcm location can have at most 8 junctions")
+	case len(junctions) == 1 && e.version < 4:
+		// X1(Junction) in V2 & V3, X1([Junction; 1]) in V4
+		interior = variantValue{name: "X1", value: junctions[0]}
+	case len(junctions) > 0:
+		interior = variantValue{name: fmt.Sprintf("X%d", len(junctions)), value: junctions}
+	}
+	return map[string]interface{}{
+		"parents":  location.Parents,
+		"interior": interior,
+	}, nil
+}
+
+func (e *xcmEncoder) junction(junction *XcmJunction) (interface{}, error) {
+	// The network is `NetworkId::Any` in V2, `Option<NetworkId>::None` in V3+
+	var anyNetwork interface{} = nil
+	if e.version == 2 {
+		anyNetwork = "Any"
+	}
+	switch junction.Type {
+	case XcmJunctionParachain, XcmJunctionPalletInstance, XcmJunctionGeneralIndex:
+		value, ok := new(big.Int).SetString(junction.Value, 10)
+		if !ok {
+			return nil, fmt.Errorf("the value of junction %v should be a number", junction.Type)
+		}
+		return variantValue{name: junction.Type, value: value}, nil
+	case XcmJunctionAccountId32:
+		publicKey, err := DecodeAddressToPublicKey(junction.Value)
+		if err != nil {
+			return nil, err
+		}
+		return variantValue{name: junction.Type, value: map[string]interface{}{
+			"network": anyNetwork,
+			"id":      types.MustHexDecodeString(publicKey),
+		}}, nil
+	case XcmJunctionAccountKey20:
+		if !common.IsHexAddress(junction.Value) {
+			return nil, ErrAddress
+		}
+		return variantValue{name: junction.Type, value: map[string]interface{}{
+			"network": anyNetwork,
+			"key":     common.HexToAddress(junction.Value).Bytes(),
+		}}, nil
+	}
+	return nil, fmt.Errorf("the junction type %v is not supported", junction.Type)
+}
+
+func (e *xcmEncoder) fungibleAsset(location *XcmLocation, amount *big.Int) (interface{}, error) {
+	assetLocation, err := e.location(location)
+	if err != nil {
+		return nil, err
+	}
+	// AssetId::Concrete(MultiLocation) in V2 & V3, AssetId(Location) in V4
+	var assetId interface{} = assetLocation
+	if e.version < 4 {
+		assetId = variantValue{name: "Concrete", value: assetLocation}
+	}
+	return map[string]interface{}{
+		"id":  assetId,
+		"fun": variantValue{name: "Fungible", value: amount},
+	}, nil
+}
+
+// The receiver on the destination chain, the evm address will be encoded as AccountKey20
+func xcmBeneficiaryLocation(beneficiary string) *XcmLocation {
+	if strings.HasPrefix(beneficiary, "0x") && len(beneficiary) == 42 {
+		return NewXcmLocation(0).AppendJunction(XcmJunctionAccountKey20, beneficiary)
+	}
+	return NewXcmLocation(0).AppendJunction(XcmJunctionAccountId32, beneficiary)
+}
+
+// The pallet is `XcmPallet` on the relay chain, `PolkadotXcm` on the parachains.
+func (t *Tx) xcmPalletName() (string, error) {
+	for _, name := range []string{"XcmPallet", "PolkadotXcm"} {
+		if _, err := callFieldsOfMetadata(t.metadata, name+".limited_reserve_transfer_assets"); err == nil {
+			return name, nil
+		}
+	}
+	return "", errors.New("the xcm pallet not found in metadata")
+}
+
+// Transfer the asset which is reserved on the current chain to the destination chain.
+// @param dest the location of destination chain, e.g. NewXcmParachainLocation(2000, true)
+// @param beneficiary the receiver address on the destination chain, ss58 address or evm address.
+// @param asset the location of the asset relative to the current chain, nil means the native token of the relay chain.
+// @param amount the amount of asset, the destination fee will be paid from it.
+func (t *Tx) NewXcmLimitedReserveTransferAssetsTx(dest *XcmLocation, beneficiary string, asset *XcmLocation, amount string) (*Transaction, error) {
+	return t.newXcmTransferAssetsTx("limited_reserve_transfer_assets", dest, beneficiary, asset, amount)
+}
+
+// Teleport the asset to the trusted destination chain, e.g. DOT between the relay chain and the asset hub.
+// The parameters are the same as `NewXcmLimitedReserveTransferAssetsTx`
+func (t *Tx) NewXcmLimitedTeleportAssetsTx(dest *XcmLocation, beneficiary string, asset *XcmLocation, amount string) (*Transaction, error) {
+	return t.newXcmTransferAssetsTx("limited_teleport_assets", dest, beneficiary, asset, amount)
+}
+
+func (t *Tx) newXcmTransferAssetsTx(method string, dest *XcmLocation, beneficiary string, asset *XcmLocation, amount string) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	if dest == nil {
+		return nil, errors.New("the destination location is required")
+	}
+	amountInt, ok := new(big.Int).SetString(amount, 10)
+	if !ok || amountInt.Sign() <= 0 {
+		return nil, ErrNumber
+	}
+	pallet, err := t.xcmPalletName()
+	if err != nil {
+		return nil, err
+	}
+	callName := pallet + "." + method
+	if asset == nil {
+		asset = NewXcmLocation(1)
+		if pallet == "XcmPallet" {
+			asset = NewXcmLocation(0)
+		}
+	}
+	destType, err := typeOfCallField(t.metadata, callName, "dest")
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := newXcmEncoder(t.metadata, destType)
+	if err != nil {
+		return nil, err
+	}
+
+	destValue, err := encoder.location(dest)
+	if err != nil {
+		return nil, err
+	}
+	beneficiaryValue, err := encoder.location(xcmBeneficiaryLocation(beneficiary))
+	if err != nil {
+		return nil, err
+	}
+	assetValue, err := encoder.fungibleAsset(asset, amountInt)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{
+		"dest":           encoder.versioned(destValue),
+		"beneficiary":    encoder.versioned(beneficiaryValue),
+		"assets":         encoder.versioned([]interface{}{assetValue}),
+		"fee_asset_item": 0,
+		"weight_limit":   "Unlimited",
+	}
+	return t.newExtrinsicsWithEncodedArgs(callName, values)
+}
+
+// Transfer the token with orml-xtokens, the destination includes the beneficiary.
+// @param currencyId the currency id number, or the SCALE encoded `CurrencyId` in hex.
+// @param dest the location of destination chain, e.g. NewXcmRelayLocation(), NewXcmParachainLocation(2000, false)
+// @param beneficiary the receiver address on the destination chain, ss58 address or evm address.
+func (t *Tx) NewXTokensTransferTx(currencyId, amount string, dest *XcmLocation, beneficiary string) (*Transaction, error) {
+	if t.metadata == nil {
+		return nil, ErrNilMetadata
+	}
+	if dest == nil {
+		return nil, errors.New("the destination location is required")
+	}
+	const callName = "XTokens.transfer"
+	currencyType, err := typeOfCallField(t.metadata, callName, "currency_id")
+	if err != nil {
+		return nil, err
+	}
+	currencyArg, err := encodeAssetIdOfTypeDef(t.metadata, currencyType, currencyId)
+	if err != nil {
+		return nil, err
+	}
+	amountInt, ok := new(big.Int).SetString(amount, 10)
+	if !ok || amountInt.Sign() <= 0 {
+		return nil, ErrNumber
+	}
+	destType, err := typeOfCallField(t.metadata, callName, "dest")
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := newXcmEncoder(t.metadata, destType)
+	if err != nil {
+		return nil, err
+	}
+	// dest ++ beneficiary
+	fullDest := &XcmLocation{Parents: dest.Parents, Junctions: append(append([]*XcmJunction{}, dest.Junctions...), xcmBeneficiaryLocation(beneficiary).Junctions...)}
+	destValue, err := encoder.location(fullDest)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{
+		"currency_id":       encodedArg(currencyArg),
+		"amount":            amountInt,
+		"dest":              encoder.versioned(destValue),
+		"dest_weight_limit": "Unlimited",
+	}
+	return t.newExtrinsicsWithEncodedArgs(callName, values)
+}
+
+// Encode the arguments with the types declared in metadata, then create the call.
+// @param values the values of `encodeType`, the encodedArg will be used directly.
+func (t *Tx) newExtrinsicsWithEncodedArgs(callName string, values map[string]interface{}) (*Transaction, error) {
+	fields, err := callFieldsOfMetadata(t.metadata, callName)
+	if err != nil {
+		return nil, err
+	}
+	args := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		name := string(field.Name)
+		value, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("the argument %v of call %v is not supported", name, callName)
+		}
+		if encoded, ok := value.(encodedArg); ok {
+			args[name] = encoded
+			continue
+		}
+		data, err := encodeType(t.metadata, field.Type.Int64(), value)
+		if err != nil {
+			return nil, fmt.Errorf("encode argument %v of call %v failed: %v", name, callName, err)
+		}
+		args[name] = encodedArg(data)
+	}
+	return t.newExtrinsicsWithNamedArgs(callName, args)
+}
+
+// Estimate the fee paid on the destination chain for executing the received xcm, the chain should be the destination.
+// The weight is estimated by the instruction count, and the fee is converted with the runtime api `TransactionPaymentApi_query_weight_to_fee`.
+// @param instructionCount the number of instructions executed on the destination, e.g. XcmTransferInstructionCount
+// @return the fee in the native token of the destination chain.
+func (c *Chain) EstimateXcmDestinationFee(instructionCount int) (s string, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if instructionCount <= 0 {
+		instructionCount = XcmTransferInstructionCount
+	}
+	cl, err := getConnectedPolkaClient(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	// Weight { ref_time: Compact<u64>, proof_size: Compact<u64> }
+	refTime, err := types.EncodeToBytes(types.NewUCompactFromUInt(uint64(instructionCount) * xcmInstructionRefTime))
+	if err != nil {
+		return
+	}
+	proofSize, err := types.EncodeToBytes(types.NewUCompactFromUInt(uint64(instructionCount) * xcmInstructionProofSize))
+	if err != nil {
+		return
+	}
+	var result string
+	err = cl.api.Client.Call(&result, "state_call", "TransactionPaymentApi_query_weight_to_fee", types.HexEncodeToString(append(refTime, proofSize...)))
+	if err != nil {
+		return
+	}
+	var fee types.U128
+	if err = types.DecodeFromHexString(result, &fee); err != nil {
+		return
+	}
+	return fee.String(), nil
+}
@@ -0,0 +1,63 @@
+package polka
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeType(t *testing.T) {
+	metadata := mockDecoderMetadata()
+	account := types.MustHexDecodeString("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")
+
+	// pallet_balances::Call::transfer { dest: MultiAddress::Id(account), value: Compact(15000000000) }
+	data, err := encodeType(metadata, 6, variantValue{name: "transfer", value: map[string]interface{}{
+		"dest":  variantValue{name: "Id", value: account},
+		"value": "15000000000",
+	}})
+	require.Nil(t, err)
+	want := append(append([]byte{0, 0}, account...), mustEncode(t, types.NewUCompact(big.NewInt(15000000000)))...)
+	require.Equal(t, want, data)
+
+	// the sequence of u8 can be given as bytes
+	data, err = encodeType(metadata, 4, account)
+	require.Nil(t, err)
+	require.Equal(t, account, data)
+
+	_, err = encodeType(metadata, 4, account[:31])
+	require.NotNil(t, err)
+	_, err = encodeType(metadata, 0, 256)
+	require.NotNil(t, err)
+	_, err = encodeType(metadata, 6, variantValue{name: "transfer_all"})
+	require.NotNil(t, err)
+}
+
+func TestTx_XcmTransferTx(t *testing.T) {
+	chain, err := rpcs.polkadot.Chain()
+	require.Nil(t, err)
+	tx, err := chain.GetTx()
+	require.Nil(t, err)
+
+	// teleport DOT to the asset hub
+	assetHub := NewXcmParachainLocation(1000, true)
+	teleport, err := tx.NewXcmLimitedTeleportAssetsTx(assetHub, accountCase.address0, nil, "10000000000")
+	require.Nil(t, err)
+	fee, err := chain.EstimateFeeForTransaction(teleport)
+	require.Nil(t, err)
+	t.Log(fee)
+
+	// reserve transfer DOT to the parachain 2000
+	reserve, err := tx.NewXcmLimitedReserveTransferAssetsTx(NewXcmParachainLocation(2000, true), "0x7Be1D1CFdcE7d0F25d57D0f2cDe4aB3e38C6B7E1", nil, "10000000000")
+	require.Nil(t, err)
+	decoded, err := tx.DecodeExtrinsicJson(mustUnsignedTx(t, reserve), 10, 0)
+	require.Nil(t, err)
+	t.Log(decoded.Value)
+
+	assetHubChain, err := NewChainWithRpc("https://polkadot-asset-hub-rpc.polkadot.io", "")
+	require.Nil(t, err)
+	destFee, err := assetHubChain.EstimateXcmDestinationFee(XcmTransferInstructionCount)
+	require.Nil(t, err)
+	t.Log(destFee)
+}
@@ -2,19 +2,16 @@ package solana
 
 import (
 	"context"
-	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
 
 	"github.com/coming-chat/wallet-SDK/core/base"
 	"github.com/portto/solana-go-sdk/client"
-	"github.com/portto/solana-go-sdk/common"
-	"github.com/portto/solana-go-sdk/program/sysprog"
 	"github.com/portto/solana-go-sdk/rpc"
 
 	hexTypes "github.com/centrifuge/go-substrate-rpc-client/v4/types"
-	"github.com/portto/solana-go-sdk/types"
 )
 
 const (
@@ -69,38 +66,31 @@ func (c *Chain) BalanceOfAccount(account base.Account) (*base.Balance, error) {
 // Send the raw transaction on-chain
 // @return the hex hash string
 func (c *Chain) SendRawTransaction(signedTx string) (string, error) {
-	client := c.client()
 	bytes, err := hexTypes.HexDecodeString(signedTx)
 	if err != nil {
 		return "", err
 	}
-	transaction, err := types.TransactionDeserialize(bytes)
-	if err != nil {
-		return "", err
-	}
-	res, err := client.SendTransaction(context.Background(), transaction)
+	// the legacy and versioned transactions are both supported.
+	transaction, err := VersionedTransactionDeserialize(bytes)
 	if err != nil {
 		return "", err
 	}
-	return res, nil
+	return c.SendVersionedTransaction(transaction)
 }
 
 // Fetch transaction details through transaction hash
+// The from and to addresses are of the first transfer, the amount is empty if it's not a SOL transfer.
+// See `FetchFullTransactionDetail` for the amounts and mints of all SOL and SPL token transfers.
 func (c *Chain) FetchTransactionDetail(hash string) (*base.TransactionDetail, error) {
-	response, err := c.client().GetTransaction(context.Background(), hash)
-	if err != nil {
-		return nil, err
-	}
-	detail := &base.TransactionDetail{HashString: hash}
-	err = decodeTransaction(response, detail)
+	detail, err := c.FetchFullTransactionDetail(hash)
 	if err != nil {
 		return nil, err
 	}
-	return detail, nil
+	return detail.TransactionDetail, nil
 }
 
 func (c *Chain) FetchTransactionStatus(hash string) base.TransactionStatus {
-	response, err := c.client().GetTransaction(context.Background(), hash)
+	response, err := c.fetchRawTransaction(hash)
 	if err != nil {
 		return base.TransactionStatusNone
 	}
@@ -121,51 +111,28 @@ func (c *Chain) BatchFetchTransactionStatus(hashListString string) string {
 	return strings.Join(statuses, ",")
 }
 
-func decodeTransaction(tx *client.GetTransactionResponse, to *base.TransactionDetail) error {
-	base.CatchPanicAndMapToBasicError(nil)
+func (c *Chain) SPLToken(mintAddress string) (*SPLToken, error) {
+	return NewSPLToken(c, mintAddress)
+}
 
-	if tx == nil || tx.BlockTime == nil {
-		to.Status = base.TransactionStatusPending
-		return nil
+// Call the rpc method which is not supported by the client, the `result` of response will be unmarshaled into `out`.
+func (c *Chain) callRpc(out interface{}, method string, params ...interface{}) error {
+	body, err := c.client().RpcClient.Call(context.Background(), append([]interface{}{method}, params...)...)
+	if err != nil {
+		return err
 	}
-
-	message := tx.Transaction.Message
-	for _, instruction := range message.Instructions {
-		program := message.Accounts[instruction.ProgramIDIndex]
-		if program != common.SystemProgramID {
-			continue
-		}
-
-		// We only support decode amount transfer currently.
-		data := instruction.Data
-		instruct := binary.LittleEndian.Uint32(data[:4])
-		toidx := -1
-		switch sysprog.Instruction(instruct) {
-		case sysprog.InstructionTransfer:
-			toidx = instruction.Accounts[1]
-		case sysprog.InstructionTransferWithSeed:
-			toidx = instruction.Accounts[2]
-		}
-		if toidx == -1 {
-			continue
-		}
-
-		fromidx := instruction.Accounts[0]
-		to.FromAddress = message.Accounts[fromidx].ToBase58()
-		to.ToAddress = message.Accounts[toidx].ToBase58()
-		amount := binary.LittleEndian.Uint64(data[4:12])
-		to.Amount = strconv.FormatUint(amount, 10)
-		to.EstimateFees = strconv.FormatUint(tx.Meta.Fee, 10)
-		to.FinishTimestamp = *tx.BlockTime
-
-		if tx.Meta.Err == nil {
-			to.Status = base.TransactionStatusSuccess
-		} else {
-			to.Status = base.TransactionStatusFailure
-			to.FailureMessage = tx.Meta.LogMessages[len(tx.Meta.LogMessages)-1]
-		}
-
-		return nil
+	var res struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err = json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return errors.New(res.Error.Message)
 	}
-	return errors.New("The transaction does not contain an amount transfer")
+	return json.Unmarshal(res.Result, out)
 }
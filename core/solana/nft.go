@@ -0,0 +1,219 @@
+package solana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/pkg/httpUtil"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/metaplex/tokenmeta"
+)
+
+const (
+	NFTStandardMetaplex = "Metaplex"
+
+	// The maximum count of accounts of `getMultipleAccounts`.
+	maxMultipleAccounts = 100
+)
+
+// The off-chain json that the uri of Metaplex metadata points to.
+type nftOffChainMetadata struct {
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	Description string `json:"description"`
+	ExternalUrl string `json:"external_url"`
+}
+
+// MARK - Implement the protocol NFTFetcher
+
+// The NFTs are the token accounts (token program only) which amount is 1 and decimals is 0,
+// and they are grouped by the name of the collection in the Metaplex metadata.
+func (c *Chain) FetchNFTs(owner string) (res map[string][]*base.NFT, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if !IsValidAddress(owner) {
+		return nil, errors.New("Invalid owner address")
+	}
+	accounts, err := c.fetchTokenAccountsByOwner(owner, "")
+	if err != nil {
+		return
+	}
+	mints := []common.PublicKey{}
+	for _, account := range accounts {
+		if account.Amount == "1" && account.Decimals == 0 {
+			mints = append(mints, common.PublicKeyFromString(account.Mint))
+		}
+	}
+	metadatas, err := c.fetchMetadatas(mints)
+	if err != nil {
+		return
+	}
+
+	collectionMints := []common.PublicKey{}
+	for _, metadata := range metadatas {
+		if metadata.Collection != nil && metadata.Collection.Verified {
+			collectionMints = append(collectionMints, metadata.Collection.Key)
+		}
+	}
+	collectionMetadatas, err := c.fetchMetadatas(collectionMints)
+	if err != nil {
+		return
+	}
+	collectionNames := make(map[string]string)
+	for key, metadata := range collectionMetadatas {
+		collectionNames[key] = metadata.Data.Name
+	}
+
+	list := make([]interface{}, 0, len(metadatas))
+	for _, metadata := range metadatas {
+		list = append(list, metadata)
+	}
+	nfts, err := base.MapListConcurrent(list, 10, func(i interface{}) (interface{}, error) {
+		metadata := i.(*tokenmeta.Metadata)
+		nft := nftOfMetadata(metadata, collectionNames)
+		// It will use the on-chain metadata if the off-chain json is unavailable.
+		if offChain, err := fetchOffChainMetadata(metadata.Data.Uri); err == nil {
+			nft.Image = base.IpfsUrlToHttpUrl(offChain.Image)
+			nft.Description = offChain.Description
+			nft.RelatedUrl = offChain.ExternalUrl
+		}
+		return nft, nil
+	})
+	if err != nil {
+		return
+	}
+
+	nftGroupd := make(map[string][]*base.NFT)
+	for _, item := range nfts {
+		nft := item.(*base.NFT)
+		key := nft.GroupName()
+		nftGroupd[key] = append(nftGroupd[key], nft)
+	}
+	for _, group := range nftGroupd {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].Name < group[j].Name
+		})
+	}
+	return nftGroupd, nil
+}
+
+// @return json string that grouped by nft's collection
+func (c *Chain) FetchNFTsJsonString(owner string) (*base.OptionalString, error) {
+	nfts, err := c.FetchNFTs(owner)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := json.Marshal(nfts)
+	if err != nil {
+		return nil, err
+	}
+	return &base.OptionalString{Value: string(bytes)}, nil
+}
+
+// MARK - NFT transfer
+
+// Transfer the NFT to the receiver, the associated token account of the receiver will be created if it does not exist.
+// @param options nil means no compute budget instructions and the latest blockhash is used.
+func (c *Chain) BuildTransferNFTTx(account *Account, mintAddress, receiverAddress string, options *TransactionOptions) (*base.OptionalString, error) {
+	token, err := c.nftToken(mintAddress)
+	if err != nil {
+		return nil, err
+	}
+	return token.BuildTransferTxWithOptions(account, receiverAddress, "1", options)
+}
+
+func (c *Chain) EstimateTransferNFTFees(mintAddress, receiverAddress string, options *TransactionOptions) (*base.OptionalString, error) {
+	token, err := c.nftToken(mintAddress)
+	if err != nil {
+		return nil, err
+	}
+	return token.EstimateFeesWithOptions(receiverAddress, "1", options)
+}
+
+func (c *Chain) nftToken(mintAddress string) (*SPLToken, error) {
+	token, err := NewSPLToken(c, mintAddress)
+	if err != nil {
+		return nil, err
+	}
+	info, err := token.TokenInfo()
+	if err != nil {
+		return nil, err
+	}
+	if info.Decimal != 0 {
+		return nil, errors.New("The mint is not a NFT: " + mintAddress)
+	}
+	return token, nil
+}
+
+// MARK - Metadata
+
+// Fetch the Metaplex metadata of the mints, the mints without metadata are ignored.
+// @return the metadata map, the key is the mint address.
+func (c *Chain) fetchMetadatas(mints []common.PublicKey) (map[string]*tokenmeta.Metadata, error) {
+	res := make(map[string]*tokenmeta.Metadata)
+	pubkeys := []string{}
+	for _, mint := range mints {
+		pubkey, err := tokenmeta.GetTokenMetaPubkey(mint)
+		if err != nil {
+			return nil, err
+		}
+		pubkeys = append(pubkeys, pubkey.ToBase58())
+	}
+	for start := 0; start < len(pubkeys); start += maxMultipleAccounts {
+		end := start + maxMultipleAccounts
+		if end > len(pubkeys) {
+			end = len(pubkeys)
+		}
+		accounts, err := c.client().GetMultipleAccounts(context.Background(), pubkeys[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range accounts {
+			if account.Owner != common.MetaplexTokenMetaProgramID.ToBase58() || len(account.Data) == 0 {
+				continue
+			}
+			metadata, err := tokenmeta.MetadataDeserialize(account.Data)
+			if err != nil {
+				continue
+			}
+			res[metadata.Mint.ToBase58()] = &metadata
+		}
+	}
+	return res, nil
+}
+
+// The image is empty, it's resolved from the off-chain json of `metadata.Data.Uri`.
+// @param collectionNames the collection name map, the key is the collection mint address.
+func nftOfMetadata(metadata *tokenmeta.Metadata, collectionNames map[string]string) *base.NFT {
+	mint := metadata.Mint.ToBase58()
+	collection := ""
+	if metadata.Collection != nil && metadata.Collection.Verified {
+		collection = collectionNames[metadata.Collection.Key.ToBase58()]
+	}
+	return &base.NFT{
+		Id:              mint,
+		Name:            metadata.Data.Name,
+		Standard:        NFTStandardMetaplex,
+		Collection:      collection,
+		ContractAddress: mint,
+	}
+}
+
+func fetchOffChainMetadata(uri string) (*nftOffChainMetadata, error) {
+	if uri == "" {
+		return nil, errors.New("The metadata uri is empty")
+	}
+	body, err := httpUtil.Get(base.IpfsUrlToHttpUrl(uri), nil)
+	if err != nil {
+		return nil, err
+	}
+	var metadata nftOffChainMetadata
+	err = json.Unmarshal(body, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
@@ -0,0 +1,42 @@
+package solana
+
+import (
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/metaplex/tokenmeta"
+	"github.com/portto/solana-go-sdk/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNFTOfMetadata(t *testing.T) {
+	mint := common.PublicKeyFromString("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	collection := common.PublicKeyFromString("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g")
+	metadata := &tokenmeta.Metadata{
+		Mint: mint,
+		Data: tokenmeta.Data{
+			Name: "Test #1",
+			Uri:  "ipfs://QmTest/1.json",
+		},
+		Collection: &tokenmeta.Collection{Verified: true, Key: collection},
+	}
+	names := map[string]string{collection.ToBase58(): "Test Collection"}
+
+	nft := nftOfMetadata(metadata, names)
+	require.Equal(t, mint.ToBase58(), nft.Id)
+	require.Equal(t, "Test #1", nft.Name)
+	require.Equal(t, "", nft.Image) // the uri is the json document, not the image
+	require.Equal(t, "Test Collection", nft.GroupName())
+
+	// the unverified collection should not be trusted.
+	metadata.Collection.Verified = false
+	nft = nftOfMetadata(metadata, names)
+	require.Equal(t, "Others", nft.GroupName())
+}
+
+func TestFetchNFTs(t *testing.T) {
+	chain := NewChainWithRpc(rpc.MainnetRPCEndpoint)
+	nfts, err := chain.FetchNFTsJsonString("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g")
+	require.Nil(t, err)
+	t.Log(nfts.Value)
+}
@@ -0,0 +1,276 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strconv"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/assotokenprog"
+	"github.com/portto/solana-go-sdk/program/metaplex/tokenmeta"
+	"github.com/portto/solana-go-sdk/program/tokenprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+var Token2022ProgramID = common.PublicKeyFromString("TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb")
+
+// The offset of `decimals` in the mint account data, token-2022 mint has the same layout followed by extensions.
+const mintDecimalsOffset = 44
+
+type SPLToken struct {
+	chain       *Chain
+	MintAddress string
+
+	tokenInfo *base.TokenInfo
+	programId common.PublicKey
+}
+
+func NewSPLToken(chain *Chain, mintAddress string) (*SPLToken, error) {
+	if !IsValidAddress(mintAddress) {
+		return nil, errors.New("Invalid mint address")
+	}
+	return &SPLToken{chain: chain, MintAddress: mintAddress}, nil
+}
+
+// MARK - Implement the protocol Token
+
+func (t *SPLToken) Chain() base.Chain {
+	return t.chain
+}
+
+// The decimals is read from the mint account, the name and symbol are read from the Metaplex metadata, they are empty if the metadata does not exist.
+func (t *SPLToken) TokenInfo() (*base.TokenInfo, error) {
+	if t.tokenInfo != nil {
+		return t.tokenInfo, nil
+	}
+	client := t.chain.client()
+	mint := common.PublicKeyFromString(t.MintAddress)
+	mintAccount, err := client.GetAccountInfo(context.Background(), t.MintAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(mintAccount.Data) < tokenprog.MintAccountSize {
+		return nil, errors.New("Invalid mint account: " + t.MintAddress)
+	}
+	info := &base.TokenInfo{Decimal: int16(mintAccount.Data[mintDecimalsOffset])}
+
+	metadataPubkey, err := tokenmeta.GetTokenMetaPubkey(mint)
+	if err != nil {
+		return nil, err
+	}
+	metadataAccount, err := client.GetAccountInfo(context.Background(), metadataPubkey.ToBase58())
+	if err != nil {
+		return nil, err
+	}
+	if len(metadataAccount.Data) > 0 {
+		metadata, err := tokenmeta.MetadataDeserialize(metadataAccount.Data)
+		if err != nil {
+			return nil, err
+		}
+		info.Name = metadata.Data.Name
+		info.Symbol = metadata.Data.Symbol
+	}
+
+	t.tokenInfo = info
+	t.programId = common.PublicKeyFromString(mintAccount.Owner)
+	return info, nil
+}
+
+// The balance is the sum of all token accounts of the owner, the frozen accounts are not usable.
+func (t *SPLToken) BalanceOfAddress(address string) (*base.Balance, error) {
+	accounts, err := t.chain.fetchTokenAccountsByOwner(address, t.MintAddress)
+	if err != nil {
+		return nil, err
+	}
+	total := big.NewInt(0)
+	usable := big.NewInt(0)
+	for _, account := range accounts {
+		amount, ok := big.NewInt(0).SetString(account.Amount, 10)
+		if !ok {
+			continue
+		}
+		total.Add(total, amount)
+		if account.State != "frozen" {
+			usable.Add(usable, amount)
+		}
+	}
+	return &base.Balance{Total: total.String(), Usable: usable.String()}, nil
+}
+
+func (t *SPLToken) BalanceOfPublicKey(publicKey string) (*base.Balance, error) {
+	address, err := EncodePublicKeyToAddress(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return t.BalanceOfAddress(address)
+}
+
+func (t *SPLToken) BalanceOfAccount(account base.Account) (*base.Balance, error) {
+	return t.BalanceOfAddress(account.Address())
+}
+
+// MARK - SPL token
+
+func (t *SPLToken) BuildTransferTx(privateKey, receiverAddress, amount string) (*base.OptionalString, error) {
+	account, err := AccountWithPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return t.BuildTransferTxWithAccount(account, receiverAddress, amount)
+}
+
+// The associated token account of the receiver will be created if it does not exist, the sender pays the rent.
+func (t *SPLToken) BuildTransferTxWithAccount(account *Account, receiverAddress, amount string) (*base.OptionalString, error) {
+	return t.BuildTransferTxWithOptions(account, receiverAddress, amount, nil)
+}
+
+// Build the transfer transaction with the compute budget or durable nonce.
+// @param options nil means no compute budget instructions and the latest blockhash is used.
+func (t *SPLToken) BuildTransferTxWithOptions(account *Account, receiverAddress, amount string, options *TransactionOptions) (*base.OptionalString, error) {
+	message, err := t.transferMessage(account.Address(), receiverAddress, amount, options)
+	if err != nil {
+		return nil, err
+	}
+	return signMessage(message, account)
+}
+
+// The fee does not contain the rent of the receiver's associated token account.
+func (t *SPLToken) EstimateFees(receiverAddress, amount string) (*base.OptionalString, error) {
+	return t.EstimateFeesWithOptions(receiverAddress, amount, nil)
+}
+
+// The fee contains the priority fee of the compute budget in the options.
+func (t *SPLToken) EstimateFeesWithOptions(receiverAddress, amount string, options *TransactionOptions) (*base.OptionalString, error) {
+	message, err := t.transferMessage(receiverAddress, receiverAddress, amount, options)
+	if err != nil {
+		return nil, err
+	}
+	return t.chain.estimateFeeOfMessage(message)
+}
+
+func (t *SPLToken) transferMessage(fromAddress, toAddress, amount string, options *TransactionOptions) (*types.Message, error) {
+	if !IsValidAddress(toAddress) {
+		return nil, errors.New("Invalid receiver address")
+	}
+	amountUint, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return nil, errors.New("Invalid amount")
+	}
+	info, err := t.TokenInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	mint := common.PublicKeyFromString(t.MintAddress)
+	pubFrom := common.PublicKeyFromString(fromAddress)
+	pubTo := common.PublicKeyFromString(toAddress)
+	ataFrom, err := findAssociatedTokenAddress(pubFrom, mint, t.programId)
+	if err != nil {
+		return nil, err
+	}
+	ataTo, err := findAssociatedTokenAddress(pubTo, mint, t.programId)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := tokenprog.TransferChecked(tokenprog.TransferCheckedParam{
+		From:     ataFrom,
+		To:       ataTo,
+		Mint:     mint,
+		Auth:     pubFrom,
+		Amount:   amountUint,
+		Decimals: uint8(info.Decimal),
+	})
+	// the instruction layout of token-2022 is compatible with the token program.
+	transfer.ProgramID = t.programId
+
+	instructions := []types.Instruction{
+		createAssociatedTokenAccountIdempotent(pubFrom, pubTo, mint, ataTo, t.programId),
+		transfer,
+	}
+	return t.chain.newMessage(pubFrom, instructions, options)
+}
+
+// Same as `common.FindAssociatedTokenAddress`, but the token program can be token-2022.
+func findAssociatedTokenAddress(wallet, mint, tokenProgramId common.PublicKey) (common.PublicKey, error) {
+	seeds := [][]byte{wallet.Bytes(), tokenProgramId.Bytes(), mint.Bytes()}
+	address, _, err := common.FindProgramAddress(seeds, common.SPLAssociatedTokenAccountProgramID)
+	return address, err
+}
+
+// Create the associated token account if it does not exist, otherwise do nothing.
+func createAssociatedTokenAccountIdempotent(funder, owner, mint, associatedAccount, tokenProgramId common.PublicKey) types.Instruction {
+	return types.Instruction{
+		ProgramID: common.SPLAssociatedTokenAccountProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: funder, IsSigner: true, IsWritable: true},
+			{PubKey: associatedAccount, IsSigner: false, IsWritable: true},
+			{PubKey: owner, IsSigner: false, IsWritable: false},
+			{PubKey: mint, IsSigner: false, IsWritable: false},
+			{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: tokenProgramId, IsSigner: false, IsWritable: false},
+		},
+		Data: []byte{byte(assotokenprog.InstructionCreateIdempotent)},
+	}
+}
+
+// MARK - token accounts
+
+type tokenAccount struct {
+	Address string
+	Mint    string
+	Owner   string
+	State   string
+	Amount  string
+
+	Decimals int16
+}
+
+// Query the token accounts of the owner with `jsonParsed` encoding.
+// @param mint the mint address of token, all token accounts of the owner (token program only) will be returned if it's empty.
+func (c *Chain) fetchTokenAccountsByOwner(owner, mint string) ([]*tokenAccount, error) {
+	filter := map[string]string{"mint": mint}
+	if mint == "" {
+		filter = map[string]string{"programId": common.TokenProgramID.ToBase58()}
+	}
+	var res struct {
+		Value []struct {
+			Pubkey  string `json:"pubkey"`
+			Account struct {
+				Data struct {
+					Parsed struct {
+						Info struct {
+							Mint        string `json:"mint"`
+							Owner       string `json:"owner"`
+							State       string `json:"state"`
+							TokenAmount struct {
+								Amount   string `json:"amount"`
+								Decimals int16  `json:"decimals"`
+							} `json:"tokenAmount"`
+						} `json:"info"`
+					} `json:"parsed"`
+				} `json:"data"`
+			} `json:"account"`
+		} `json:"value"`
+	}
+	err := c.callRpc(&res, "getTokenAccountsByOwner", owner, filter, map[string]string{"encoding": "jsonParsed"})
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]*tokenAccount, 0, len(res.Value))
+	for _, v := range res.Value {
+		info := v.Account.Data.Parsed.Info
+		accounts = append(accounts, &tokenAccount{
+			Address: v.Pubkey,
+			Mint:    info.Mint,
+			Owner:   info.Owner,
+			State:   info.State,
+			Amount:  info.TokenAmount.Amount,
+
+			Decimals: info.TokenAmount.Decimals,
+		})
+	}
+	return accounts, nil
+}
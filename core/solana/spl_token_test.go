@@ -0,0 +1,44 @@
+package solana
+
+import (
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindAssociatedTokenAddress(t *testing.T) {
+	owner := common.PublicKeyFromString("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g")
+	mint := common.PublicKeyFromString("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	expected, _, err := common.FindAssociatedTokenAddress(owner, mint)
+	require.Nil(t, err)
+
+	address, err := findAssociatedTokenAddress(owner, mint, common.TokenProgramID)
+	require.Nil(t, err)
+	require.Equal(t, expected, address)
+
+	address2022, err := findAssociatedTokenAddress(owner, mint, Token2022ProgramID)
+	require.Nil(t, err)
+	require.NotEqual(t, expected, address2022)
+}
+
+func TestSPLToken(t *testing.T) {
+	chain := NewChainWithRpc(rpc.MainnetRPCEndpoint)
+	// USDC
+	token, err := chain.SPLToken("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	require.Nil(t, err)
+
+	info, err := token.TokenInfo()
+	require.Nil(t, err)
+	require.Equal(t, int16(6), info.Decimal)
+	t.Log(info.Name, info.Symbol)
+
+	balance, err := token.BalanceOfAddress("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g")
+	require.Nil(t, err)
+	t.Log(balance)
+
+	fee, err := token.EstimateFees("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g", "1000")
+	require.Nil(t, err)
+	t.Log(fee.Value)
+}
@@ -0,0 +1,408 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/stakeprog"
+	"github.com/portto/solana-go-sdk/program/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// The offset of withdraw authority in the stake account data: state(4) + rent_exempt_reserve(8) + staker(32)
+const stakeWithdrawerOffset = 44
+
+type Validator struct {
+	VoteAccount string `json:"voteAccount"`
+	NodeAccount string `json:"nodeAccount"`
+	// The commission percentage, 0 ~ 100
+	Commission     int64  `json:"commission"`
+	ActivatedStake string `json:"activatedStake"`
+	LastVote       int64  `json:"lastVote"`
+	// The validator is delinquent if it has not voted recently.
+	Delinquent bool `json:"delinquent"`
+}
+
+func (v *Validator) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(v)
+}
+
+func NewValidatorWithJsonString(str string) (*Validator, error) {
+	var o Validator
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (v *Validator) AsAny() *base.Any {
+	return &base.Any{Value: v}
+}
+
+func AsValidator(a *base.Any) *Validator {
+	if r, ok := a.Value.(*Validator); ok {
+		return r
+	}
+	if r, ok := a.Value.(Validator); ok {
+		return &r
+	}
+	return nil
+}
+
+type StakeState = string
+
+const (
+	StakeStateInactive     StakeState = "inactive"
+	StakeStateActivating   StakeState = "activating"
+	StakeStateActive       StakeState = "active"
+	StakeStateDeactivating StakeState = "deactivating"
+)
+
+type StakeAccount struct {
+	Address string `json:"address"`
+	// The total lamports of the stake account, including the rent-exempt reserve.
+	Lamports   string     `json:"lamports"`
+	State      StakeState `json:"state"`
+	Staker     string     `json:"staker"`
+	Withdrawer string     `json:"withdrawer"`
+
+	// The vote account of the delegated validator, empty if the stake account is not delegated.
+	Voter             string `json:"voter"`
+	DelegatedStake    string `json:"delegatedStake"`
+	ActivationEpoch   int64  `json:"activationEpoch"`
+	DeactivationEpoch int64  `json:"deactivationEpoch"` // -1 means not deactivated
+	// The inflation reward of the last epoch.
+	LastReward string `json:"lastReward"`
+}
+
+func (s *StakeAccount) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(s)
+}
+
+func NewStakeAccountWithJsonString(str string) (*StakeAccount, error) {
+	var o StakeAccount
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (s *StakeAccount) AsAny() *base.Any {
+	return &base.Any{Value: s}
+}
+
+func AsStakeAccount(a *base.Any) *StakeAccount {
+	if r, ok := a.Value.(*StakeAccount); ok {
+		return r
+	}
+	if r, ok := a.Value.(StakeAccount); ok {
+		return &r
+	}
+	return nil
+}
+
+// MARK - Query
+
+// @return Array of `Validator` elements, the delinquent validators are at the end.
+func (c *Chain) FetchValidators() (arr *base.AnyArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	type rawVoteAccount struct {
+		VotePubkey     string `json:"votePubkey"`
+		NodePubkey     string `json:"nodePubkey"`
+		ActivatedStake uint64 `json:"activatedStake"`
+		Commission     int64  `json:"commission"`
+		LastVote       int64  `json:"lastVote"`
+	}
+	var res struct {
+		Current    []rawVoteAccount `json:"current"`
+		Delinquent []rawVoteAccount `json:"delinquent"`
+	}
+	if err = c.callRpc(&res, "getVoteAccounts"); err != nil {
+		return nil, err
+	}
+	arr = &base.AnyArray{}
+	for i, group := range [][]rawVoteAccount{res.Current, res.Delinquent} {
+		for _, v := range group {
+			arr.Values = append(arr.Values, &Validator{
+				VoteAccount:    v.VotePubkey,
+				NodeAccount:    v.NodePubkey,
+				Commission:     v.Commission,
+				ActivatedStake: strconv.FormatUint(v.ActivatedStake, 10),
+				LastVote:       v.LastVote,
+				Delinquent:     i == 1,
+			})
+		}
+	}
+	return arr, nil
+}
+
+// Fetch the stake accounts which can be withdrawn by the owner, with the inflation reward of the last epoch.
+// @return Array of `StakeAccount` elements
+func (c *Chain) FetchStakeAccounts(owner string) (arr *base.AnyArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	var res []struct {
+		Pubkey  string `json:"pubkey"`
+		Account struct {
+			Lamports uint64 `json:"lamports"`
+			Data     struct {
+				Parsed struct {
+					Type string `json:"type"`
+					Info struct {
+						Meta struct {
+							Authorized struct {
+								Staker     string `json:"staker"`
+								Withdrawer string `json:"withdrawer"`
+							} `json:"authorized"`
+						} `json:"meta"`
+						Stake *struct {
+							Delegation struct {
+								Voter             string `json:"voter"`
+								Stake             string `json:"stake"`
+								ActivationEpoch   string `json:"activationEpoch"`
+								DeactivationEpoch string `json:"deactivationEpoch"`
+							} `json:"delegation"`
+						} `json:"stake"`
+					} `json:"info"`
+				} `json:"parsed"`
+			} `json:"data"`
+		} `json:"account"`
+	}
+	err = c.callRpc(&res, "getProgramAccounts", common.StakeProgramID.ToBase58(), map[string]interface{}{
+		"encoding": "jsonParsed",
+		"filters": []interface{}{
+			map[string]interface{}{"memcmp": map[string]interface{}{"offset": stakeWithdrawerOffset, "bytes": owner}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var epochInfo struct {
+		Epoch int64 `json:"epoch"`
+	}
+	if err = c.callRpc(&epochInfo, "getEpochInfo"); err != nil {
+		return nil, err
+	}
+
+	accounts := make([]*StakeAccount, 0, len(res))
+	addresses := make([]string, 0, len(res))
+	for _, r := range res {
+		info := r.Account.Data.Parsed.Info
+		account := &StakeAccount{
+			Address:           r.Pubkey,
+			Lamports:          strconv.FormatUint(r.Account.Lamports, 10),
+			State:             StakeStateInactive,
+			Staker:            info.Meta.Authorized.Staker,
+			Withdrawer:        info.Meta.Authorized.Withdrawer,
+			DeactivationEpoch: -1,
+			LastReward:        "0",
+		}
+		if info.Stake != nil {
+			delegation := info.Stake.Delegation
+			account.Voter = delegation.Voter
+			account.DelegatedStake = delegation.Stake
+			account.ActivationEpoch = parseEpoch(delegation.ActivationEpoch)
+			account.DeactivationEpoch = parseEpoch(delegation.DeactivationEpoch)
+			account.State = stakeStateOfEpoch(epochInfo.Epoch, account.ActivationEpoch, account.DeactivationEpoch)
+		}
+		accounts = append(accounts, account)
+		addresses = append(addresses, r.Pubkey)
+	}
+
+	if len(addresses) > 0 && epochInfo.Epoch > 0 {
+		var rewards []*struct {
+			Amount uint64 `json:"amount"`
+		}
+		err = c.callRpc(&rewards, "getInflationReward", addresses, map[string]interface{}{"epoch": epochInfo.Epoch - 1})
+		if err == nil {
+			for i, reward := range rewards {
+				if reward != nil && i < len(accounts) {
+					accounts[i].LastReward = strconv.FormatUint(reward.Amount, 10)
+				}
+			}
+		}
+		// the rewards are optional, the error is ignored.
+		err = nil
+	}
+
+	arr = &base.AnyArray{}
+	for _, account := range accounts {
+		arr.Values = append(arr.Values, account)
+	}
+	return arr, nil
+}
+
+// The epoch of u64::MAX means not set.
+func parseEpoch(epoch string) int64 {
+	value, err := strconv.ParseUint(epoch, 10, 64)
+	if err != nil || value > math.MaxInt64 {
+		return -1
+	}
+	return int64(value)
+}
+
+// The stake takes effect (or is deactivated) at the next epoch, the warmup and cooldown rate limit is ignored.
+func stakeStateOfEpoch(currentEpoch, activationEpoch, deactivationEpoch int64) StakeState {
+	switch {
+	case deactivationEpoch >= 0 && currentEpoch > deactivationEpoch:
+		return StakeStateInactive
+	case deactivationEpoch >= 0:
+		return StakeStateDeactivating
+	case activationEpoch >= 0 && currentEpoch > activationEpoch:
+		return StakeStateActive
+	}
+	return StakeStateActivating
+}
+
+// MARK - Transactions
+
+// The address of stake account created by `BuildCreateStakeAccountTx` with the seed.
+func StakeAccountAddressWithSeed(owner, seed string) (string, error) {
+	if !IsValidAddress(owner) {
+		return "", errors.New("Invalid owner address")
+	}
+	return common.CreateWithSeed(common.PublicKeyFromString(owner), seed, common.StakeProgramID).ToBase58(), nil
+}
+
+// Create a stake account derived from the owner with the seed, and delegate it to the validator.
+// The owner is both the staker and withdrawer, the rent-exempt reserve is added to the amount.
+// @param seed the seed to derive the stake account, max 32 characters. empty means a seed generated by the current time.
+func (c *Chain) BuildCreateStakeAccountTx(account *Account, voteAccount, amount, seed string) (*base.OptionalString, error) {
+	if !IsValidAddress(voteAccount) {
+		return nil, errors.New("Invalid vote account")
+	}
+	lamports, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return nil, errors.New("Invalid amount")
+	}
+	if seed == "" {
+		seed = "stake:" + strconv.FormatInt(time.Now().UnixMilli(), 10)
+	}
+	rent, err := c.client().GetMinimumBalanceForRentExemption(context.Background(), stakeprog.AccountSize)
+	if err != nil {
+		return nil, err
+	}
+	owner := account.account.PublicKey
+	stake := common.CreateWithSeed(owner, seed, common.StakeProgramID)
+	instructions := []types.Instruction{
+		sysprog.CreateAccountWithSeed(sysprog.CreateAccountWithSeedParam{
+			From:     owner,
+			New:      stake,
+			Base:     owner,
+			Owner:    common.StakeProgramID,
+			Seed:     seed,
+			Lamports: lamports + rent,
+			Space:    stakeprog.AccountSize,
+		}),
+		stakeprog.Initialize(stakeprog.InitializeParam{
+			Stake: stake,
+			Auth:  stakeprog.Authorized{Staker: owner, Withdrawer: owner},
+		}),
+		stakeprog.DelegateStake(stakeprog.DelegateStakeParam{
+			Stake: stake,
+			Auth:  owner,
+			Vote:  common.PublicKeyFromString(voteAccount),
+		}),
+	}
+	return c.buildStakeTx(account, instructions)
+}
+
+// Deactivate the stake account, it can be withdrawn after the cooldown.
+func (c *Chain) BuildDeactivateStakeTx(account *Account, stakeAccount string) (*base.OptionalString, error) {
+	if !IsValidAddress(stakeAccount) {
+		return nil, errors.New("Invalid stake account")
+	}
+	return c.buildStakeTx(account, []types.Instruction{
+		stakeprog.Deactivate(stakeprog.DeactivateParam{
+			Stake: common.PublicKeyFromString(stakeAccount),
+			Auth:  account.account.PublicKey,
+		}),
+	})
+}
+
+// Withdraw the inactive lamports of the stake account to the owner, the stake account will be closed if all lamports are withdrawn.
+func (c *Chain) BuildWithdrawStakeTx(account *Account, stakeAccount, amount string) (*base.OptionalString, error) {
+	if !IsValidAddress(stakeAccount) {
+		return nil, errors.New("Invalid stake account")
+	}
+	lamports, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return nil, errors.New("Invalid amount")
+	}
+	return c.buildStakeTx(account, []types.Instruction{
+		stakeprog.Withdraw(stakeprog.WithdrawParam{
+			Stake:    common.PublicKeyFromString(stakeAccount),
+			Auth:     account.account.PublicKey,
+			To:       account.account.PublicKey,
+			Lamports: lamports,
+		}),
+	})
+}
+
+// Split the amount of the stake account into a new stake account derived from the owner with the seed.
+// The rent-exempt reserve of the new stake account is transferred from the owner, it's extra to the amount.
+// @param seed see `BuildCreateStakeAccountTx`
+func (c *Chain) BuildSplitStakeTx(account *Account, stakeAccount, amount, seed string) (*base.OptionalString, error) {
+	if !IsValidAddress(stakeAccount) {
+		return nil, errors.New("Invalid stake account")
+	}
+	lamports, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return nil, errors.New("Invalid amount")
+	}
+	if seed == "" {
+		seed = "stake:" + strconv.FormatInt(time.Now().UnixMilli(), 10)
+	}
+	rent, err := c.client().GetMinimumBalanceForRentExemption(context.Background(), stakeprog.AccountSize)
+	if err != nil {
+		return nil, err
+	}
+	owner := account.account.PublicKey
+	splitStake := common.CreateWithSeed(owner, seed, common.StakeProgramID)
+	return c.buildStakeTx(account, []types.Instruction{
+		// The split destination must be rent-exempt before the split.
+		sysprog.Transfer(sysprog.TransferParam{
+			From:   owner,
+			To:     splitStake,
+			Amount: rent,
+		}),
+		sysprog.AllocateWithSeed(sysprog.AllocateWithSeedParam{
+			Account: splitStake,
+			Base:    owner,
+			Owner:   common.StakeProgramID,
+			Seed:    seed,
+			Space:   stakeprog.AccountSize,
+		}),
+		stakeprog.Split(stakeprog.SplitParam{
+			Stake:      common.PublicKeyFromString(stakeAccount),
+			Auth:       owner,
+			SplitStake: splitStake,
+			Lamports:   lamports,
+		}),
+	})
+}
+
+// Merge the source stake account into the destination, the source will be closed.
+// The two accounts must have the same authorities and lockup, and be in the compatible states.
+func (c *Chain) BuildMergeStakeTx(account *Account, destinationStake, sourceStake string) (*base.OptionalString, error) {
+	if !IsValidAddress(destinationStake) || !IsValidAddress(sourceStake) {
+		return nil, errors.New("Invalid stake account")
+	}
+	return c.buildStakeTx(account, []types.Instruction{
+		stakeprog.Merge(stakeprog.MergeParam{
+			From: common.PublicKeyFromString(sourceStake),
+			Auth: account.account.PublicKey,
+			To:   common.PublicKeyFromString(destinationStake),
+		}),
+	})
+}
+
+func (c *Chain) buildStakeTx(account *Account, instructions []types.Instruction) (*base.OptionalString, error) {
+	message, err := c.newMessage(account.account.PublicKey, instructions, nil)
+	if err != nil {
+		return nil, err
+	}
+	return signMessage(message, account)
+}
@@ -0,0 +1,91 @@
+package solana
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStakeStateOfEpoch(t *testing.T) {
+	require.Equal(t, StakeStateActivating, stakeStateOfEpoch(100, 100, -1))
+	require.Equal(t, StakeStateActive, stakeStateOfEpoch(101, 100, -1))
+	require.Equal(t, StakeStateDeactivating, stakeStateOfEpoch(110, 100, 110))
+	require.Equal(t, StakeStateInactive, stakeStateOfEpoch(111, 100, 110))
+	require.Equal(t, int64(-1), parseEpoch("18446744073709551615"))
+	require.Equal(t, int64(300), parseEpoch("300"))
+}
+
+func TestStakeAccountAddressWithSeed(t *testing.T) {
+	owner := "9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g"
+	address, err := StakeAccountAddressWithSeed(owner, "stake:0")
+	require.Nil(t, err)
+	require.True(t, IsValidAddress(address))
+	address2, err := StakeAccountAddressWithSeed(owner, "stake:1")
+	require.Nil(t, err)
+	require.NotEqual(t, address, address2)
+}
+
+func TestBuildSplitStakeTx(t *testing.T) {
+	const rent = 2282880
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": 1}
+		switch req.Method {
+		case "getMinimumBalanceForRentExemption":
+			res["result"] = rent
+		case "getLatestBlockhash":
+			res["result"] = map[string]interface{}{
+				"context": map[string]interface{}{"slot": 1},
+				"value":   map[string]interface{}{"blockhash": "9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g", "lastValidBlockHeight": 100},
+			}
+		default:
+			t.Fatalf("unexpected method %v", req.Method)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	account := newRandomAccount()
+	stakeAccount := "9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g"
+	signed, err := NewChainWithRpc(server.URL).BuildSplitStakeTx(account, stakeAccount, "1000000000", "stake:0")
+	require.Nil(t, err)
+	tx, err := NewVersionedTransactionWithString(signed.Value)
+	require.Nil(t, err)
+	splitStake, err := StakeAccountAddressWithSeed(account.Address(), "stake:0")
+	require.Nil(t, err)
+
+	message := tx.Message
+	require.Equal(t, 3, len(message.Instructions))
+	// the rent-exempt reserve is transferred to the split stake before the split.
+	transfer := message.Instructions[0]
+	require.Equal(t, common.SystemProgramID, message.AccountKeys[transfer.ProgramIDIndex])
+	require.Equal(t, account.Address(), message.AccountKeys[transfer.Accounts[0]].ToBase58())
+	require.Equal(t, splitStake, message.AccountKeys[transfer.Accounts[1]].ToBase58())
+	require.Equal(t, uint32(2), binary.LittleEndian.Uint32(transfer.Data)) // Transfer
+	require.Equal(t, uint64(rent), binary.LittleEndian.Uint64(transfer.Data[4:]))
+
+	split := message.Instructions[2]
+	require.Equal(t, common.StakeProgramID, message.AccountKeys[split.ProgramIDIndex])
+	require.Equal(t, uint64(1000000000), binary.LittleEndian.Uint64(split.Data[4:]))
+}
+
+func TestFetchValidatorsAndStakeAccounts(t *testing.T) {
+	chain := NewChainWithRpc(rpc.DevnetRPCEndpoint)
+	validators, err := chain.FetchValidators()
+	require.Nil(t, err)
+	require.NotEqual(t, 0, validators.Count())
+	t.Log(validators.ValueOf(0))
+
+	stakes, err := chain.FetchStakeAccounts("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g")
+	require.Nil(t, err)
+	t.Log(stakes.Count())
+}
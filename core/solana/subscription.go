@@ -0,0 +1,25 @@
+package solana
+
+import (
+	"encoding/json"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+// Subscribe the confirmation of the transaction, the message is the json of result, e.g. `{"context":{"slot":5207624},"value":{"err":null}}`
+// The subscription is finished after the notification.
+// @param commitment "processed", "confirmed" or "finalized", default is "finalized".
+func NewSignatureSubscription(wsUrl, signature, commitment string) *base.Subscription {
+	params := []any{signature}
+	if commitment != "" {
+		params = append(params, map[string]any{"commitment": commitment})
+	}
+	sub := base.NewSubscription(wsUrl, "signatureSubscribe", params, "signatureUnsubscribe")
+	return sub.SetFinished(func(result json.RawMessage) bool { return true })
+}
+
+// Subscribe the changes of the account, the message is the json of result with the `jsonParsed` account data.
+func NewAccountSubscription(wsUrl, address string) *base.Subscription {
+	params := []any{address, map[string]any{"encoding": "jsonParsed", "commitment": "confirmed"}}
+	return base.NewSubscription(wsUrl, "accountSubscribe", params, "accountUnsubscribe")
+}
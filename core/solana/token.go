@@ -1,13 +1,10 @@
 package solana
 
 import (
-	"context"
 	"errors"
 	"strconv"
 
-	hexTypes "github.com/centrifuge/go-substrate-rpc-client/v4/types"
 	"github.com/coming-chat/wallet-SDK/core/base"
-	"github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
 	"github.com/portto/solana-go-sdk/program/sysprog"
 	"github.com/portto/solana-go-sdk/types"
@@ -58,47 +55,33 @@ func (t *Token) BuildTransferTx(privateKey, receiverAddress, amount string) (*ba
 }
 
 func (t *Token) BuildTransferTxWithAccount(account *Account, receiverAddress, amount string) (*base.OptionalString, error) {
-	client := t.chain.client()
-	message, err := transactionMessage(client, account.Address(), receiverAddress, amount)
-	if err != nil {
-		return nil, err
-	}
-
-	// create tx by message + signer
-	tx, err := types.NewTransaction(types.NewTransactionParam{
-		Message: *message,
-		Signers: []types.Account{*account.account, *account.account},
-	})
-	if err != nil {
-		return nil, err
-	}
+	return t.BuildTransferTxWithOptions(account, receiverAddress, amount, nil)
+}
 
-	bytes, err := tx.Serialize()
+// Build the transfer transaction with the compute budget or durable nonce.
+// @param options nil means no compute budget instructions and the latest blockhash is used.
+func (t *Token) BuildTransferTxWithOptions(account *Account, receiverAddress, amount string, options *TransactionOptions) (*base.OptionalString, error) {
+	message, err := t.transactionMessage(account.Address(), receiverAddress, amount, options)
 	if err != nil {
 		return nil, err
 	}
-	hash := hexTypes.HexEncodeToString(bytes)
-
-	return &base.OptionalString{Value: hash}, nil
+	return signMessage(message, account)
 }
 
 func (t *Token) EstimateFees(receiverAddress, amount string) (*base.OptionalString, error) {
-	client := t.chain.client()
-	message, err := transactionMessage(client, receiverAddress, receiverAddress, amount)
-	if err != nil {
-		return nil, err
-	}
+	return t.EstimateFeesWithOptions(receiverAddress, amount, nil)
+}
 
-	fee, err := client.GetFeeForMessage(context.Background(), *message)
+// The fee contains the priority fee of the compute budget in the options.
+func (t *Token) EstimateFeesWithOptions(receiverAddress, amount string, options *TransactionOptions) (*base.OptionalString, error) {
+	message, err := t.transactionMessage(receiverAddress, receiverAddress, amount, options)
 	if err != nil {
 		return nil, err
 	}
-	feeString := strconv.FormatUint(*fee, 10)
-
-	return &base.OptionalString{Value: feeString}, nil
+	return t.chain.estimateFeeOfMessage(message)
 }
 
-func transactionMessage(client *client.Client, fromAddress, toAddress, amount string) (*types.Message, error) {
+func (t *Token) transactionMessage(fromAddress, toAddress, amount string, options *TransactionOptions) (*types.Message, error) {
 	if !IsValidAddress(toAddress) {
 		return nil, errors.New("Invalid receiver address")
 	}
@@ -109,24 +92,10 @@ func transactionMessage(client *client.Client, fromAddress, toAddress, amount st
 	pubTo := common.PublicKeyFromString(toAddress)
 	pubFrom := common.PublicKeyFromString(fromAddress) // from is same as to, or it's must valid
 
-	// to fetch recent blockhash
-	res, err := client.GetRecentBlockhash(context.Background())
-	if err != nil {
-		return nil, err
-	}
-
-	// create a message
-	message := types.NewMessage(types.NewMessageParam{
-		FeePayer:        pubFrom,
-		RecentBlockhash: res.Blockhash, // recent blockhash
-		Instructions: []types.Instruction{
-			sysprog.Transfer(sysprog.TransferParam{
-				From:   pubFrom, // from
-				To:     pubTo,   // to
-				Amount: amountUint,
-			}),
-		},
+	transfer := sysprog.Transfer(sysprog.TransferParam{
+		From:   pubFrom, // from
+		To:     pubTo,   // to
+		Amount: amountUint,
 	})
-
-	return &message, nil
+	return t.chain.newMessage(pubFrom, []types.Instruction{transfer}, options)
 }
@@ -0,0 +1,364 @@
+package solana
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/decred/base58"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/sysprog"
+	"github.com/portto/solana-go-sdk/program/tokenprog"
+	"github.com/portto/solana-go-sdk/rpc"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// The SOL or SPL token transfer decoded from the instruction.
+type TokenTransfer struct {
+	// The mint address of token, empty means SOL.
+	Mint string
+	// The owner of the source account.
+	From string
+	// The owner of the destination account, it's the token account if the owner is unknown.
+	To string
+	// The source and destination token accounts, empty for SOL.
+	FromTokenAccount string
+	ToTokenAccount   string
+
+	Amount string
+	// The decimals of token, -1 means unknown.
+	Decimals int16
+	// The transfer is from the inner instruction, e.g. the transfer of swap.
+	IsInner bool
+}
+
+// The associated token account created by the transaction.
+type TokenAccountCreation struct {
+	Account string
+	Owner   string
+	Mint    string
+	Payer   string
+}
+
+// The token balance change of the token account, it's calculated from the pre and post token balances.
+type TokenBalanceChange struct {
+	TokenAccount string
+	Owner        string
+	Mint         string
+	Decimals     int16
+	PreAmount    string
+	PostAmount   string
+	// The signed change amount, e.g. "-1000".
+	Change string
+}
+
+// The transaction detail with the transfers of SOL and SPL tokens.
+type TransactionDetail struct {
+	*base.TransactionDetail
+
+	// -1 means legacy transaction.
+	Version int
+
+	Transfers             []*TokenTransfer
+	TokenAccountCreations []*TokenAccountCreation
+	TokenBalanceChanges   []*TokenBalanceChange
+}
+
+func (d *TransactionDetail) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(d)
+}
+
+// The raw response of `getTransaction` with base64 encoding.
+type rawTransactionResponse struct {
+	Slot        uint64   `json:"slot"`
+	BlockTime   *int64   `json:"blockTime"`
+	Transaction []string `json:"transaction"`
+	Meta        *struct {
+		Err               interface{}                       `json:"err"`
+		Fee               uint64                            `json:"fee"`
+		PreTokenBalances  []rpc.TransactionMetaTokenBalance `json:"preTokenBalances"`
+		PostTokenBalances []rpc.TransactionMetaTokenBalance `json:"postTokenBalances"`
+		LogMessages       []string                          `json:"logMessages"`
+		InnerInstructions []struct {
+			Index        int `json:"index"`
+			Instructions []struct {
+				ProgramIdIndex int    `json:"programIdIndex"`
+				Accounts       []int  `json:"accounts"`
+				Data           string `json:"data"` // base58
+			} `json:"instructions"`
+		} `json:"innerInstructions"`
+		LoadedAddresses *struct {
+			Writable []string `json:"writable"`
+			Readonly []string `json:"readonly"`
+		} `json:"loadedAddresses"`
+	} `json:"meta"`
+}
+
+// Fetch the legacy or versioned transaction.
+// @return nil if the transaction is not found.
+func (c *Chain) fetchRawTransaction(hash string) (*rawTransactionResponse, error) {
+	var res *rawTransactionResponse
+	err := c.callRpc(&res, "getTransaction", hash, map[string]interface{}{
+		"encoding":                       "base64",
+		"maxSupportedTransactionVersion": 0,
+	})
+	return res, err
+}
+
+// Fetch the transaction detail with the SOL and SPL token transfers, including the inner instructions.
+func (c *Chain) FetchFullTransactionDetail(hash string) (*TransactionDetail, error) {
+	res, err := c.fetchRawTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFullTransaction(hash, res)
+}
+
+func decodeFullTransaction(hash string, res *rawTransactionResponse) (detail *TransactionDetail, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	detail = &TransactionDetail{
+		TransactionDetail: &base.TransactionDetail{HashString: hash},
+		Version:           MessageVersionLegacy,
+	}
+	if res == nil || res.BlockTime == nil || res.Meta == nil {
+		detail.Status = base.TransactionStatusPending
+		return detail, nil
+	}
+	if len(res.Transaction) == 0 {
+		return nil, ErrInvalidTransactionData
+	}
+	data, err := base64.StdEncoding.DecodeString(res.Transaction[0])
+	if err != nil {
+		return nil, err
+	}
+	tx, err := VersionedTransactionDeserialize(data)
+	if err != nil {
+		return nil, err
+	}
+	meta := res.Meta
+	detail.Version = tx.Message.Version
+	detail.EstimateFees = strconv.FormatUint(meta.Fee, 10)
+	detail.FinishTimestamp = *res.BlockTime
+	if meta.Err == nil {
+		detail.Status = base.TransactionStatusSuccess
+	} else {
+		detail.Status = base.TransactionStatusFailure
+		if len(meta.LogMessages) > 0 {
+			detail.FailureMessage = meta.LogMessages[len(meta.LogMessages)-1]
+		}
+	}
+
+	// static accounts, then the writable and readonly accounts loaded from lookup tables.
+	accounts := make([]string, 0, len(tx.Message.AccountKeys))
+	for _, key := range tx.Message.AccountKeys {
+		accounts = append(accounts, key.ToBase58())
+	}
+	if meta.LoadedAddresses != nil {
+		accounts = append(accounts, meta.LoadedAddresses.Writable...)
+		accounts = append(accounts, meta.LoadedAddresses.Readonly...)
+	}
+	decoder := newInstructionDecoder(accounts, meta.PreTokenBalances, meta.PostTokenBalances)
+
+	inners := make(map[int][]types.CompiledInstruction)
+	for _, inner := range meta.InnerInstructions {
+		for _, instruction := range inner.Instructions {
+			inners[inner.Index] = append(inners[inner.Index], types.CompiledInstruction{
+				ProgramIDIndex: instruction.ProgramIdIndex,
+				Accounts:       instruction.Accounts,
+				Data:           base58.Decode(instruction.Data),
+			})
+		}
+	}
+	for i, instruction := range tx.Message.Instructions {
+		decoder.decode(instruction, false)
+		for _, inner := range inners[i] {
+			decoder.decode(inner, true)
+		}
+	}
+	detail.Transfers = decoder.transfers
+	detail.TokenAccountCreations = decoder.creations
+	detail.TokenBalanceChanges = tokenBalanceChanges(accounts, meta.PreTokenBalances, meta.PostTokenBalances)
+
+	// The first transfer of outer instructions is the main transfer.
+	// Only the SOL amount is filled, the amount and mint of SPL transfers can only be found in `Transfers`.
+	for _, transfer := range detail.Transfers {
+		if transfer.IsInner {
+			continue
+		}
+		detail.FromAddress = transfer.From
+		detail.ToAddress = transfer.To
+		if transfer.Mint == "" {
+			detail.Amount = transfer.Amount
+		}
+		break
+	}
+	return detail, nil
+}
+
+type tokenAccountInfo struct {
+	mint     string
+	owner    string
+	decimals int16
+}
+
+type instructionDecoder struct {
+	accounts      []string
+	tokenAccounts map[string]*tokenAccountInfo
+
+	transfers []*TokenTransfer
+	creations []*TokenAccountCreation
+}
+
+func newInstructionDecoder(accounts []string, pre, post []rpc.TransactionMetaTokenBalance) *instructionDecoder {
+	tokenAccounts := make(map[string]*tokenAccountInfo)
+	for _, balance := range append(pre, post...) {
+		if int(balance.AccountIndex) >= len(accounts) {
+			continue
+		}
+		tokenAccounts[accounts[balance.AccountIndex]] = &tokenAccountInfo{
+			mint:     balance.Mint,
+			owner:    balance.Owner,
+			decimals: int16(balance.UITokenAmount.Decimals),
+		}
+	}
+	return &instructionDecoder{accounts: accounts, tokenAccounts: tokenAccounts}
+}
+
+func (d *instructionDecoder) account(instruction types.CompiledInstruction, index int) string {
+	if index >= len(instruction.Accounts) || instruction.Accounts[index] >= len(d.accounts) {
+		return ""
+	}
+	return d.accounts[instruction.Accounts[index]]
+}
+
+// The owner of the token account, it's the token account itself if the owner is unknown.
+func (d *instructionDecoder) ownerOf(tokenAccount string) string {
+	if info, ok := d.tokenAccounts[tokenAccount]; ok && info.owner != "" {
+		return info.owner
+	}
+	return tokenAccount
+}
+
+func (d *instructionDecoder) decode(instruction types.CompiledInstruction, isInner bool) {
+	if instruction.ProgramIDIndex >= len(d.accounts) {
+		return
+	}
+	data := instruction.Data
+	switch d.accounts[instruction.ProgramIDIndex] {
+	case common.SystemProgramID.ToBase58():
+		if len(data) < 12 {
+			return
+		}
+		toIndex := -1
+		switch sysprog.Instruction(binary.LittleEndian.Uint32(data[:4])) {
+		case sysprog.InstructionTransfer:
+			toIndex = 1
+		case sysprog.InstructionTransferWithSeed:
+			toIndex = 2
+		}
+		if toIndex == -1 {
+			return
+		}
+		d.transfers = append(d.transfers, &TokenTransfer{
+			From:     d.account(instruction, 0),
+			To:       d.account(instruction, toIndex),
+			Amount:   strconv.FormatUint(binary.LittleEndian.Uint64(data[4:12]), 10),
+			Decimals: 9,
+			IsInner:  isInner,
+		})
+
+	case common.TokenProgramID.ToBase58(), Token2022ProgramID.ToBase58():
+		if len(data) < 9 {
+			return
+		}
+		var source, destination, authority, mint string
+		decimals := int16(-1)
+		switch tokenprog.Instruction(data[0]) {
+		case tokenprog.InstructionTransfer:
+			source, destination, authority = d.account(instruction, 0), d.account(instruction, 1), d.account(instruction, 2)
+			if info, ok := d.tokenAccounts[source]; ok {
+				mint, decimals = info.mint, info.decimals
+			}
+		case tokenprog.InstructionTransferChecked:
+			if len(data) < 10 {
+				return
+			}
+			source, mint, destination, authority = d.account(instruction, 0), d.account(instruction, 1), d.account(instruction, 2), d.account(instruction, 3)
+			decimals = int16(data[9])
+		default:
+			return
+		}
+		d.transfers = append(d.transfers, &TokenTransfer{
+			Mint:             mint,
+			From:             authority,
+			To:               d.ownerOf(destination),
+			FromTokenAccount: source,
+			ToTokenAccount:   destination,
+			Amount:           strconv.FormatUint(binary.LittleEndian.Uint64(data[1:9]), 10),
+			Decimals:         decimals,
+			IsInner:          isInner,
+		})
+
+	case common.SPLAssociatedTokenAccountProgramID.ToBase58():
+		// Create or CreateIdempotent, the data of the legacy create instruction is empty.
+		if len(data) > 1 || (len(data) == 1 && data[0] > 1) {
+			return
+		}
+		creation := &TokenAccountCreation{
+			Payer:   d.account(instruction, 0),
+			Account: d.account(instruction, 1),
+			Owner:   d.account(instruction, 2),
+			Mint:    d.account(instruction, 3),
+		}
+		d.creations = append(d.creations, creation)
+		if _, ok := d.tokenAccounts[creation.Account]; !ok {
+			d.tokenAccounts[creation.Account] = &tokenAccountInfo{mint: creation.Mint, owner: creation.Owner, decimals: -1}
+		}
+	}
+}
+
+func tokenBalanceChanges(accounts []string, pre, post []rpc.TransactionMetaTokenBalance) []*TokenBalanceChange {
+	changes := make([]*TokenBalanceChange, 0)
+	preBalances := make(map[uint64]rpc.TransactionMetaTokenBalance)
+	for _, balance := range pre {
+		preBalances[balance.AccountIndex] = balance
+	}
+	changeOf := func(balance rpc.TransactionMetaTokenBalance, preAmount, postAmount string) {
+		preInt, _ := big.NewInt(0).SetString(preAmount, 10)
+		postInt, _ := big.NewInt(0).SetString(postAmount, 10)
+		if preInt == nil || postInt == nil {
+			return
+		}
+		change := big.NewInt(0).Sub(postInt, preInt)
+		if change.Sign() == 0 || int(balance.AccountIndex) >= len(accounts) {
+			return
+		}
+		changes = append(changes, &TokenBalanceChange{
+			TokenAccount: accounts[balance.AccountIndex],
+			Owner:        balance.Owner,
+			Mint:         balance.Mint,
+			Decimals:     int16(balance.UITokenAmount.Decimals),
+			PreAmount:    preAmount,
+			PostAmount:   postAmount,
+			Change:       change.String(),
+		})
+	}
+	for _, balance := range post {
+		preAmount := "0"
+		if preBalance, ok := preBalances[balance.AccountIndex]; ok {
+			preAmount = preBalance.UITokenAmount.Amount
+			delete(preBalances, balance.AccountIndex)
+		}
+		changeOf(balance, preAmount, balance.UITokenAmount.Amount)
+	}
+	// the token accounts closed by the transaction.
+	for _, balance := range pre {
+		if _, ok := preBalances[balance.AccountIndex]; ok {
+			changeOf(balance, balance.UITokenAmount.Amount, "0")
+		}
+	}
+	return changes
+}
@@ -0,0 +1,147 @@
+package solana
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/sysprog"
+	"github.com/portto/solana-go-sdk/program/tokenprog"
+	"github.com/portto/solana-go-sdk/rpc"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeFullTransaction(t *testing.T) {
+	sender := newRandomAccount()
+	receiver := common.PublicKeyFromString("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g")
+	mint := common.PublicKeyFromString("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	ataFrom, err := findAssociatedTokenAddress(sender.account.PublicKey, mint, common.TokenProgramID)
+	require.Nil(t, err)
+	ataTo, err := findAssociatedTokenAddress(receiver, mint, common.TokenProgramID)
+	require.Nil(t, err)
+
+	message := types.NewMessage(types.NewMessageParam{
+		FeePayer:        sender.account.PublicKey,
+		RecentBlockhash: "9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g",
+		Instructions: []types.Instruction{
+			createAssociatedTokenAccountIdempotent(sender.account.PublicKey, receiver, mint, ataTo, common.TokenProgramID),
+			tokenprog.TransferChecked(tokenprog.TransferCheckedParam{
+				From: ataFrom, To: ataTo, Mint: mint, Auth: sender.account.PublicKey, Amount: 1500, Decimals: 6,
+			}),
+		},
+	})
+	signed, err := signMessage(&message, sender)
+	require.Nil(t, err)
+	tx, err := NewVersionedTransactionWithString(signed.Value)
+	require.Nil(t, err)
+
+	indexOf := func(key common.PublicKey) uint64 {
+		for i, k := range message.Accounts {
+			if k == key {
+				return uint64(i)
+			}
+		}
+		t.Fatal("account not found")
+		return 0
+	}
+	tokenBalance := func(account common.PublicKey, owner common.PublicKey, amount string) rpc.TransactionMetaTokenBalance {
+		return rpc.TransactionMetaTokenBalance{
+			AccountIndex:  indexOf(account),
+			Mint:          mint.ToBase58(),
+			Owner:         owner.ToBase58(),
+			UITokenAmount: rpc.GetTokenAccountBalanceResultValue{Amount: amount, Decimals: 6},
+		}
+	}
+	blockTime := int64(1700000000)
+	res := &rawTransactionResponse{
+		BlockTime:   &blockTime,
+		Transaction: []string{base64.StdEncoding.EncodeToString(tx.Serialize()), "base64"},
+	}
+	metaJson, _ := json.Marshal(map[string]interface{}{
+		"err":               nil,
+		"fee":               5000,
+		"preTokenBalances":  []rpc.TransactionMetaTokenBalance{tokenBalance(ataFrom, sender.account.PublicKey, "2000")},
+		"postTokenBalances": []rpc.TransactionMetaTokenBalance{tokenBalance(ataFrom, sender.account.PublicKey, "500"), tokenBalance(ataTo, receiver, "1500")},
+		"innerInstructions": []interface{}{},
+	})
+	require.Nil(t, json.Unmarshal([]byte(`{"meta":`+string(metaJson)+`}`), res))
+
+	detail, err := decodeFullTransaction(tx.TxHash(), res)
+	require.Nil(t, err)
+	require.Equal(t, base.TransactionStatusSuccess, detail.Status)
+	require.Equal(t, "5000", detail.EstimateFees)
+	// the amount of SPL transfer is not in lamports, it's only in the transfers.
+	require.Equal(t, sender.Address(), detail.FromAddress)
+	require.Equal(t, receiver.ToBase58(), detail.ToAddress)
+	require.Equal(t, "", detail.Amount)
+
+	require.Equal(t, 1, len(detail.TokenAccountCreations))
+	require.Equal(t, ataTo.ToBase58(), detail.TokenAccountCreations[0].Account)
+	require.Equal(t, 1, len(detail.Transfers))
+	require.Equal(t, mint.ToBase58(), detail.Transfers[0].Mint)
+	require.Equal(t, int16(6), detail.Transfers[0].Decimals)
+
+	require.Equal(t, 2, len(detail.TokenBalanceChanges))
+	changes := map[string]string{}
+	for _, change := range detail.TokenBalanceChanges {
+		changes[change.Owner] = change.Change
+	}
+	require.Equal(t, map[string]string{sender.Address(): "-1500", receiver.ToBase58(): "1500"}, changes)
+
+	pending, err := decodeFullTransaction(tx.TxHash(), nil)
+	require.Nil(t, err)
+	require.Equal(t, base.TransactionStatusPending, pending.Status)
+
+	// the SPL only transaction is not an error of FetchTransactionDetail
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "getTransaction", req.Method)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": res})
+	}))
+	defer server.Close()
+	baseDetail, err := NewChainWithRpc(server.URL).FetchTransactionDetail(tx.TxHash())
+	require.Nil(t, err)
+	require.Equal(t, base.TransactionStatusSuccess, baseDetail.Status)
+	require.Equal(t, "5000", baseDetail.EstimateFees)
+	require.Equal(t, blockTime, baseDetail.FinishTimestamp)
+	require.Equal(t, sender.Address(), baseDetail.FromAddress)
+	require.Equal(t, receiver.ToBase58(), baseDetail.ToAddress)
+	require.Equal(t, "", baseDetail.Amount)
+}
+
+func TestDecodeFullTransaction_SOL(t *testing.T) {
+	sender := newRandomAccount()
+	receiver := common.PublicKeyFromString("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g")
+	message := types.NewMessage(types.NewMessageParam{
+		FeePayer:        sender.account.PublicKey,
+		RecentBlockhash: "9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g",
+		Instructions: []types.Instruction{
+			sysprog.Transfer(sysprog.TransferParam{From: sender.account.PublicKey, To: receiver, Amount: 1000}),
+		},
+	})
+	signed, err := signMessage(&message, sender)
+	require.Nil(t, err)
+	tx, err := NewVersionedTransactionWithString(signed.Value)
+	require.Nil(t, err)
+
+	blockTime := int64(1700000000)
+	res := &rawTransactionResponse{
+		BlockTime:   &blockTime,
+		Transaction: []string{base64.StdEncoding.EncodeToString(tx.Serialize()), "base64"},
+	}
+	require.Nil(t, json.Unmarshal([]byte(`{"meta":{"err":null,"fee":5000}}`), res))
+
+	detail, err := decodeFullTransaction(tx.TxHash(), res)
+	require.Nil(t, err)
+	require.Equal(t, sender.Address(), detail.FromAddress)
+	require.Equal(t, receiver.ToBase58(), detail.ToAddress)
+	require.Equal(t, "1000", detail.Amount)
+}
@@ -0,0 +1,281 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	hexTypes "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/cmptbdgprog"
+	"github.com/portto/solana-go-sdk/program/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// The options of building transaction.
+// The priority fee is `ComputeUnitLimit * ComputeUnitPrice / 1e6` lamports, it's paid in addition to the signature fee.
+type TransactionOptions struct {
+	// The maximum compute units the transaction can consume. 0 means not set, the runtime default is 200000 per instruction.
+	ComputeUnitLimit int64
+	// The price of compute unit (micro-lamports). 0 means no priority fee.
+	ComputeUnitPrice int64
+
+	// The durable nonce account, the stored nonce will be used instead of the recent blockhash, so the transaction will not expire.
+	NonceAccount string
+	// The authority of the nonce account, it must sign the transaction. Empty means the fee payer.
+	// The builders only sign with the fee payer, so the other authority is not supported.
+	NonceAuthority string
+}
+
+func NewTransactionOptions() *TransactionOptions {
+	return &TransactionOptions{}
+}
+
+// The json of `TransactionOptions`, it can be passed to the transfer builders.
+func NewTransactionOptionsWithJsonString(str string) (*TransactionOptions, error) {
+	var o TransactionOptions
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (o *TransactionOptions) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(o)
+}
+
+// Build the message with the options, the compute budget and advance nonce instructions are prepended to the instructions.
+func (c *Chain) newMessage(feePayer common.PublicKey, instructions []types.Instruction, options *TransactionOptions) (*types.Message, error) {
+	if options == nil {
+		options = &TransactionOptions{}
+	}
+	prefix := []types.Instruction{}
+	blockhash := ""
+	if options.NonceAccount != "" {
+		if !IsValidAddress(options.NonceAccount) {
+			return nil, errors.New("Invalid nonce account")
+		}
+		nonce, err := c.FetchNonce(options.NonceAccount)
+		if err != nil {
+			return nil, err
+		}
+		authority := feePayer
+		if options.NonceAuthority != "" {
+			authority = common.PublicKeyFromString(options.NonceAuthority)
+		}
+		if authority != feePayer {
+			return nil, errors.New("The nonce authority must be the fee payer")
+		}
+		// The advance nonce instruction must be the first instruction.
+		prefix = append(prefix, sysprog.AdvanceNonceAccount(sysprog.AdvanceNonceAccountParam{
+			Nonce: common.PublicKeyFromString(options.NonceAccount),
+			Auth:  authority,
+		}))
+		blockhash = nonce
+	} else {
+		latest, err := c.LatestBlockhash()
+		if err != nil {
+			return nil, err
+		}
+		blockhash = latest.Blockhash
+	}
+	if options.ComputeUnitLimit > 0 {
+		prefix = append(prefix, cmptbdgprog.SetComputeUnitLimit(cmptbdgprog.SetComputeUnitLimitParam{
+			Units: uint32(options.ComputeUnitLimit),
+		}))
+	}
+	if options.ComputeUnitPrice > 0 {
+		prefix = append(prefix, cmptbdgprog.SetComputeUnitPrice(cmptbdgprog.SetComputeUnitPriceParam{
+			MicroLamports: uint64(options.ComputeUnitPrice),
+		}))
+	}
+
+	message := types.NewMessage(types.NewMessageParam{
+		FeePayer:        feePayer,
+		RecentBlockhash: blockhash,
+		Instructions:    append(prefix, instructions...),
+	})
+	return &message, nil
+}
+
+// MARK - Blockhash
+
+type LatestBlockhash struct {
+	Blockhash string
+	// The transaction with the blockhash will expire after the block height.
+	LastValidBlockHeight int64
+}
+
+func (c *Chain) LatestBlockhash() (*LatestBlockhash, error) {
+	res, err := c.client().GetLatestBlockhash(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &LatestBlockhash{
+		Blockhash:            res.Blockhash,
+		LastValidBlockHeight: int64(res.LatestValidBlockHeight),
+	}, nil
+}
+
+// Check whether the transaction with the blockhash has expired, it can't be on-chain anymore if it's expired and not confirmed.
+// @param lastValidBlockHeight the `LastValidBlockHeight` of `LatestBlockhash`
+func (c *Chain) IsBlockhashExpired(lastValidBlockHeight int64) (bool, error) {
+	var height uint64
+	err := c.callRpc(&height, "getBlockHeight")
+	if err != nil {
+		return false, err
+	}
+	return int64(height) > lastValidBlockHeight, nil
+}
+
+// MARK - Priority fee
+
+// The suggested compute unit price (micro-lamports) from the recent prioritization fees.
+type PriorityFeeSuggestion struct {
+	Low    int64
+	Medium int64
+	High   int64
+	Max    int64
+}
+
+func (s *PriorityFeeSuggestion) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(s)
+}
+
+// Suggest the compute unit price by the prioritization fees of recent 150 blocks, the percentiles 25, 50 and 75 are used as low, medium and high.
+// @param writableAccounts the addresses of accounts written by the transaction, separated by ",". the fees of the blocks that lock the accounts will be used, empty means global.
+func (c *Chain) SuggestPriorityFee(writableAccounts string) (*PriorityFeeSuggestion, error) {
+	accounts := []string{}
+	for _, account := range strings.Split(writableAccounts, ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			accounts = append(accounts, account)
+		}
+	}
+	var res []struct {
+		Slot              uint64 `json:"slot"`
+		PrioritizationFee uint64 `json:"prioritizationFee"`
+	}
+	err := c.callRpc(&res, "getRecentPrioritizationFees", accounts)
+	if err != nil {
+		return nil, err
+	}
+	fees := make([]int64, len(res))
+	for i, r := range res {
+		fees[i] = int64(r.PrioritizationFee)
+	}
+	return priorityFeeSuggestionOfFees(fees), nil
+}
+
+func priorityFeeSuggestionOfFees(fees []int64) *PriorityFeeSuggestion {
+	if len(fees) == 0 {
+		return &PriorityFeeSuggestion{}
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+	percentile := func(p int) int64 {
+		return fees[(len(fees)-1)*p/100]
+	}
+	return &PriorityFeeSuggestion{
+		Low:    percentile(25),
+		Medium: percentile(50),
+		High:   percentile(75),
+		Max:    fees[len(fees)-1],
+	}
+}
+
+// MARK - Durable nonce
+
+// Fetch the nonce stored in the nonce account, it's used as the blockhash of durable transaction.
+func (c *Chain) FetchNonce(nonceAccount string) (string, error) {
+	account, err := c.client().GetNonceAccount(context.Background(), nonceAccount)
+	if err != nil {
+		return "", err
+	}
+	return account.Nonce.ToBase58(), nil
+}
+
+// Create and initialize the nonce account, the creator is the authority of the nonce account.
+// @param nonceAccount the new keypair of the nonce account, it also signs the transaction.
+func (c *Chain) BuildCreateNonceAccountTx(account, nonceAccount *Account) (*base.OptionalString, error) {
+	rent, err := c.client().GetMinimumBalanceForRentExemption(context.Background(), sysprog.NonceAccountSize)
+	if err != nil {
+		return nil, err
+	}
+	payer := account.account.PublicKey
+	nonce := nonceAccount.account.PublicKey
+	instructions := []types.Instruction{
+		sysprog.CreateAccount(sysprog.CreateAccountParam{
+			From:     payer,
+			New:      nonce,
+			Owner:    common.SystemProgramID,
+			Lamports: rent,
+			Space:    sysprog.NonceAccountSize,
+		}),
+		sysprog.InitializeNonceAccount(sysprog.InitializeNonceAccountParam{
+			Nonce: nonce,
+			Auth:  payer,
+		}),
+	}
+	message, err := c.newMessage(payer, instructions, nil)
+	if err != nil {
+		return nil, err
+	}
+	return signMessage(message, account, nonceAccount)
+}
+
+// Advance the nonce of the nonce account, the signed durable transactions with the old nonce will be invalid.
+func (c *Chain) BuildAdvanceNonceTx(account *Account, nonceAccount string) (*base.OptionalString, error) {
+	if !IsValidAddress(nonceAccount) {
+		return nil, errors.New("Invalid nonce account")
+	}
+	payer := account.account.PublicKey
+	instruction := sysprog.AdvanceNonceAccount(sysprog.AdvanceNonceAccountParam{
+		Nonce: common.PublicKeyFromString(nonceAccount),
+		Auth:  payer,
+	})
+	message, err := c.newMessage(payer, []types.Instruction{instruction}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return signMessage(message, account)
+}
+
+func signMessage(message *types.Message, signers ...*Account) (*base.OptionalString, error) {
+	accounts := make([]types.Account, len(signers))
+	for i, signer := range signers {
+		accounts[i] = *signer.account
+	}
+	// the transaction with the empty signature will be rejected by the node, so it should not be returned.
+	for _, required := range message.Accounts[:message.Header.NumRequireSignatures] {
+		signed := false
+		for _, account := range accounts {
+			signed = signed || account.PublicKey == required
+		}
+		if !signed {
+			return nil, errors.New("Missing the signature of " + required.ToBase58())
+		}
+	}
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: *message,
+		Signers: accounts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := tx.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return &base.OptionalString{Value: hexTypes.HexEncodeToString(bytes)}, nil
+}
+
+func (c *Chain) estimateFeeOfMessage(message *types.Message) (*base.OptionalString, error) {
+	fee, err := c.client().GetFeeForMessage(context.Background(), *message)
+	if err != nil {
+		return nil, err
+	}
+	if fee == nil {
+		return nil, errors.New("The blockhash of the message has expired")
+	}
+	return &base.OptionalString{Value: strconv.FormatUint(*fee, 10)}, nil
+}
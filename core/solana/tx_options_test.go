@@ -0,0 +1,75 @@
+package solana
+
+import (
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/sysprog"
+	"github.com/portto/solana-go-sdk/rpc"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityFeeSuggestionOfFees(t *testing.T) {
+	suggestion := priorityFeeSuggestionOfFees([]int64{})
+	require.Equal(t, PriorityFeeSuggestion{}, *suggestion)
+
+	suggestion = priorityFeeSuggestionOfFees([]int64{90, 10, 50, 0, 70, 30, 20, 80, 60, 40, 100})
+	require.Equal(t, PriorityFeeSuggestion{Low: 20, Medium: 50, High: 70, Max: 100}, *suggestion)
+}
+
+func TestSignMessageMissingSigner(t *testing.T) {
+	account := newRandomAccount()
+	payer := account.account.PublicKey
+	advance := func(authority common.PublicKey) *types.Message {
+		message := types.NewMessage(types.NewMessageParam{
+			FeePayer:        payer,
+			RecentBlockhash: "9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g",
+			Instructions: []types.Instruction{sysprog.AdvanceNonceAccount(sysprog.AdvanceNonceAccountParam{
+				Nonce: types.NewAccount().PublicKey,
+				Auth:  authority,
+			})},
+		})
+		return &message
+	}
+
+	// the other nonce authority can't be signed by the builders.
+	_, err := signMessage(advance(types.NewAccount().PublicKey), account)
+	require.NotNil(t, err)
+
+	signed, err := signMessage(advance(payer), account)
+	require.Nil(t, err)
+	require.NotEmpty(t, signed.Value)
+}
+
+func TestTransferWithOptions(t *testing.T) {
+	chain, account := newChainAndAccount()
+	token := chain.MainToken().(*Token)
+
+	suggestion, err := chain.SuggestPriorityFee(account.Address())
+	require.Nil(t, err)
+	t.Log(suggestion.JsonString())
+
+	options := &TransactionOptions{ComputeUnitLimit: 500, ComputeUnitPrice: suggestion.Medium + 1}
+	fee, err := token.EstimateFeesWithOptions("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g", "1000", options)
+	require.Nil(t, err)
+	t.Log(fee.Value)
+
+	message, err := token.transactionMessage(account.Address(), "9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g", "1000", options)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(message.Instructions))
+	require.Equal(t, common.ComputeBudgetProgramID, message.Accounts[message.Instructions[0].ProgramIDIndex])
+
+	blockhash, err := chain.LatestBlockhash()
+	require.Nil(t, err)
+	expired, err := chain.IsBlockhashExpired(blockhash.LastValidBlockHeight)
+	require.Nil(t, err)
+	require.False(t, expired)
+}
+
+func TestFetchNonce(t *testing.T) {
+	chain := NewChainWithRpc(rpc.DevnetRPCEndpoint)
+	_, err := chain.FetchNonce("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g")
+	// it's not a nonce account
+	require.NotNil(t, err)
+}
@@ -0,0 +1,504 @@
+package solana
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	hexTypes "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/decred/base58"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+const (
+	// The legacy message has no version prefix.
+	MessageVersionLegacy = -1
+	MessageVersionV0     = 0
+
+	// The first bit of versioned message is set, the rest bits are the version.
+	messageVersionPrefixMask = 0x80
+	// The size of the metadata of address lookup table account, the addresses follow it.
+	lookupTableMetaSize = 56
+)
+
+var ErrInvalidTransactionData = errors.New("invalid transaction data")
+
+type AddressTableLookup struct {
+	AccountKey      common.PublicKey
+	WritableIndexes []uint8
+	ReadonlyIndexes []uint8
+}
+
+// The message of legacy or versioned transaction.
+type VersionedMessage struct {
+	Version             int
+	Header              types.MessageHeader
+	AccountKeys         []common.PublicKey
+	RecentBlockhash     string
+	Instructions        []types.CompiledInstruction
+	AddressTableLookups []AddressTableLookup
+
+	// The serialized message, it's the data to be signed.
+	data []byte
+}
+
+// The legacy or versioned (v0) transaction, it's usually created by dApps.
+type VersionedTransaction struct {
+	Signatures [][]byte
+	Message    *VersionedMessage
+}
+
+// Deserialize the legacy or v0 transaction.
+// @param txString the serialized transaction in base64, or hex with 0x prefix.
+func NewVersionedTransactionWithString(txString string) (*VersionedTransaction, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(txString, "0x") {
+		data, err = hexTypes.HexDecodeString(txString)
+	} else {
+		data, err = base64.StdEncoding.DecodeString(txString)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return VersionedTransactionDeserialize(data)
+}
+
+func VersionedTransactionDeserialize(data []byte) (*VersionedTransaction, error) {
+	reader := &shortVecReader{data: data}
+	count, err := reader.readLength()
+	if err != nil {
+		return nil, err
+	}
+	signatures := make([][]byte, count)
+	for i := range signatures {
+		if signatures[i], err = reader.readBytes(ed25519.SignatureSize); err != nil {
+			return nil, err
+		}
+	}
+	message, err := VersionedMessageDeserialize(reader.data[reader.offset:])
+	if err != nil {
+		return nil, err
+	}
+	if int(message.Header.NumRequireSignatures) != len(signatures) {
+		return nil, errors.New("the count of signatures does not match the message header")
+	}
+	return &VersionedTransaction{Signatures: signatures, Message: message}, nil
+}
+
+func VersionedMessageDeserialize(data []byte) (*VersionedMessage, error) {
+	reader := &shortVecReader{data: data}
+	message := &VersionedMessage{Version: MessageVersionLegacy, data: data}
+
+	prefix, err := reader.readByte()
+	if err != nil {
+		return nil, err
+	}
+	if prefix&messageVersionPrefixMask != 0 {
+		message.Version = int(prefix &^ messageVersionPrefixMask)
+		if message.Version != MessageVersionV0 {
+			return nil, fmt.Errorf("unsupported message version %v", message.Version)
+		}
+		if prefix, err = reader.readByte(); err != nil {
+			return nil, err
+		}
+	}
+	message.Header.NumRequireSignatures = prefix
+	if message.Header.NumReadonlySignedAccounts, err = reader.readByte(); err != nil {
+		return nil, err
+	}
+	if message.Header.NumReadonlyUnsignedAccounts, err = reader.readByte(); err != nil {
+		return nil, err
+	}
+
+	if message.AccountKeys, err = reader.readPublicKeys(); err != nil {
+		return nil, err
+	}
+	blockhash, err := reader.readBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	message.RecentBlockhash = base58.Encode(blockhash)
+
+	count, err := reader.readLength()
+	if err != nil {
+		return nil, err
+	}
+	message.Instructions = make([]types.CompiledInstruction, count)
+	for i := range message.Instructions {
+		programIndex, err := reader.readByte()
+		if err != nil {
+			return nil, err
+		}
+		accounts, err := reader.readVec()
+		if err != nil {
+			return nil, err
+		}
+		data, err := reader.readVec()
+		if err != nil {
+			return nil, err
+		}
+		indexes := make([]int, len(accounts))
+		for j, index := range accounts {
+			indexes[j] = int(index)
+		}
+		message.Instructions[i] = types.CompiledInstruction{ProgramIDIndex: int(programIndex), Accounts: indexes, Data: data}
+	}
+
+	if message.Version == MessageVersionV0 {
+		count, err := reader.readLength()
+		if err != nil {
+			return nil, err
+		}
+		message.AddressTableLookups = make([]AddressTableLookup, count)
+		for i := range message.AddressTableLookups {
+			key, err := reader.readBytes(32)
+			if err != nil {
+				return nil, err
+			}
+			writable, err := reader.readVec()
+			if err != nil {
+				return nil, err
+			}
+			readonly, err := reader.readVec()
+			if err != nil {
+				return nil, err
+			}
+			message.AddressTableLookups[i] = AddressTableLookup{
+				AccountKey:      common.PublicKeyFromBytes(key),
+				WritableIndexes: writable,
+				ReadonlyIndexes: readonly,
+			}
+		}
+	}
+	if reader.offset != len(data) {
+		return nil, ErrInvalidTransactionData
+	}
+	if err := message.sanitize(); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// The message comes from dApps, so the header and indexes are checked as the runtime does, to avoid indexing out of range.
+func (m *VersionedMessage) sanitize() error {
+	keyCount := len(m.AccountKeys)
+	signerCount := int(m.Header.NumRequireSignatures)
+	if signerCount == 0 || signerCount > keyCount {
+		return errors.New("invalid message header: the count of required signatures is out of range")
+	}
+	// the fee payer must be writable
+	if int(m.Header.NumReadonlySignedAccounts) >= signerCount {
+		return errors.New("invalid message header: too many readonly signed accounts")
+	}
+	if int(m.Header.NumReadonlyUnsignedAccounts) > keyCount-signerCount {
+		return errors.New("invalid message header: too many readonly unsigned accounts")
+	}
+
+	// the accounts loaded from the address lookup tables follow the static account keys.
+	totalCount := keyCount
+	for _, lookup := range m.AddressTableLookups {
+		totalCount += len(lookup.WritableIndexes) + len(lookup.ReadonlyIndexes)
+	}
+	for _, instruction := range m.Instructions {
+		// the program can't be loaded from the lookup tables
+		if instruction.ProgramIDIndex >= keyCount {
+			return errors.New("invalid instruction: the program index is out of range")
+		}
+		for _, index := range instruction.Accounts {
+			if index >= totalCount {
+				return errors.New("invalid instruction: the account index is out of range")
+			}
+		}
+	}
+	return nil
+}
+
+// MARK - Transaction
+
+func (t *VersionedTransaction) Version() int {
+	return t.Message.Version
+}
+
+// The addresses of the required signers, separated by ",".
+func (t *VersionedTransaction) RequiredSigners() string {
+	signers := make([]string, t.Message.Header.NumRequireSignatures)
+	for i := range signers {
+		signers[i] = t.Message.AccountKeys[i].ToBase58()
+	}
+	return strings.Join(signers, ",")
+}
+
+// Sign the transaction with the account, the signatures of other signers are kept, so it can be partial signed by several signers.
+func (t *VersionedTransaction) SignWithAccount(account *Account) error {
+	for i := 0; i < int(t.Message.Header.NumRequireSignatures); i++ {
+		if t.Message.AccountKeys[i] == account.account.PublicKey {
+			t.Signatures[i] = account.account.Sign(t.Message.data)
+			return nil
+		}
+	}
+	return errors.New("the account is not a signer of the transaction")
+}
+
+// Check whether all required signers have signed the transaction with valid signatures.
+func (t *VersionedTransaction) IsFullySigned() bool {
+	for i, signature := range t.Signatures {
+		if !ed25519.Verify(t.Message.AccountKeys[i].Bytes(), t.Message.data, signature) {
+			return false
+		}
+	}
+	return true
+}
+
+// The hash of the transaction is the first signature.
+func (t *VersionedTransaction) TxHash() string {
+	if len(t.Signatures) == 0 {
+		return ""
+	}
+	return base58.Encode(t.Signatures[0])
+}
+
+func (t *VersionedTransaction) Serialize() []byte {
+	data := appendShortVecLength(nil, len(t.Signatures))
+	for _, signature := range t.Signatures {
+		data = append(data, signature...)
+	}
+	return append(data, t.Message.data...)
+}
+
+func (t *VersionedTransaction) SerializeBase64() string {
+	return base64.StdEncoding.EncodeToString(t.Serialize())
+}
+
+// The hex string with 0x prefix, it can be sent by `Chain.SendRawTransaction`.
+func (t *VersionedTransaction) SerializeHex() string {
+	return hexTypes.HexEncodeToString(t.Serialize())
+}
+
+// MARK - Resolve lookup tables
+
+type ResolvedAccount struct {
+	Address    string `json:"address"`
+	IsSigner   bool   `json:"isSigner"`
+	IsWritable bool   `json:"isWritable"`
+	// The address lookup table which the account is loaded from, empty means static account.
+	LookupTable string `json:"lookupTable,omitempty"`
+}
+
+type ResolvedInstruction struct {
+	ProgramId string   `json:"programId"`
+	Accounts  []string `json:"accounts"`
+	Data      string   `json:"data"` // hex string
+}
+
+// The transaction with all accounts resolved, it's used for display.
+type ResolvedTransaction struct {
+	Version         int                    `json:"version"`
+	FeePayer        string                 `json:"feePayer"`
+	RecentBlockhash string                 `json:"recentBlockhash"`
+	Accounts        []*ResolvedAccount     `json:"accounts"`
+	Instructions    []*ResolvedInstruction `json:"instructions"`
+}
+
+func (t *ResolvedTransaction) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(t)
+}
+
+// Load the addresses of lookup tables and resolve the accounts of instructions.
+func (c *Chain) ResolveVersionedTransaction(tx *VersionedTransaction) (*ResolvedTransaction, error) {
+	message := tx.Message
+	tables := make([]string, len(message.AddressTableLookups))
+	for i, lookup := range message.AddressTableLookups {
+		tables[i] = lookup.AccountKey.ToBase58()
+	}
+	tableAddresses := make([][]common.PublicKey, len(tables))
+	if len(tables) > 0 {
+		accounts, err := c.client().GetMultipleAccounts(context.Background(), tables)
+		if err != nil {
+			return nil, err
+		}
+		for i, account := range accounts {
+			if tableAddresses[i], err = lookupTableAddresses(account.Data); err != nil {
+				return nil, fmt.Errorf("invalid lookup table %v: %v", tables[i], err)
+			}
+		}
+	}
+	return resolveMessage(message, tableAddresses)
+}
+
+// The accounts of v0 message are the static accounts, followed by the writable and then the readonly accounts of lookup tables.
+func resolveMessage(message *VersionedMessage, tableAddresses [][]common.PublicKey) (*ResolvedTransaction, error) {
+	header := message.Header
+	staticCount := len(message.AccountKeys)
+	accounts := make([]*ResolvedAccount, 0, staticCount)
+	for i, key := range message.AccountKeys {
+		isSigner := i < int(header.NumRequireSignatures)
+		isWritable := false
+		if isSigner {
+			isWritable = i < int(header.NumRequireSignatures-header.NumReadonlySignedAccounts)
+		} else {
+			isWritable = i < staticCount-int(header.NumReadonlyUnsignedAccounts)
+		}
+		accounts = append(accounts, &ResolvedAccount{Address: key.ToBase58(), IsSigner: isSigner, IsWritable: isWritable})
+	}
+	for _, writable := range []bool{true, false} {
+		for i, lookup := range message.AddressTableLookups {
+			indexes := lookup.ReadonlyIndexes
+			if writable {
+				indexes = lookup.WritableIndexes
+			}
+			for _, index := range indexes {
+				if int(index) >= len(tableAddresses[i]) {
+					return nil, fmt.Errorf("index %v out of lookup table %v", index, lookup.AccountKey.ToBase58())
+				}
+				accounts = append(accounts, &ResolvedAccount{
+					Address:     tableAddresses[i][index].ToBase58(),
+					IsWritable:  writable,
+					LookupTable: lookup.AccountKey.ToBase58(),
+				})
+			}
+		}
+	}
+
+	instructions := make([]*ResolvedInstruction, len(message.Instructions))
+	for i, instruction := range message.Instructions {
+		if instruction.ProgramIDIndex >= len(accounts) {
+			return nil, ErrInvalidTransactionData
+		}
+		addresses := make([]string, len(instruction.Accounts))
+		for j, index := range instruction.Accounts {
+			if index >= len(accounts) {
+				return nil, ErrInvalidTransactionData
+			}
+			addresses[j] = accounts[index].Address
+		}
+		instructions[i] = &ResolvedInstruction{
+			ProgramId: accounts[instruction.ProgramIDIndex].Address,
+			Accounts:  addresses,
+			Data:      hexTypes.HexEncodeToString(instruction.Data),
+		}
+	}
+
+	resolved := &ResolvedTransaction{
+		Version:         message.Version,
+		RecentBlockhash: message.RecentBlockhash,
+		Accounts:        accounts,
+		Instructions:    instructions,
+	}
+	if staticCount > 0 {
+		resolved.FeePayer = accounts[0].Address
+	}
+	return resolved, nil
+}
+
+func lookupTableAddresses(data []byte) ([]common.PublicKey, error) {
+	if len(data) < lookupTableMetaSize || (len(data)-lookupTableMetaSize)%32 != 0 {
+		return nil, errors.New("invalid lookup table data")
+	}
+	addresses := make([]common.PublicKey, 0, (len(data)-lookupTableMetaSize)/32)
+	for i := lookupTableMetaSize; i < len(data); i += 32 {
+		addresses = append(addresses, common.PublicKeyFromBytes(data[i:i+32]))
+	}
+	return addresses, nil
+}
+
+// Send the legacy or versioned transaction.
+// @return the hash (first signature) of transaction
+func (c *Chain) SendVersionedTransaction(tx *VersionedTransaction) (string, error) {
+	var hash string
+	err := c.callRpc(&hash, "sendTransaction", tx.SerializeBase64(), map[string]string{"encoding": "base64"})
+	return hash, err
+}
+
+// MARK - Sign message
+
+// The signed off-chain message, the format is same as `signMessage` of the solana wallet adapter.
+type SignedMessage struct {
+	// The base58 encoded signature
+	Signature string `json:"signature"`
+	// The address of the signer
+	PublicKey string `json:"publicKey"`
+}
+
+func (m *SignedMessage) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(m)
+}
+
+// Sign the off-chain message for dApps, the message that can be deserialized as a transaction message is rejected to avoid signing transactions blindly.
+func (a *Account) SignMessage(message []byte) (*SignedMessage, error) {
+	if _, err := VersionedMessageDeserialize(message); err == nil {
+		return nil, errors.New("the message is a transaction, it cannot be signed as message")
+	}
+	return &SignedMessage{
+		Signature: base58.Encode(a.account.Sign(message)),
+		PublicKey: a.Address(),
+	}, nil
+}
+
+// MARK - compact-u16 (short vec) encoding
+
+type shortVecReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *shortVecReader) readByte() (byte, error) {
+	if r.offset >= len(r.data) {
+		return 0, ErrInvalidTransactionData
+	}
+	b := r.data[r.offset]
+	r.offset++
+	return b, nil
+}
+
+func (r *shortVecReader) readBytes(length int) ([]byte, error) {
+	if length < 0 || r.offset+length > len(r.data) {
+		return nil, ErrInvalidTransactionData
+	}
+	b := r.data[r.offset : r.offset+length]
+	r.offset += length
+	return b, nil
+}
+
+func (r *shortVecReader) readLength() (int, error) {
+	value, n := binary.Uvarint(r.data[r.offset:])
+	if n <= 0 || n > 3 || value > 0xffff {
+		return 0, ErrInvalidTransactionData
+	}
+	r.offset += n
+	return int(value), nil
+}
+
+func (r *shortVecReader) readVec() ([]byte, error) {
+	length, err := r.readLength()
+	if err != nil {
+		return nil, err
+	}
+	return r.readBytes(length)
+}
+
+func (r *shortVecReader) readPublicKeys() ([]common.PublicKey, error) {
+	count, err := r.readLength()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]common.PublicKey, count)
+	for i := range keys {
+		data, err := r.readBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = common.PublicKeyFromBytes(data)
+	}
+	return keys, nil
+}
+
+func appendShortVecLength(data []byte, length int) []byte {
+	return binary.AppendUvarint(data, uint64(length))
+}
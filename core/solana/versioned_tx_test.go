@@ -0,0 +1,131 @@
+package solana
+
+import (
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionedTransaction_Legacy(t *testing.T) {
+	account := newRandomAccount()
+	message := types.NewMessage(types.NewMessageParam{
+		FeePayer:        account.account.PublicKey,
+		RecentBlockhash: "9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g",
+		Instructions: []types.Instruction{
+			sysprog.Transfer(sysprog.TransferParam{
+				From:   account.account.PublicKey,
+				To:     common.PublicKeyFromString("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g"),
+				Amount: 1000,
+			}),
+		},
+	})
+	signed, err := signMessage(&message, account)
+	require.Nil(t, err)
+
+	tx, err := NewVersionedTransactionWithString(signed.Value)
+	require.Nil(t, err)
+	require.Equal(t, MessageVersionLegacy, tx.Version())
+	require.Equal(t, account.Address(), tx.RequiredSigners())
+	require.True(t, tx.IsFullySigned())
+	require.Equal(t, signed.Value, tx.SerializeHex())
+
+	// base64 round trip
+	tx2, err := NewVersionedTransactionWithString(tx.SerializeBase64())
+	require.Nil(t, err)
+	require.Equal(t, tx.Serialize(), tx2.Serialize())
+}
+
+func TestVersionedTransaction_V0(t *testing.T) {
+	account := newRandomAccount()
+	program := common.SystemProgramID
+	table := common.PublicKeyFromString("9B5XszUGdMaxCZ7uSQhPzdks5ZQSmWxrmzCSvtJ6Ns6g")
+	loaded := common.PublicKeyFromString("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+
+	data := []byte{0x80, 1, 0, 1, 2}
+	data = append(data, account.account.PublicKey.Bytes()...)
+	data = append(data, program.Bytes()...)
+	data = append(data, make([]byte, 32)...) // blockhash
+	data = append(data, 1, 1, 2, 0, 2, 2, 0xaa, 0xbb)
+	data = append(data, 1)
+	data = append(data, table.Bytes()...)
+	data = append(data, 1, 3, 0)
+
+	message, err := VersionedMessageDeserialize(data)
+	require.Nil(t, err)
+	require.Equal(t, MessageVersionV0, message.Version)
+	require.Equal(t, 1, len(message.AddressTableLookups))
+
+	tx := &VersionedTransaction{Signatures: [][]byte{make([]byte, 64)}, Message: message}
+	require.False(t, tx.IsFullySigned())
+	require.Nil(t, tx.SignWithAccount(account))
+	require.True(t, tx.IsFullySigned())
+
+	other := newRandomAccount()
+	require.NotNil(t, tx.SignWithAccount(other))
+
+	tx2, err := VersionedTransactionDeserialize(tx.Serialize())
+	require.Nil(t, err)
+	require.Equal(t, tx.Signatures, tx2.Signatures)
+
+	tableAddresses := [][]common.PublicKey{{{}, {}, {}, loaded}}
+	resolved, err := resolveMessage(message, tableAddresses)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(resolved.Accounts))
+	require.Equal(t, &ResolvedAccount{Address: loaded.ToBase58(), IsWritable: true, LookupTable: table.ToBase58()}, resolved.Accounts[2])
+	require.Equal(t, program.ToBase58(), resolved.Instructions[0].ProgramId)
+	require.Equal(t, []string{account.Address(), loaded.ToBase58()}, resolved.Instructions[0].Accounts)
+	require.Equal(t, "0xaabb", resolved.Instructions[0].Data)
+
+	_, err = account.SignMessage(data)
+	require.NotNil(t, err)
+	signed, err := account.SignMessage([]byte("Hello, Solana"))
+	require.Nil(t, err)
+	require.Equal(t, account.Address(), signed.PublicKey)
+}
+
+func TestVersionedMessageDeserialize_Invalid(t *testing.T) {
+	key := newRandomAccount().account.PublicKey
+	// legacy message with the keys [key, system program] and one instruction.
+	message := func(header []byte, programIndex byte, accounts ...byte) []byte {
+		data := append([]byte{}, header...)
+		data = append(data, 2)
+		data = append(data, key.Bytes()...)
+		data = append(data, common.SystemProgramID.Bytes()...)
+		data = append(data, make([]byte, 32)...) // blockhash
+		data = append(data, 1, programIndex, byte(len(accounts)))
+		data = append(data, accounts...)
+		return append(data, 0)
+	}
+
+	_, err := VersionedMessageDeserialize(message([]byte{1, 0, 1}, 1, 0))
+	require.Nil(t, err)
+
+	invalids := [][]byte{
+		message([]byte{3, 0, 0}, 1, 0), // more signers than keys
+		message([]byte{0, 0, 1}, 1, 0), // no signer
+		message([]byte{1, 1, 1}, 1, 0), // readonly fee payer
+		message([]byte{1, 0, 2}, 1, 0), // too many readonly unsigned accounts
+		message([]byte{1, 0, 1}, 2, 0), // program index out of range
+		message([]byte{1, 0, 1}, 1, 2), // account index out of range
+	}
+	for _, data := range invalids {
+		_, err := VersionedMessageDeserialize(data)
+		require.NotNil(t, err)
+	}
+
+	// the header says 2 signers but there is only 1 key.
+	data := []byte{2, 0, 0, 1}
+	data = append(data, key.Bytes()...)
+	data = append(data, make([]byte, 32)...)
+	data = append(data, 0)
+	_, err = VersionedMessageDeserialize(data)
+	require.NotNil(t, err)
+}
+
+func newRandomAccount() *Account {
+	account := types.NewAccount()
+	return &Account{&account}
+}